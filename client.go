@@ -0,0 +1,113 @@
+package scrapen
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/akeil/scrapen/internal/cookies"
+	"github.com/akeil/scrapen/internal/har"
+)
+
+// client holds the HTTP client that is used for all requests of a task,
+// together with the components that need to be saved when the task is done.
+type client struct {
+	client     *http.Client
+	jar        *cookies.Jar
+	recorder   *har.Recorder
+	recordPath string
+}
+
+// newClient creates the HTTP client for a task.
+//
+// Requests pass through these layers:
+// per-host injection -> HAR recording -> network or HAR replay
+func newClient(o *Options) (*client, error) {
+	c := &client{}
+
+	var jar http.CookieJar
+	var err error
+
+	if o.CookieFile != "" {
+		c.jar, err = cookies.Open(o.CookieFile)
+		if err != nil {
+			return nil, err
+		}
+		jar = c.jar
+	} else {
+		jar, err = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var transport http.RoundTripper
+	if o.ReplayHAR != "" {
+		transport, err = har.LoadReplayer(o.ReplayHAR)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o.RecordHAR != "" {
+		c.recorder = har.NewRecorder(transport)
+		c.recordPath = o.RecordHAR
+		transport = c.recorder
+	}
+
+	if len(o.Hosts) > 0 {
+		hosts := make(map[string]cookies.Host)
+		for k, v := range o.Hosts {
+			hosts[k] = cookies.Host{
+				Cookies: v.Cookies,
+				Headers: v.Headers,
+			}
+		}
+		transport = cookies.Inject(transport, hosts)
+	}
+
+	c.client = &http.Client{
+		Jar:       jar,
+		Transport: transport,
+	}
+	return c, nil
+}
+
+// close saves the cookie jar and appends the recorded HAR, if configured.
+func (c *client) close() error {
+	if c.jar != nil {
+		err := c.jar.Save()
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.recorder != nil {
+		err := c.recorder.Append(c.recordPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportCookies adds the cookies from the given file to the cookie jar
+// stored in cookieFile.
+//
+// The file can be in Netscape `cookies.txt` format or a JSON export from
+// a browser extension.
+func ImportCookies(cookieFile, path string) error {
+	jar, err := cookies.Open(cookieFile)
+	if err != nil {
+		return err
+	}
+
+	err = jar.Import(path)
+	if err != nil {
+		return err
+	}
+
+	return jar.Save()
+}
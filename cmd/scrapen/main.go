@@ -1,28 +1,52 @@
 package main
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/akeil/scrapen/internal/cite"
 	"github.com/akeil/scrapen/internal/htm"
 	"github.com/akeil/scrapen/internal/pipeline"
 
 	"github.com/akeil/scrapen"
 )
 
-func main() {
+var (
+	recordHAR  = flag.String("record", "", "record all HTTP traffic to the given HAR file")
+	replayHAR  = flag.String("replay", "", "serve HTTP responses from the given HAR file")
+	cookieFile = flag.String("cookies", "", "persistent cookie jar")
+	format     = flag.String("format", "html", "output format: html, bib, ris, csl, atom, rss or json")
+	articles   = flag.Bool("articles", false, "fail if the page is not an article")
+	stateFile  = flag.String("state", "", "remember scraped feed entries in the given file")
+	opmlFile   = flag.String("opml", "", "scrape the feeds from the given OPML file")
+)
 
-	url := os.Args[1]
+func main() {
+	flag.Parse()
 
-	output := "./output"
-	if len(os.Args) >= 3 {
-		output = os.Args[2]
+	var err error
+	switch flag.Arg(0) {
+	case "feed":
+		err = runFeed(flag.Args()[1:])
+	case "opml":
+		err = runOPML(flag.Args()[1:])
+	default:
+		output := "./output"
+		if flag.NArg() >= 2 {
+			output = flag.Arg(1)
+		}
+		err = run(flag.Arg(0), output)
 	}
 
-	err := run(url, output)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -30,48 +54,309 @@ func main() {
 
 type composeFunc func(w io.Writer, t *pipeline.Task) error
 
+// output formats, the key is used as the file extension
+var formats = map[string]composeFunc{
+	"html": htm.Compose,
+	"bib":  cite.BibTeX,
+	"ris":  cite.RIS,
+	"csl":  cite.CSL,
+	"atom": composeFeed("atom"),
+	"rss":  composeFeed("rss"),
+	"json": composeFeed("json"),
+}
+
+// feed formats write all entries of the feed command to a single file
+var feedFormats = map[string]bool{
+	"atom": true,
+	"rss":  true,
+	"json": true,
+}
+
+// composeFeed writes a single task as a feed.
+func composeFeed(format string) composeFunc {
+	return func(w io.Writer, t *pipeline.Task) error {
+		return htm.ComposeFeed(w, []*pipeline.Task{t}, htm.FeedOptions{
+			Format: format,
+			Title:  t.Title,
+			Link:   t.ContentURL(),
+		})
+	}
+}
+
 func run(url, output string) error {
+	compose, ok := formats[*format]
+	if !ok {
+		return fmt.Errorf("unsupported output format %q", *format)
+	}
+
 	log.SetLevel(log.DebugLevel)
 	//log.SetLevel(log.InfoLevel)
 	s := pipeline.NewMemoryStore()
-	o := &scrapen.Options{
+	a, err := scrapen.Scrape(url, options(s))
+	if err != nil {
+		return err
+	}
+
+	outfile := fmt.Sprintf("%v.%v", output, *format)
+	return write(outfile, compose, taskFromArticle(a, s))
+}
+
+// runFeed scrapes each new entry of a feed into a file in the output
+// directory.
+// The arguments are the feed URL and the output directory,
+// or only the output directory if the feeds are read from an OPML file.
+// Feed formats write all entries to a single feed in the output directory.
+func runFeed(args []string) error {
+	compose, ok := formats[*format]
+	if !ok {
+		return fmt.Errorf("unsupported output format %q", *format)
+	}
+
+	var urls []string
+	if *opmlFile != "" {
+		subs, err := readOPML(*opmlFile)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			urls = append(urls, sub.XMLURL)
+		}
+	} else if len(args) > 0 {
+		urls = append(urls, args[0])
+		args = args[1:]
+	}
+	if len(urls) == 0 && *opmlFile != "" {
+		return fmt.Errorf("no feeds in %q", *opmlFile)
+	} else if len(urls) == 0 {
+		return fmt.Errorf("usage: scrapen feed URL [OUTDIR] or scrapen -opml FILE feed [OUTDIR]")
+	}
+
+	output := "./output"
+	if len(args) > 0 {
+		output = args[0]
+	}
+
+	log.SetLevel(log.DebugLevel)
+	err := os.MkdirAll(output, 0755)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	tasks := make([]*pipeline.Task, 0)
+	// feeds for feed formats are committed after the feed is written
+	pending := make([]*scrapen.FeedResult, 0)
+	title := "Feeds"
+	link := ""
+	for _, url := range urls {
+		s := pipeline.NewMemoryStore()
+		o := options(s)
+		o.FeedStateFile = *stateFile
+		fr, err := scrapen.ScrapeFeed(url, o)
+		if err != nil && len(urls) == 1 {
+			return err
+		} else if err != nil {
+			// continue with the other feeds
+			log.WithFields(log.Fields{
+				"url":   url,
+				"error": err,
+			}).Warn("Failed to scrape feed")
+			continue
+		}
+
+		if len(urls) == 1 {
+			if fr.Feed.Title != "" {
+				title = fr.Feed.Title
+			}
+			link = fr.Feed.Link
+			if link == "" {
+				link = url
+			}
+		}
+
+		for _, item := range fr.Items {
+			n++
+			if item.Err != nil {
+				log.WithFields(log.Fields{
+					"url":   item.Entry.Link,
+					"error": item.Err,
+				}).Warn("Failed to scrape feed entry")
+			}
+
+			if feedFormats[*format] {
+				tasks = append(tasks, taskFromArticle(item.Result, s))
+				continue
+			}
+
+			name := entryName(item.Entry, item.Result.Title, n)
+			outfile := filepath.Join(output, fmt.Sprintf("%v.%v", name, *format))
+			err = write(outfile, compose, taskFromArticle(item.Result, s))
+			if err != nil {
+				return err
+			}
+		}
+
+		if feedFormats[*format] {
+			pending = append(pending, fr)
+			continue
+		}
+		err = fr.Commit()
+		if err != nil {
+			return err
+		}
+	}
+
+	if feedFormats[*format] {
+		outfile := filepath.Join(output, fmt.Sprintf("feed.%v", *format))
+		log.Info(fmt.Sprintf("Output to %q\n", outfile))
+		f, err := os.Create(outfile)
+		if err != nil {
+			return err
+		}
+		err = htm.ComposeFeed(f, tasks, htm.FeedOptions{Format: *format, Title: title, Link: link})
+		if err != nil {
+			f.Close()
+			return err
+		}
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, fr := range pending {
+		err = fr.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readOPML(path string) ([]scrapen.Subscription, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return scrapen.ReadOPML(f)
+}
+
+// runOPML scrapes the given URLs and writes the feeds that were found to
+// an OPML file. The first argument is the output file.
+func runOPML(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: scrapen opml OUTFILE URL...")
+	}
+	outfile, urls := args[0], args[1:]
+
+	log.SetLevel(log.DebugLevel)
+	results := make([]scrapen.Result, 0)
+	for _, url := range urls {
+		o := options(pipeline.NewMemoryStore())
+		o.DownloadImages = false
+		// feeds are usually found on home pages, which are not articles
+		o.ArticlesOnly = false
+		r, err := scrapen.Scrape(url, o)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"url":   url,
+				"error": err,
+			}).Warn("Failed to scrape")
+			continue
+		}
+		results = append(results, r)
+	}
+
+	log.Info(fmt.Sprintf("Output to %q\n", outfile))
+	f, err := os.Create(outfile)
+	if err != nil {
+		return err
+	}
+
+	err = scrapen.WriteOPML(f, "Feeds", results)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func options(s scrapen.Store) *scrapen.Options {
+	return &scrapen.Options{
 		Metadata:       true,
 		Readability:    true,
 		Clean:          true,
 		Normalize:      true,
 		DownloadImages: true,
 		FindFeeds:      true,
+		ProbeFeeds:     true,
+		ReadFeeds:      true,
+		Embeds:         true,
 		SiteSpecific:   true,
+		Pages:          true,
+		MaxPages:       10,
+		ArticlesOnly:   *articles,
+		RetryBlocked:   true,
 		Store:          s,
+		CookieFile:     *cookieFile,
+		RecordHAR:      *recordHAR,
+		ReplayHAR:      *replayHAR,
 	}
-	a, err := scrapen.Scrape(url, o)
-	if err != nil {
-		return err
-	}
+}
 
-	format := "html"
-	outfile := fmt.Sprintf("%v.%v", output, format)
+func write(outfile string, compose composeFunc, t *pipeline.Task) error {
 	log.Info(fmt.Sprintf("Output to %q\n", outfile))
 
 	f, err := os.Create(outfile)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	err = htm.Compose(f, taskFromArticle(a, s))
+
+	err = compose(f, t)
 	if err != nil {
+		f.Close()
 		return err
 	}
+	return f.Close()
+}
 
-	return nil
+var nonWord = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// slug makes a file name from a title.
+func slug(title string) string {
+	s := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	r := []rune(s)
+	if len(r) > 80 {
+		s = strings.TrimRight(string(r[:80]), "-")
+	}
+	return s
+}
+
+// entryName makes a file name for a feed entry.
+// A hash of the entry ID keeps entries with the same title apart.
+func entryName(e scrapen.FeedEntry, title string, n int) string {
+	id := pipeline.FirstOf(e.ID, e.Link)
+	if id == "" {
+		return pipeline.FirstOf(slug(title), "entry") + fmt.Sprintf("-%d", n)
+	}
+
+	sum := sha1.Sum([]byte(id))
+	hash := hex.EncodeToString(sum[:])[:8]
+	name := pipeline.FirstOf(slug(title), slug(id))
+	return name + "-" + hash
 }
 
 func taskFromArticle(a scrapen.Result, s scrapen.Store) *pipeline.Task {
 	fs := make([]pipeline.FeedInfo, len(a.Feeds))
 	for i, f := range a.Feeds {
 		fs[i] = pipeline.FeedInfo{
-			URL:   f.URL,
-			Title: f.Title,
+			URL:     f.URL,
+			Title:   f.Title,
+			Source:  f.Source,
+			Format:  f.Format,
+			Entries: f.Entries,
 		}
 	}
 
@@ -93,27 +378,104 @@ func taskFromArticle(a scrapen.Result, s scrapen.Store) *pipeline.Task {
 			URL:         e.URL,
 			ContentType: e.ContentType,
 			Description: e.Description,
+			Duration:    e.Duration,
+			Size:        e.Size,
+			Image:       e.Image,
+			Key:         e.Key,
+		}
+	}
+
+	as := make([]pipeline.Author, len(a.Authors))
+	for i, au := range a.Authors {
+		as[i] = pipeline.Author{
+			Name:  au.Name,
+			URL:   au.URL,
+			Image: au.Image,
+			Role:  au.Role,
+		}
+	}
+
+	var c *pipeline.Citation
+	if a.Citation != nil {
+		c = &pipeline.Citation{
+			Type:        a.Citation.Type,
+			DOI:         a.Citation.DOI,
+			Journal:     a.Citation.Journal,
+			Volume:      a.Citation.Volume,
+			Issue:       a.Citation.Issue,
+			FirstPage:   a.Citation.FirstPage,
+			LastPage:    a.Citation.LastPage,
+			Publisher:   a.Citation.Publisher,
+			Institution: a.Citation.Institution,
+			ISSN:        a.Citation.ISSN,
+			ISBN:        a.Citation.ISBN,
+			PDFURL:      a.Citation.PDFURL,
+		}
+	}
+
+	var l *pipeline.License
+	if a.License != nil {
+		l = &pipeline.License{
+			ID:     a.License.ID,
+			Name:   a.License.Name,
+			URL:    a.License.URL,
+			Holder: a.License.Holder,
+			Year:   a.License.Year,
 		}
 	}
 
 	t := &pipeline.Task{
-		URL:          a.URL,
-		ActualURL:    a.ActualURL,
-		CanonicalURL: a.CanonicalURL,
-		StatusCode:   a.StatusCode,
-		Title:        a.Title,
-		Retrieved:    a.Retrieved,
-		Description:  a.Description,
-		PubDate:      a.PubDate,
-		Site:         a.Site,
-		SiteScheme:   a.SiteScheme,
-		Author:       a.Author,
-		ImageURL:     a.ImageURL,
-		WordCount:    a.WordCount,
-		Images:       imgs,
-		Feeds:        fs,
-		Enclosures:   encs,
-		Store:        s,
+		URL:              a.URL,
+		ActualURL:        a.ActualURL,
+		CanonicalURL:     a.CanonicalURL,
+		StatusCode:       a.StatusCode,
+		Title:            a.Title,
+		TitleSource:      a.TitleSource,
+		Retrieved:        a.Retrieved,
+		Description:      a.Description,
+		PubDate:          a.PubDate,
+		PubDatePrecision: a.PubDatePrecision,
+		ModifiedDate:     a.ModifiedDate,
+		Site:             a.Site,
+		SiteScheme:       a.SiteScheme,
+		SiteName:         a.SiteName,
+		SiteIcon:         a.SiteIcon,
+		SiteLogo:         a.SiteLogo,
+		ThemeColor:       a.ThemeColor,
+		Author:           a.Author,
+		Authors:          as,
+		Keywords:         a.Keywords,
+		Section:          a.Section,
+		Language:         a.Language,
+		Direction:        a.Direction,
+		InReplyTo:        a.InReplyTo,
+		LikeOf:           a.LikeOf,
+		Citation:         c,
+		License:          l,
+		PageType: pipeline.PageType{
+			Type:       a.PageType,
+			Confidence: a.PageTypeConfidence,
+		},
+		Paywall: pipeline.Paywall{
+			Paywalled:  a.Paywalled,
+			Confidence: a.PaywallConfidence,
+			Signals:    a.PaywallSignals,
+		},
+		ImageURL:  a.ImageURL,
+		WordCount: a.WordCount,
+		Stats: pipeline.Stats{
+			Characters:  a.Stats.Characters,
+			Sentences:   a.Stats.Sentences,
+			Paragraphs:  a.Stats.Paragraphs,
+			Headings:    a.Stats.Headings,
+			Images:      a.Stats.Images,
+			Links:       a.Stats.Links,
+			ReadingTime: a.Stats.ReadingTime,
+		},
+		Images:     imgs,
+		Feeds:      fs,
+		Enclosures: encs,
+		Store:      s,
 	}
 	t.SetHTML(a.HTML)
 	return t
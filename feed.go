@@ -0,0 +1,385 @@
+package scrapen
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/feed"
+	"github.com/akeil/scrapen/internal/htm"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// ParsedFeed is a feed read with ParseFeed.
+type ParsedFeed = feed.Feed
+
+// FeedEntry is an item from a ParsedFeed.
+type FeedEntry = feed.Entry
+
+// FeedPerson is the author of a feed or entry.
+type FeedPerson = feed.Person
+
+// FeedEnclosure is a media file attached to a FeedEntry.
+type FeedEnclosure = feed.Enclosure
+
+// ErrUnknownFormat is returned by ParseFeed if the document is not a feed.
+var ErrUnknownFormat = feed.ErrUnknownFormat
+
+// ParseFeed reads an RSS, RDF, Atom or JSON feed.
+//
+// Relative URLs are resolved against the given URL of the feed,
+// which may be empty.
+func ParseFeed(r io.Reader, feedURL string) (*ParsedFeed, error) {
+	return feed.Parse(r, feedURL)
+}
+
+// FeedResult holds the results of ScrapeFeed.
+type FeedResult struct {
+	Feed *ParsedFeed
+	// Items holds the entries that were scraped, in the order of the feed.
+	Items []FeedItem
+
+	feedURL   string
+	stateFile string
+	// done holds the IDs of entries which should not be scraped again
+	done []string
+}
+
+// FeedItem is the result for a single entry of a feed.
+type FeedItem struct {
+	Entry FeedEntry
+	// Result holds the scraped page. Title, dates, authors and content
+	// which are missing from the page are taken from the feed entry.
+	Result Result
+	// Err is set if the page could not be scraped.
+	// The Result then holds the data from the feed entry only.
+	Err error
+}
+
+// ScrapeFeed reads the feed with the given URL and scrapes the page for
+// each entry.
+//
+// If Options.FeedStateFile is set, entries that were scraped before are
+// skipped. The new entries are added to the file with FeedResult.Commit.
+// Entries that failed are tried again on the next call, unless the page
+// does not exist or is not an article.
+func ScrapeFeed(feedURL string, o *Options) (*FeedResult, error) {
+	if o == nil {
+		o = DefaultOptions()
+	}
+	ctx := context.Background()
+
+	state, err := feed.OpenState(o.FeedStateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := newClient(o)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := c.close()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"module": "main",
+				"error":  err,
+			}).Warn("Failed to save client state")
+		}
+	}()
+
+	f, err := feed.Fetch(ctx, c.client, feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	fr := &FeedResult{
+		Feed:      f,
+		feedURL:   feedURL,
+		stateFile: o.FeedStateFile,
+	}
+	for _, e := range f.Entries {
+		if state.Seen(feedURL, e.ID) {
+			continue
+		}
+
+		item := scrapeEntry(ctx, e, o, c)
+		fr.Items = append(fr.Items, item)
+
+		if item.Err == nil || isPermanent(item.Err) {
+			fr.done = append(fr.done, e.ID)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"module":  "main",
+		"url":     feedURL,
+		"entries": len(f.Entries),
+		"scraped": len(fr.Items),
+	}).Info("Feed complete")
+
+	return fr, nil
+}
+
+// Commit adds the entries from this result to Options.FeedStateFile,
+// so that they are skipped by the next ScrapeFeed.
+//
+// Call this after the items were processed, entries are then not lost if
+// that fails. Commit does nothing if there is no state file.
+func (fr *FeedResult) Commit() error {
+	if fr.stateFile == "" {
+		return nil
+	}
+
+	// read the file again, it may have changed for other feeds
+	state, err := feed.OpenState(fr.stateFile)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(fr.Feed.Entries))
+	for i, e := range fr.Feed.Entries {
+		ids[i] = e.ID
+	}
+	state.Retain(fr.feedURL, ids)
+
+	for _, id := range fr.done {
+		state.Add(fr.feedURL, id)
+	}
+	return state.Save()
+}
+
+func scrapeEntry(ctx context.Context, e FeedEntry, o *Options, c *client) FeedItem {
+	item := FeedItem{Entry: e}
+	if e.Link == "" {
+		item.Err = errors.New("feed entry has no link")
+	} else {
+		var t *pipeline.Task
+		t, item.Err = runTask(ctx, e.Link, o, c.client, entryEnclosures(e))
+		if item.Err == nil {
+			item.Result = resultFromTask(t)
+		}
+	}
+
+	if item.Err != nil {
+		item.Result = Result{URL: e.Link}
+	}
+	fillFromEntry(&item.Result, e)
+	return item
+}
+
+// isPermanent tells if a failed entry should not be tried again.
+func isPermanent(err error) bool {
+	var notArticle *NotArticleError
+	return errors.Is(err, ErrNotFound) || errors.As(err, &notArticle)
+}
+
+// fillFromEntry sets fields that are missing from the result
+// from the feed entry.
+func fillFromEntry(r *Result, e FeedEntry) {
+	if r.Title == "" {
+		r.Title = e.Title
+		r.TitleSource = "feed"
+	}
+
+	if r.PubDate == nil {
+		r.PubDate = e.Published
+		if r.PubDate == nil {
+			r.PubDate = e.Updated
+		}
+	}
+	if r.ModifiedDate == nil && e.Updated != nil && e.Published != nil {
+		r.ModifiedDate = e.Updated
+	}
+
+	if r.Author == "" && len(r.Authors) == 0 {
+		for _, p := range e.Authors {
+			if p.Name == "" {
+				continue
+			}
+			if r.Author == "" {
+				r.Author = p.Name
+			}
+			r.Authors = append(r.Authors, Author{Name: p.Name, URL: p.URL})
+		}
+	}
+
+	if len(r.Keywords) == 0 {
+		r.Keywords = e.Categories
+	}
+	if r.ImageURL == "" {
+		r.ImageURL = e.Image
+	}
+
+	// scraped entries already have these from entryEnclosures
+	for _, enc := range e.Enclosures {
+		addFeedEnclosure(r, enc, e)
+	}
+
+	// e.g. content:encoded from RSS
+	if strings.TrimSpace(r.HTML) == "" {
+		r.HTML = e.Content
+		if r.HTML == "" {
+			r.HTML = e.Summary
+		}
+	}
+}
+
+// entryEnclosures creates a step that adds the audio and video enclosures
+// of a feed entry to the task. The enclosures are then available to
+// DownloadEnclosures, page enclosures with the same URL are merged.
+func entryEnclosures(e FeedEntry) pipeline.Pipeline {
+	return func(ctx context.Context, t *pipeline.Task) error {
+		for _, enc := range e.Enclosures {
+			typ := pipeline.EnclosureType(enc.Type)
+			if typ == "" {
+				continue
+			}
+			t.AddEnclosure(pipeline.Enclosure{
+				Type:        typ,
+				Title:       pipeline.FirstOf(enc.Title, e.Title),
+				URL:         enc.URL,
+				ContentType: enc.Type,
+				Duration:    enc.Duration,
+				Size:        enc.Length,
+				Image:       pipeline.FirstOf(enc.Image, e.Image),
+			})
+		}
+		return nil
+	}
+}
+
+// addFeedEnclosure adds an audio or video enclosure from a feed entry to the
+// result, or completes the enclosure with the same URL.
+func addFeedEnclosure(r *Result, enc FeedEnclosure, e FeedEntry) {
+	typ := pipeline.EnclosureType(enc.Type)
+	if typ == "" {
+		return
+	}
+
+	var target *Enclosure
+	for i := range r.Enclosures {
+		if r.Enclosures[i].URL == enc.URL {
+			target = &r.Enclosures[i]
+			break
+		}
+	}
+	if target == nil {
+		r.Enclosures = append(r.Enclosures, Enclosure{Type: typ, URL: enc.URL})
+		target = &r.Enclosures[len(r.Enclosures)-1]
+	}
+
+	if target.Title == "" {
+		target.Title = pipeline.FirstOf(enc.Title, e.Title)
+	}
+	if target.ContentType == "" {
+		target.ContentType = enc.Type
+	}
+	if target.Duration == 0 {
+		target.Duration = enc.Duration
+	}
+	if target.Size == 0 {
+		target.Size = enc.Length
+	}
+	if target.Image == "" {
+		target.Image = pipeline.FirstOf(enc.Image, e.Image)
+	}
+}
+
+// Feed output formats
+const (
+	FeedAtom = "atom"
+	FeedRSS  = "rss"
+	FeedJSON = "json"
+)
+
+// FeedOptions holds settings for WriteFeed.
+type FeedOptions struct {
+	// Format is one of FeedAtom, FeedRSS or FeedJSON.
+	Format      string
+	Title       string
+	Description string
+	// Link is the URL of the web site that publishes the feed.
+	Link string
+	// FeedURL is the URL of the feed itself.
+	FeedURL string
+	// ImageBaseURL is the URL where the contents of the Store are
+	// published. Downloaded images and enclosures are referenced with the
+	// base URL and their key. If empty, images are inlined as data URLs.
+	ImageBaseURL string
+	// Store holds the downloaded images of the results.
+	Store Store
+}
+
+// WriteFeed writes the given results as an Atom, RSS or JSON feed with one
+// entry per result. Each entry has the full HTML content, author, dates,
+// image and enclosures.
+func WriteFeed(w io.Writer, results []Result, o FeedOptions) error {
+	ts := make([]*pipeline.Task, len(results))
+	for i, r := range results {
+		ts[i] = feedTask(r, o.Store)
+	}
+
+	return htm.ComposeFeed(w, ts, htm.FeedOptions{
+		Format:       o.Format,
+		Title:        o.Title,
+		Description:  o.Description,
+		Link:         o.Link,
+		FeedURL:      o.FeedURL,
+		ImageBaseURL: o.ImageBaseURL,
+	})
+}
+
+// feedTask creates a task with the fields of a result that are used for
+// feed entries.
+func feedTask(r Result, s Store) *pipeline.Task {
+	t := &pipeline.Task{
+		URL:          r.URL,
+		ActualURL:    r.ActualURL,
+		CanonicalURL: r.CanonicalURL,
+		Title:        r.Title,
+		Description:  r.Description,
+		PubDate:      r.PubDate,
+		ModifiedDate: r.ModifiedDate,
+		Author:       r.Author,
+		Keywords:     r.Keywords,
+		ImageURL:     r.ImageURL,
+		Store:        s,
+	}
+
+	for _, a := range r.Authors {
+		t.Authors = append(t.Authors, pipeline.Author{
+			Name:  a.Name,
+			URL:   a.URL,
+			Image: a.Image,
+			Role:  a.Role,
+		})
+	}
+	for _, img := range r.Images {
+		t.Images = append(t.Images, pipeline.ImageInfo{
+			Key:         img.Key,
+			ContentURL:  img.ContentURL,
+			ContentType: img.ContentType,
+			OriginalURL: img.OriginalURL,
+		})
+	}
+	for _, e := range r.Enclosures {
+		t.Enclosures = append(t.Enclosures, pipeline.Enclosure{
+			Type:        e.Type,
+			Title:       e.Title,
+			URL:         e.URL,
+			ContentType: e.ContentType,
+			Description: e.Description,
+			Duration:    e.Duration,
+			Size:        e.Size,
+			Image:       e.Image,
+			Key:         e.Key,
+		})
+	}
+
+	t.SetHTML(r.HTML)
+	return t
+}
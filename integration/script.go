@@ -95,17 +95,13 @@ func runCase(path string, info os.FileInfo, err error) error {
 
 	base := strings.TrimSuffix(path, ext)
 	html := base + ".html"
-
-	// check if the corresponding HTML file exists
-	_, err = os.Stat(html)
-	if err != nil {
-		return err
-	}
+	har := base + ".har"
 
 	f, err := os.Open(path)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
 	p := params{}
 	dec := yaml.NewDecoder(f)
@@ -115,7 +111,19 @@ func runCase(path string, info os.FileInfo, err error) error {
 	}
 
 	log.Printf("Check %q", base)
-	err = check(html, p)
+
+	// If a HAR file exists, replay the recorded traffic for the original URL.
+	// Otherwise, serve the corresponding HTML file.
+	_, err = os.Stat(har)
+	if err == nil {
+		err = checkHAR(har, p)
+	} else {
+		_, err = os.Stat(html)
+		if err != nil {
+			return err
+		}
+		err = check(html, p)
+	}
 
 	if err != nil {
 		return fmt.Errorf("case %q, %v", base, err)
@@ -129,7 +137,20 @@ func check(html string, p params) error {
 	base := strings.TrimSuffix(filepath.Base(html), filepath.Ext(html))
 	outfile := filepath.Join(tempdir, base+".output")
 
-	cmd := exec.Command(tool, url, outfile)
+	return checkOutput(exec.Command(tool, url, outfile), outfile, p)
+}
+
+func checkHAR(har string, p params) error {
+	if p.URL == "" {
+		return fmt.Errorf("missing url for %q", har)
+	}
+	base := strings.TrimSuffix(filepath.Base(har), filepath.Ext(har))
+	outfile := filepath.Join(tempdir, base+".output")
+
+	return checkOutput(exec.Command(tool, "-replay", har, p.URL, outfile), outfile, p)
+}
+
+func checkOutput(cmd *exec.Cmd, outfile string, p params) error {
 	output, err := cmd.Output()
 	if err != nil {
 		log.Print("scrapen output:")
@@ -0,0 +1,119 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// DefaultMaxEnclosureSize is used by DownloadEnclosures if no size is given.
+const DefaultMaxEnclosureSize = 100 << 20
+
+// DownloadEnclosures creates a step that downloads the audio and video files
+// of the task into the Store. Files larger than maxSize bytes are skipped.
+//
+// Sets the Key of each enclosure that was downloaded.
+func DownloadEnclosures(maxSize int64) pipeline.Pipeline {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxEnclosureSize
+	}
+
+	return func(ctx context.Context, t *pipeline.Task) error {
+		log.WithFields(log.Fields{
+			"task":   t.ID,
+			"module": "assets",
+		}).Info("Download enclosures")
+
+		for i := range t.Enclosures {
+			enc := &t.Enclosures[i]
+			if enc.Key != "" {
+				continue
+			}
+			// skip early if the size is known
+			if enc.Size > maxSize {
+				log.WithFields(log.Fields{
+					"task":   t.ID,
+					"module": "assets",
+					"url":    enc.URL,
+					"size":   enc.Size,
+				}).Info("Skip large enclosure")
+				continue
+			}
+
+			err := downloadEnclosure(ctx, t, enc, maxSize)
+			if err != nil {
+				// all downloads are optional
+				log.WithFields(log.Fields{
+					"task":   t.ID,
+					"module": "assets",
+					"url":    enc.URL,
+					"error":  err,
+				}).Warning("Failed to download enclosure")
+			}
+		}
+
+		return nil
+	}
+}
+
+func downloadEnclosure(ctx context.Context, t *pipeline.Task, enc *pipeline.Enclosure, maxSize int64) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", enc.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.HTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("got HTTP status %v", res.StatusCode)
+	}
+	if res.ContentLength > maxSize {
+		return fmt.Errorf("size %v exceeds the limit of %v bytes", res.ContentLength, maxSize)
+	}
+
+	// read one more byte to detect files without Content-Length that are
+	// too large
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > maxSize {
+		return fmt.Errorf("size exceeds the limit of %v bytes", maxSize)
+	}
+
+	contentType := enc.ContentType
+	if mt, _, err := mime.ParseMediaType(res.Header.Get("Content-Type")); err == nil && mt != "application/octet-stream" {
+		contentType = mt
+	}
+
+	key := uuid.New().String() + fileExt(contentType)
+	err = t.PutAsset(key, contentType, data)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "assets",
+		"url":    enc.URL,
+		"key":    key,
+	}).Info("Downloaded enclosure")
+
+	enc.Key = key
+	enc.Size = int64(len(data))
+	if enc.ContentType == "" {
+		enc.ContentType = contentType
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package assets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestDownloadEnclosures(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/small.mp3":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Write([]byte("0123456789"))
+		case "/large.mp4":
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Write([]byte(strings.Repeat("x", 100)))
+		case "/stream.mp3":
+			// no Content-Length
+			w.Header().Set("Content-Type", "audio/mpeg")
+			for i := 0; i < 10; i++ {
+				w.Write([]byte(strings.Repeat("x", 10)))
+				w.(http.Flusher).Flush()
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	task := &pipeline.Task{Store: pipeline.NewMemoryStore()}
+	task.Enclosures = []pipeline.Enclosure{
+		{Type: "Audio", URL: srv.URL + "/small.mp3"},
+		{Type: "Video", URL: srv.URL + "/large.mp4"},
+		{Type: "Audio", URL: srv.URL + "/stream.mp3"},
+		{Type: "Video", URL: srv.URL + "/known.mp4", Size: 1000},
+		{Type: "Audio", URL: srv.URL + "/missing.mp3"},
+	}
+
+	err := DownloadEnclosures(50)(context.TODO(), task)
+	assert.Nil(err)
+
+	enc := task.Enclosures[0]
+	assert.True(strings.HasSuffix(enc.Key, ".mp3"))
+	assert.Equal(int64(10), enc.Size)
+	assert.Equal("audio/mpeg", enc.ContentType)
+	ct, data, err := task.GetAsset(enc.Key)
+	assert.Nil(err)
+	assert.Equal("audio/mpeg", ct)
+	assert.Equal("0123456789", string(data))
+
+	for _, enc := range task.Enclosures[1:] {
+		assert.Equal("", enc.Key, enc.URL)
+	}
+}
@@ -19,8 +19,6 @@ import (
 	"github.com/akeil/scrapen/internal/pipeline"
 )
 
-var client = &http.Client{}
-
 // DownloadImages finds img tags in the HTML and downloads the referenced images.
 //
 // Replaces the images src attribute with a "store://xyz..." url.
@@ -43,7 +41,7 @@ func DownloadImages(ctx context.Context, t *pipeline.Task) error {
 		if u.Scheme == "data" {
 			i, data, err = fetchData(src)
 		} else if u.Scheme == "http" || u.Scheme == "https" { // assume HTTP
-			i, data, err = fetchHTTP(ctx, src)
+			i, data, err = fetchHTTP(ctx, t.HTTPClient(), src)
 		} else {
 			err = fmt.Errorf("unsupported scheme %q", u.Scheme)
 		}
@@ -90,6 +88,8 @@ func DownloadImages(ctx context.Context, t *pipeline.Task) error {
 		}).Warning("Failed to download metadata image")
 	}
 
+	doSiteImages(f, t)
+
 	// ignore errors - all image downloads are optional
 	return nil
 }
@@ -132,7 +132,7 @@ func doImages(f fetchFunc, t *pipeline.Task) error {
 	return nil
 }
 
-func fetchHTTP(ctx context.Context, src string) (pipeline.ImageInfo, []byte, error) {
+func fetchHTTP(ctx context.Context, client *http.Client, src string) (pipeline.ImageInfo, []byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", src, nil)
 	if err != nil {
 		return pipeline.ImageInfo{}, nil, err
@@ -250,6 +250,20 @@ func doMetadataImages(f fetchFunc, t *pipeline.Task) error {
 	return nil
 }
 
+// doSiteImages downloads the site icon and logo.
+// Errors are logged by the fetch function and otherwise ignored.
+func doSiteImages(f fetchFunc, t *pipeline.Task) {
+	for _, u := range []*string{&t.SiteIcon, &t.SiteLogo} {
+		if *u == "" {
+			continue
+		}
+		src, err := f(*u)
+		if err == nil {
+			*u = src
+		}
+	}
+}
+
 func findExistingImage(t *pipeline.Task) pipeline.ImageInfo {
 	for _, img := range t.Images {
 		if t.ImageURL == img.OriginalURL {
@@ -13,6 +13,20 @@ func fileExt(mime string) string {
 		return ".gif"
 	case "image/svg+xml", "image/svg":
 		return ".svg"
+	case "image/x-icon", "image/vnd.microsoft.icon":
+		return ".ico"
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3"
+	case "audio/mp4", "audio/x-m4a", "audio/aac":
+		return ".m4a"
+	case "audio/ogg", "application/ogg":
+		return ".ogg"
+	case "audio/opus":
+		return ".opus"
+	case "video/mp4":
+		return ".mp4"
+	case "video/webm", "audio/webm":
+		return ".webm"
 	default:
 		return ""
 	}
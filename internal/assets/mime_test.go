@@ -14,6 +14,7 @@ func TestFileExt(t *testing.T) {
 	assert.Equal(".jpg", fileExt("image/jpeg"))
 	assert.Equal(".gif", fileExt("image/gif"))
 	assert.Equal(".svg", fileExt("image/svg+xml"))
+	assert.Equal(".ico", fileExt("image/x-icon"))
 
 	assert.Equal(".jpg", fileExt("image/jpg")) // non standard support
 	assert.Equal(".png", fileExt("IMAGE/PNG")) // case insensitive
@@ -0,0 +1,132 @@
+package cite
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// BibTeX writes a BibTeX entry for the article.
+//
+// Web pages are exported as @misc entries, the "url" and "urldate" fields are
+// understood by biblatex and most reference managers.
+func BibTeX(w io.Writer, t *pipeline.Task) error {
+	c := citation(t)
+	entryType, containerField := bibtexType(t)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("@%v{%v,\n", entryType, key(t)))
+
+	// URLs and DOIs are verbatim fields and must not be escaped
+	raw := func(name, value string) {
+		if name != "" && value != "" {
+			b.WriteString(fmt.Sprintf("  %v = {%v},\n", name, value))
+		}
+	}
+	field := func(name, value string) {
+		raw(name, bibtexEscape(value))
+	}
+
+	names := make([]string, 0)
+	for _, a := range authors(t) {
+		// braces keep BibTeX from splitting or reversing the name
+		if isOrganization(a.Name) {
+			names = append(names, "{"+bibtexEscape(a.Name)+"}")
+			continue
+		}
+		family, given := splitName(a.Name)
+		if given == "" {
+			names = append(names, bibtexEscape(family))
+		} else {
+			names = append(names, bibtexEscape(family+", "+given))
+		}
+	}
+	raw("author", strings.Join(names, " and "))
+	field("title", t.Title)
+	field(containerField, container(t))
+
+	if t.PubDate != nil {
+		field("year", t.PubDate.Format("2006"))
+		// the month macros are not quoted
+		if t.PubDatePrecision != pipeline.PrecisionYear {
+			b.WriteString(fmt.Sprintf("  month = %v,\n", bibtexMonths[t.PubDate.Month()-1]))
+		}
+	}
+
+	field("volume", c.Volume)
+	field("number", c.Issue)
+	field("pages", pages(c, "--"))
+	field("publisher", c.Publisher)
+	switch entryType {
+	case "phdthesis":
+		field("school", c.Institution)
+	case "techreport":
+		field("institution", c.Institution)
+	}
+	field("issn", c.ISSN)
+	field("isbn", c.ISBN)
+	raw("doi", c.DOI)
+	raw("url", pageURL(t))
+	if d := accessed(t); d != nil {
+		field("urldate", d.Format("2006-01-02"))
+	}
+	field("language", t.Language)
+	field("keywords", strings.Join(t.Keywords, ", "))
+	field("abstract", t.Description)
+
+	b.WriteString("}\n")
+
+	_, err := w.Write([]byte(b.String()))
+	return err
+}
+
+// bibtexType returns the entry type and the name of the field which holds
+// the container title, empty if the type has none.
+func bibtexType(t *pipeline.Task) (string, string) {
+	switch itemType(t) {
+	case "article-journal":
+		return "article", "journal"
+	case "paper-conference":
+		return "inproceedings", "booktitle"
+	case "chapter":
+		return "incollection", "booktitle"
+	case "book":
+		return "book", ""
+	case "thesis":
+		return "phdthesis", ""
+	case "report":
+		return "techreport", ""
+	case "article":
+		if citation(t).Journal != "" {
+			return "article", "journal"
+		}
+	}
+	return "misc", "howpublished"
+}
+
+var bibtexMonths = []string{
+	"jan", "feb", "mar", "apr", "may", "jun",
+	"jul", "aug", "sep", "oct", "nov", "dec",
+}
+
+var bibtexReplacer = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// bibtexEscape escapes characters with a special meaning in BibTeX.
+// Line breaks are replaced with spaces.
+func bibtexEscape(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return bibtexReplacer.Replace(s)
+}
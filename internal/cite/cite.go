@@ -0,0 +1,177 @@
+// Package cite exports bibliographic entries for scraped articles.
+//
+// Articles with citation metadata are exported with their item type, e.g. as
+// a journal article. All other articles are exported as web pages.
+package cite
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// item type for articles without citation metadata
+const typeWebpage = "webpage"
+
+// itemType returns the CSL item type for the article.
+func itemType(t *pipeline.Task) string {
+	if t.Citation != nil && t.Citation.Type != "" {
+		return t.Citation.Type
+	}
+	return typeWebpage
+}
+
+// citation returns the citation data, or an empty citation if there is none.
+func citation(t *pipeline.Task) pipeline.Citation {
+	if t.Citation != nil {
+		return *t.Citation
+	}
+	return pipeline.Citation{}
+}
+
+// container returns the title of the journal, proceedings or web site.
+func container(t *pipeline.Task) string {
+	c := citation(t)
+	if c.Journal != "" {
+		return c.Journal
+	}
+	return t.SiteName
+}
+
+// pageURL returns the URL for the article, the canonical URL if it is known.
+func pageURL(t *pipeline.Task) string {
+	if t.CanonicalURL != "" {
+		return t.CanonicalURL
+	}
+	return t.ContentURL()
+}
+
+// authors returns the authors of the article, without editors or
+// contributors.
+func authors(t *pipeline.Task) []pipeline.Author {
+	result := make([]pipeline.Author, 0)
+	for _, a := range t.Authors {
+		if a.Name == "" {
+			continue
+		}
+		if a.Role == "" || a.Role == "author" {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// splitName splits a name into family and given name.
+// The given name is empty if the name consists of a single word.
+func splitName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if i := strings.Index(name, ","); i > 0 {
+		return strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+1:])
+	}
+
+	i := strings.LastIndex(name, " ")
+	if i < 0 {
+		return name, ""
+	}
+	return name[i+1:], strings.TrimSpace(name[:i])
+}
+
+// isOrganization tells if the name is probably not the name of a person,
+// e.g. "The New York Times" or "Smith and Sons".
+// Names in the form "Family, Given" are always persons.
+func isOrganization(name string) bool {
+	if strings.Contains(name, ",") {
+		return false
+	}
+
+	words := strings.Fields(strings.ToLower(name))
+	if len(words) > 3 || (len(words) > 1 && words[0] == "the") {
+		return true
+	}
+	for _, w := range words {
+		if w == "and" || w == "&" {
+			return true
+		}
+	}
+	return false
+}
+
+// pages returns the page range with the given separator.
+func pages(c pipeline.Citation, sep string) string {
+	if c.FirstPage == "" {
+		return ""
+	}
+	if c.LastPage == "" || c.LastPage == c.FirstPage {
+		return c.FirstPage
+	}
+	return c.FirstPage + sep + c.LastPage
+}
+
+// key creates a citation key like "doe2021example" from the first author,
+// the year and the first word of the title.
+func key(t *pipeline.Task) string {
+	var b strings.Builder
+
+	as := authors(t)
+	if len(as) > 0 {
+		family, _ := splitName(as[0].Name)
+		b.WriteString(keyPart(family))
+	}
+
+	if t.PubDate != nil {
+		b.WriteString(t.PubDate.Format("2006"))
+	}
+
+	for _, word := range strings.Fields(t.Title) {
+		w := keyPart(word)
+		if len(w) > 3 && !stopWords[w] {
+			b.WriteString(w)
+			break
+		}
+	}
+
+	if b.Len() == 0 {
+		return "item"
+	}
+	return b.String()
+}
+
+var stopWords = map[string]bool{
+	"about": true,
+	"from":  true,
+	"into":  true,
+	"that":  true,
+	"their": true,
+	"there": true,
+	"these": true,
+	"this":  true,
+	"what":  true,
+	"when":  true,
+	"where": true,
+	"which": true,
+	"with":  true,
+}
+
+// keyPart reduces a word to lower case ASCII letters,
+// diacritics are removed.
+func keyPart(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// accessed returns the date when the article was retrieved.
+func accessed(t *pipeline.Task) *time.Time {
+	if t.Retrieved.IsZero() {
+		return nil
+	}
+	return &t.Retrieved
+}
@@ -0,0 +1,195 @@
+package cite
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func journalArticle() *pipeline.Task {
+	pub := time.Date(2021, 8, 15, 0, 0, 0, 0, time.UTC)
+	return &pipeline.Task{
+		URL:       "https://journal.example.com/article/123",
+		Title:     "On the Nature of Examples",
+		Retrieved: time.Date(2022, 1, 2, 10, 0, 0, 0, time.UTC),
+		PubDate:   &pub,
+		Authors: []pipeline.Author{
+			{Name: "Jane Doe", Role: "author"},
+			{Name: "Richard Roe", Role: "author"},
+			{Name: "Ed Itor", Role: "editor"},
+		},
+		Keywords: []string{"examples", "theory"},
+		Citation: &pipeline.Citation{
+			Type:      "article-journal",
+			DOI:       "10.1234/joe_2021",
+			Journal:   "Journal of Examples & Co",
+			Volume:    "12",
+			Issue:     "3",
+			FirstPage: "101",
+			LastPage:  "117",
+		},
+	}
+}
+
+func webPage() *pipeline.Task {
+	return &pipeline.Task{
+		URL:       "https://example.com/blog/post",
+		Title:     "The Blog Post",
+		SiteName:  "Example Blog",
+		Retrieved: time.Date(2022, 1, 2, 10, 0, 0, 0, time.UTC),
+		Authors: []pipeline.Author{
+			{Name: "Ümit Öztürk"},
+		},
+	}
+}
+
+func TestBibTeX(t *testing.T) {
+	assert := assert.New(t)
+
+	var b bytes.Buffer
+	err := BibTeX(&b, journalArticle())
+	assert.Nil(err)
+
+	expected := `@article{doe2021nature,
+  author = {Doe, Jane and Roe, Richard},
+  title = {On the Nature of Examples},
+  journal = {Journal of Examples \& Co},
+  year = {2021},
+  month = aug,
+  volume = {12},
+  number = {3},
+  pages = {101--117},
+  doi = {10.1234/joe_2021},
+  url = {https://journal.example.com/article/123},
+  urldate = {2022-01-02},
+  keywords = {examples, theory},
+}
+`
+	assert.Equal(expected, b.String())
+
+	b.Reset()
+	err = BibTeX(&b, webPage())
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(b.String(), "@misc{ozturkblog,\n"))
+	assert.Contains(b.String(), "howpublished = {Example Blog}")
+
+	// organizations are not split
+	task := webPage()
+	task.Authors = []pipeline.Author{
+		{Name: "The New York Times"},
+		{Name: "Smith and Sons"},
+		{Name: "Doe, Jane"},
+	}
+	b.Reset()
+	err = BibTeX(&b, task)
+	assert.Nil(err)
+	assert.Contains(b.String(), "author = {{The New York Times} and {Smith and Sons} and Doe, Jane},")
+
+	// year only
+	task = journalArticle()
+	task.PubDatePrecision = pipeline.PrecisionYear
+	b.Reset()
+	err = BibTeX(&b, task)
+	assert.Nil(err)
+	assert.Contains(b.String(), "year = {2021}")
+	assert.NotContains(b.String(), "month = ")
+}
+
+func TestRIS(t *testing.T) {
+	assert := assert.New(t)
+
+	var b bytes.Buffer
+	err := RIS(&b, journalArticle())
+	assert.Nil(err)
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	assert.Equal("TY  - JOUR", lines[0])
+	assert.Contains(lines, "AU  - Doe, Jane")
+	assert.Contains(lines, "AU  - Roe, Richard")
+	assert.NotContains(lines, "AU  - Itor, Ed")
+	assert.Contains(lines, "T2  - Journal of Examples & Co")
+	assert.Contains(lines, "DA  - 2021/08/15")
+	assert.Contains(lines, "SP  - 101")
+	assert.Contains(lines, "EP  - 117")
+	assert.Contains(lines, "DO  - 10.1234/joe_2021")
+	assert.Contains(lines, "KW  - theory")
+	assert.Equal("ER  - ", lines[len(lines)-1])
+
+	b.Reset()
+	err = RIS(&b, webPage())
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(b.String(), "TY  - ELEC\r\n"))
+
+	task := webPage()
+	task.Authors = []pipeline.Author{{Name: "The New York Times"}}
+	b.Reset()
+	err = RIS(&b, task)
+	assert.Nil(err)
+	assert.Contains(b.String(), "AU  - The New York Times\r\n")
+
+	// incomplete dates
+	task = journalArticle()
+	task.PubDatePrecision = pipeline.PrecisionMonth
+	b.Reset()
+	err = RIS(&b, task)
+	assert.Nil(err)
+	assert.Contains(b.String(), "DA  - 2021/08/\r\n")
+
+	task.PubDatePrecision = pipeline.PrecisionYear
+	b.Reset()
+	err = RIS(&b, task)
+	assert.Nil(err)
+	assert.Contains(b.String(), "PY  - 2021\r\n")
+	assert.NotContains(b.String(), "DA  - ")
+}
+
+func TestCSL(t *testing.T) {
+	assert := assert.New(t)
+
+	var b bytes.Buffer
+	err := CSL(&b, journalArticle())
+	assert.Nil(err)
+
+	s := b.String()
+	assert.Contains(s, `"id": "doe2021nature"`)
+	assert.Contains(s, `"type": "article-journal"`)
+	assert.Contains(s, `"container-title": "Journal of Examples & Co"`)
+	assert.Contains(s, `"page": "101-117"`)
+	assert.Contains(s, `"DOI": "10.1234/joe_2021"`)
+	assert.Contains(s, `"family": "Doe"`)
+	assert.Contains(s, `"given": "Jane"`)
+	assert.NotContains(s, `"Itor"`)
+
+	b.Reset()
+	err = CSL(&b, webPage())
+	assert.Nil(err)
+	assert.Contains(b.String(), `"type": "webpage"`)
+	assert.Contains(b.String(), `"family": "Öztürk"`)
+
+	task := webPage()
+	task.Authors = []pipeline.Author{{Name: "Staff"}}
+	b.Reset()
+	err = CSL(&b, task)
+	assert.Nil(err)
+	assert.Contains(b.String(), `"literal": "Staff"`)
+
+	task.Authors = []pipeline.Author{{Name: "The New York Times"}}
+	b.Reset()
+	err = CSL(&b, task)
+	assert.Nil(err)
+	assert.Contains(b.String(), `"literal": "The New York Times"`)
+
+	// year only
+	task = journalArticle()
+	task.PubDatePrecision = pipeline.PrecisionYear
+	b.Reset()
+	err = CSL(&b, task)
+	assert.Nil(err)
+	compact := strings.Join(strings.Fields(b.String()), "")
+	assert.Contains(compact, `"issued":{"date-parts":[[2021]]}`)
+}
@@ -0,0 +1,105 @@
+package cite
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// CSL writes a CSL-JSON item for the article.
+// The output is a JSON array with a single item.
+//
+// see: https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html
+func CSL(w io.Writer, t *pipeline.Task) error {
+	c := citation(t)
+
+	item := cslItem{
+		ID:             key(t),
+		Type:           itemType(t),
+		Title:          t.Title,
+		ContainerTitle: container(t),
+		Issued:         cslDate(t.PubDate, t.PubDatePrecision),
+		Accessed:       cslDate(accessed(t), ""),
+		URL:            pageURL(t),
+		DOI:            c.DOI,
+		Volume:         c.Volume,
+		Issue:          c.Issue,
+		Page:           pages(c, "-"),
+		Publisher:      c.Publisher,
+		ISSN:           c.ISSN,
+		ISBN:           c.ISBN,
+		Language:       t.Language,
+		Keyword:        strings.Join(t.Keywords, ", "),
+		Abstract:       t.Description,
+	}
+	if c.Publisher == "" {
+		item.Publisher = c.Institution
+	}
+
+	for _, a := range authors(t) {
+		family, given := splitName(a.Name)
+		if given == "" || isOrganization(a.Name) {
+			item.Author = append(item.Author, cslName{Literal: strings.TrimSpace(a.Name)})
+		} else {
+			item.Author = append(item.Author, cslName{Family: family, Given: given})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode([]cslItem{item})
+}
+
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title,omitempty"`
+	Author         []cslName `json:"author,omitempty"`
+	ContainerTitle string    `json:"container-title,omitempty"`
+	Issued         *cslDates `json:"issued,omitempty"`
+	Accessed       *cslDates `json:"accessed,omitempty"`
+	URL            string    `json:"URL,omitempty"`
+	DOI            string    `json:"DOI,omitempty"`
+	Volume         string    `json:"volume,omitempty"`
+	Issue          string    `json:"issue,omitempty"`
+	Page           string    `json:"page,omitempty"`
+	Publisher      string    `json:"publisher,omitempty"`
+	ISSN           string    `json:"ISSN,omitempty"`
+	ISBN           string    `json:"ISBN,omitempty"`
+	Language       string    `json:"language,omitempty"`
+	Keyword        string    `json:"keyword,omitempty"`
+	Abstract       string    `json:"abstract,omitempty"`
+}
+
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDates struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// cslDate creates the date parts for a date, with only the year or the year
+// and month for an incomplete date.
+func cslDate(t *time.Time, precision string) *cslDates {
+	if t == nil {
+		return nil
+	}
+
+	parts := []int{t.Year(), int(t.Month()), t.Day()}
+	switch precision {
+	case pipeline.PrecisionYear:
+		parts = parts[:1]
+	case pipeline.PrecisionMonth:
+		parts = parts[:2]
+	}
+	return &cslDates{
+		DateParts: [][]int{parts},
+	}
+}
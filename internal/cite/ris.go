@@ -0,0 +1,99 @@
+package cite
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// RIS writes an entry in the RIS format for the article.
+//
+// see: https://en.wikipedia.org/wiki/RIS_(file_format)
+func RIS(w io.Writer, t *pipeline.Task) error {
+	c := citation(t)
+
+	var b strings.Builder
+	tag := func(name, value string) {
+		value = strings.Join(strings.Fields(value), " ")
+		if value != "" {
+			// the format requires CRLF line endings
+			b.WriteString(fmt.Sprintf("%v  - %v\r\n", name, value))
+		}
+	}
+
+	tag("TY", risType(t))
+	for _, a := range authors(t) {
+		family, given := splitName(a.Name)
+		if given == "" || isOrganization(a.Name) {
+			tag("AU", strings.TrimSpace(a.Name))
+		} else {
+			tag("AU", family+", "+given)
+		}
+	}
+	tag("TI", t.Title)
+	tag("T2", container(t))
+
+	if t.PubDate != nil {
+		tag("PY", t.PubDate.Format("2006"))
+		switch t.PubDatePrecision {
+		case pipeline.PrecisionYear:
+			// the year is in PY
+		case pipeline.PrecisionMonth:
+			tag("DA", t.PubDate.Format("2006/01/"))
+		default:
+			tag("DA", t.PubDate.Format("2006/01/02"))
+		}
+	}
+
+	tag("VL", c.Volume)
+	tag("IS", c.Issue)
+	tag("SP", c.FirstPage)
+	tag("EP", c.LastPage)
+	tag("PB", c.Publisher)
+	if c.Publisher == "" {
+		tag("PB", c.Institution)
+	}
+	tag("SN", c.ISSN)
+	tag("SN", c.ISBN)
+	tag("DO", c.DOI)
+	tag("UR", pageURL(t))
+	tag("L1", c.PDFURL)
+	if d := accessed(t); d != nil {
+		tag("Y2", d.Format("2006/01/02"))
+	}
+	tag("LA", t.Language)
+	for _, k := range t.Keywords {
+		tag("KW", k)
+	}
+	tag("AB", t.Description)
+
+	b.WriteString("ER  - \r\n")
+
+	_, err := w.Write([]byte(b.String()))
+	return err
+}
+
+func risType(t *pipeline.Task) string {
+	switch itemType(t) {
+	case "article-journal":
+		return "JOUR"
+	case "paper-conference":
+		return "CPAPER"
+	case "chapter":
+		return "CHAP"
+	case "book":
+		return "BOOK"
+	case "thesis":
+		return "THES"
+	case "report":
+		return "RPRT"
+	case "article":
+		if citation(t).Journal != "" {
+			return "JOUR"
+		}
+		return "GEN"
+	}
+	return "ELEC"
+}
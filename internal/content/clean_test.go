@@ -6,8 +6,6 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/stretchr/testify/assert"
-
-	"github.com/akeil/scrapen/internal/pipeline"
 )
 
 func TestUnwrap(t *testing.T) {
@@ -188,50 +186,6 @@ func TestNormalizeUrls(t *testing.T) {
 	assert.Equal(`<img src="https://normal.png"/>`, str(d))
 }
 
-func TestStripFromTitle(t *testing.T) {
-	assert := assert.New(t)
-	task := &pipeline.Task{}
-
-	task.Title = "No Prefix"
-	stripFromTitle(task)
-	assert.Equal("No Prefix", task.Title)
-
-	task.Title = "Prefix | The Actual Title"
-	stripFromTitle(task)
-	assert.Equal("The Actual Title", task.Title)
-
-	task.Title = "The Actual Title | Suffix"
-	stripFromTitle(task)
-	assert.Equal("The Actual Title", task.Title)
-
-	// with site name as prefix or suffix w/ various separators
-	task.Title = "The Actual Title - The Site Name"
-	task.SiteName = "The Site Name"
-	stripFromTitle(task)
-	assert.Equal("The Actual Title", task.Title)
-
-	task.Title = "The Actual Title: The Site Name"
-	task.SiteName = "The Site Name"
-	stripFromTitle(task)
-	assert.Equal("The Actual Title", task.Title)
-
-	task.Title = "The Site Name | The Actual Title"
-	task.SiteName = "The Site Name"
-	stripFromTitle(task)
-	assert.Equal("The Actual Title", task.Title)
-
-	task.Title = "The Site Name: The Actual Title"
-	task.SiteName = "The Site Name"
-	stripFromTitle(task)
-	assert.Equal("The Actual Title", task.Title)
-
-	// not to be stripped
-	task.Title = "The Actual Title can contain The Site Name"
-	task.SiteName = "The Site Name"
-	stripFromTitle(task)
-	assert.Equal("The Actual Title can contain The Site Name", task.Title)
-}
-
 func doc(s string) *goquery.Document {
 	r := strings.NewReader(s)
 	doc, err := goquery.NewDocumentFromReader(r)
@@ -0,0 +1,144 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/oembed"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// do not make more requests than this for a single document
+const maxEmbeds = 20
+
+// ResolveEmbeds replaces embedded players (iframes) from known oEmbed
+// providers with a figure that shows the thumbnail, title, author and
+// provider of the embedded content.
+//
+// Iframes for which no oEmbed data is available are left alone, they are
+// handled in Prepare.
+func ResolveEmbeds(ctx context.Context, t *pipeline.Task) error {
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "content",
+	}).Info("Resolve embedded content")
+
+	doc := t.Document()
+	resolveEmbeds(ctx, t, doc)
+
+	altDoc := t.AltDocument()
+	if altDoc != nil {
+		resolveEmbeds(ctx, t, altDoc)
+	}
+
+	return nil
+}
+
+func resolveEmbeds(ctx context.Context, t *pipeline.Task, doc *goquery.Document) {
+	count := 0
+	doc.Find("iframe").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		src, _ := s.Attr("src")
+		if src == "" {
+			return true
+		}
+		resolved, err := t.ResolveURL(src)
+		if err == nil {
+			src = resolved
+		}
+
+		contentURL := oembed.ContentURL(src)
+		endpoint := oembed.Endpoint(contentURL)
+		if endpoint == "" {
+			return true
+		}
+
+		count++
+		r, err := oembed.Fetch(ctx, t.HTTPClient(), endpoint)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"task":   t.ID,
+				"module": "content",
+				"url":    contentURL,
+				"error":  err,
+			}).Warning("Failed to read oEmbed for iframe")
+		} else {
+			s.ReplaceWithHtml(embedFigure(contentURL, r))
+		}
+
+		return count < maxEmbeds
+	})
+}
+
+// embedFigure creates the HTML for embedded content.
+func embedFigure(contentURL string, r *oembed.Response) string {
+	var b strings.Builder
+	b.WriteString(`<figure class="embed">`)
+
+	img := r.ThumbnailURL
+	width, height := r.ThumbnailWidth, r.ThumbnailHeight
+	if r.Type == "photo" && r.URL != "" {
+		img = r.URL
+		width, height = r.Width, r.Height
+	}
+
+	if img != "" {
+		b.WriteString(fmt.Sprintf(`<img src="%v"`, html.EscapeString(img)))
+		if width > 0 && height > 0 {
+			b.WriteString(fmt.Sprintf(` width="%d" height="%d"`, width, height))
+		}
+		b.WriteString(fmt.Sprintf(` alt="%v"/>`, html.EscapeString(r.Title)))
+	} else if r.Type == "rich" {
+		// e.g. posts from Twitter come as a blockquote with the text
+		b.WriteString(richQuote(r.HTML))
+	}
+
+	b.WriteString("<figcaption>")
+	title := r.Title
+	if title == "" {
+		title = contentURL
+	}
+	b.WriteString(fmt.Sprintf(`<a href="%v">%v</a>`, html.EscapeString(contentURL), html.EscapeString(title)))
+
+	if r.AuthorName != "" {
+		b.WriteString(" by ")
+		if r.AuthorURL != "" {
+			b.WriteString(fmt.Sprintf(`<a href="%v">%v</a>`, html.EscapeString(r.AuthorURL), html.EscapeString(r.AuthorName)))
+		} else {
+			b.WriteString(html.EscapeString(r.AuthorName))
+		}
+	}
+
+	if r.ProviderName != "" {
+		b.WriteString(" on ")
+		b.WriteString(html.EscapeString(r.ProviderName))
+	}
+	b.WriteString("</figcaption></figure>")
+
+	return b.String()
+}
+
+// richQuote returns the first blockquote from the HTML of a "rich" oEmbed
+// response. Scripts and players are not kept.
+func richQuote(s string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(s))
+	if err != nil {
+		return ""
+	}
+
+	q := doc.Find("blockquote").First()
+	if q.Length() == 0 {
+		return ""
+	}
+	q.Find("script, iframe").Remove()
+
+	h, err := goquery.OuterHtml(q)
+	if err != nil {
+		return ""
+	}
+	return h
+}
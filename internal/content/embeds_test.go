@@ -0,0 +1,86 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// redirect sends all requests to the test server
+type redirect struct {
+	target *url.URL
+}
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestResolveEmbeds(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case "https://vimeo.com/12345":
+			w.Write([]byte(`{
+				"type": "video",
+				"title": "A <Video>",
+				"author_name": "Someone",
+				"author_url": "https://vimeo.com/someone",
+				"provider_name": "Vimeo",
+				"thumbnail_url": "https://i.vimeocdn.com/video/1.jpg",
+				"thumbnail_width": 640,
+				"thumbnail_height": 360
+			}`))
+		case "https://twitter.com/someone/status/1":
+			w.Write([]byte(`{
+				"type": "rich",
+				"author_name": "Someone",
+				"provider_name": "Twitter",
+				"html": "<blockquote class=\"twitter-tweet\"><p>Hello</p></blockquote><script src=\"widgets.js\"></script>"
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	target, _ := url.Parse(srv.URL)
+
+	task := &pipeline.Task{
+		ActualURL: "https://example.com/article",
+		Client:    &http.Client{Transport: redirect{target}},
+	}
+	task.SetHTML(`<html><body>
+		<iframe src="https://player.vimeo.com/video/12345"></iframe>
+		<iframe src="https://twitter.com/someone/status/1"></iframe>
+		<iframe src="https://www.youtube.com/embed/abc"></iframe>
+		<iframe src="https://example.com/widget"></iframe>
+	</body></html>`)
+
+	err := ResolveEmbeds(context.TODO(), task)
+	assert.Nil(err)
+
+	doc := task.Document()
+	assert.Equal(2, doc.Find("figure.embed").Length())
+
+	video := doc.Find("figure.embed").First()
+	assert.Equal("https://i.vimeocdn.com/video/1.jpg", video.Find("img").AttrOr("src", ""))
+	assert.Equal("640", video.Find("img").AttrOr("width", ""))
+	assert.Equal("https://vimeo.com/12345", video.Find("figcaption a").First().AttrOr("href", ""))
+	assert.Equal("A <Video> by Someone on Vimeo", strings.TrimSpace(video.Find("figcaption").Text()))
+
+	tweet := doc.Find("figure.embed").Last()
+	assert.Equal("Hello", tweet.Find("blockquote").Text())
+	assert.Equal(0, tweet.Find("script").Length())
+
+	// no oEmbed data, left for Prepare
+	assert.Equal(2, doc.Find("iframe").Length())
+}
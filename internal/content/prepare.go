@@ -111,12 +111,21 @@ func resolveNoscriptImage(doc *goquery.Document) {
 // remove div's which wrap a only single element
 func unwrapDivs(doc *goquery.Document) {
 	doc.Find("div").Each(func(index int, sel *goquery.Selection) {
-		if sel.Children().Length() == 1 {
+		if sel.Children().Length() == 1 && !isMicroformat(sel) {
 			sel.Unwrap()
 		}
 	})
 }
 
+var microformatClass = regexp.MustCompile(`(^|\s)(h|e)-[a-z]+`)
+
+// isMicroformat tells if the element is a microformats2 item or holds
+// embedded content; these are needed later to find the content.
+func isMicroformat(sel *goquery.Selection) bool {
+	class, _ := sel.Attr("class")
+	return microformatClass.MatchString(class)
+}
+
 var whitespace = regexp.MustCompile(`\s`)
 
 // dropNavList attempts to find list elements that are used for navigation
@@ -37,6 +37,10 @@ func TestUnwrapDivs(t *testing.T) {
 	unwrapDivs(d)
 	assert.Equal(2, d.Find("div").Length())
 
+	// keep microformats
+	d = doc(`<div class="h-entry"><div class="e-content"><p>Text</p></div></div>`)
+	unwrapDivs(d)
+	assert.Equal(2, d.Find("div").Length())
 }
 
 func TestDropTemplates(t *testing.T) {
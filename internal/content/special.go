@@ -1,9 +1,11 @@
 package content
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -11,6 +13,7 @@ import (
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
 
+	"github.com/akeil/scrapen/internal/jsonld"
 	"github.com/akeil/scrapen/internal/pipeline"
 )
 
@@ -126,89 +129,165 @@ func youtubeVideo(src *url.URL, s *goquery.Selection) {
 
 // jsonLD handles JSON-LD markup as described on https://schema.org/
 // see: https://moz.com/blog/json-ld-for-beginners
+//
+// Metadata like title or author is read in the metadata module,
+// here we look for media that is attached to the content.
 func jsonLD(t *pipeline.Task) {
-	doc := t.Document()
-	// looking for   <script type="application/ld+json">...</script>
-	doc.Selection.Find("script").Each(func(i int, s *goquery.Selection) {
-		tp, _ := s.Attr("type")
-		if tp != "application/ld+json" {
-			return
-		}
+	g := jsonld.Parse(t.Document())
+	for _, n := range g.Find("Audio", "AudioObject", "VideoObject", "PodcastEpisode") {
+		ldMedia(t, n)
+	}
+}
 
-		data := make(map[string]interface{})
-		err := json.Unmarshal([]byte(s.Text()), &data)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"module": "content",
-				"error":  err,
-			}).Warning("Failed t parse JSON-LD")
+// ldMedia adds an enclosure for a media object. For a podcast episode, the
+// audio file is taken from the associated media.
+func ldMedia(t *pipeline.Task, n *jsonld.Node) {
+	media := n
+	typ := pipeline.EnclosureAudio
+	if n.Is("PodcastEpisode") {
+		media = n.Node("associatedMedia")
+		if media == nil {
+			media = n.Node("audio")
+		}
+		if media == nil {
 			return
 		}
+	} else if n.Is("VideoObject") {
+		typ = pipeline.EnclosureVideo
+	}
 
-		if ldType, ok := data["@type"].(string); ok {
-			switch ldType {
-			case "Audio":
-				ldAudio(t, data)
-				break
-			case "Article":
-				ldArticle(t, data)
-				break
-			case "Organization":
-				break
-			case "BreadcrumbList":
-				break
-			}
-		}
-	})
+	u := media.String("contentUrl")
+	if u == "" {
+		return
+	}
+	u, err := t.ResolveURL(u)
+	if err != nil {
+		return
+	}
+
+	enc := pipeline.Enclosure{
+		Type:        typ,
+		Title:       pipeline.FirstOf(n.String("name"), media.String("name")),
+		URL:         u,
+		ContentType: media.String("encodingFormat"),
+		Description: pipeline.FirstOf(n.String("description"), media.String("description")),
+		Duration:    pipeline.ParseDuration(pipeline.FirstOf(media.String("duration"), n.String("duration"), n.String("timeRequired"))),
+		Size:        parseSize(media.String("contentSize")),
+	}
+
+	image := pipeline.FirstOf(media.URL("thumbnailUrl"), media.URL("thumbnail"), media.URL("image"), n.URL("image"))
+	if image != "" {
+		enc.Image, _ = t.ResolveURL(image)
+	}
+
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "content",
+		"type":   enc.Type,
+		"url":    enc.URL,
+	}).Info("Add enclosure from JSON-LD")
+	t.AddEnclosure(enc)
 }
 
-func ldAudio(t *pipeline.Task, m map[string]interface{}) {
-	if u, ok := m["contentUrl"].(string); ok {
-		u, err := t.ResolveURL(u)
-		if err != nil {
+var sizePattern = regexp.MustCompile(`(?i)^([\d.,]+)\s*([kmgt]?i?b)?$`)
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseSize reads the size of a file in bytes from strings like "12345"
+// or "12.5 MB". Returns zero if the size cannot be parsed.
+func parseSize(s string) int64 {
+	m := sizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return int64(n * sizeUnits[strings.ToLower(m[2])])
+}
+
+// FindMedia adds an enclosure for each <audio> and <video> element in the
+// content.
+//
+// Use this after readability, so that players from sidebars, ads and
+// related videos are not included.
+func FindMedia(ctx context.Context, t *pipeline.Task) error {
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "content",
+	}).Info("Find media elements")
+
+	mediaElements(t)
+	return nil
+}
+
+// mediaElements adds an enclosure for each <audio> and <video> element
+// in the document.
+func mediaElements(t *pipeline.Task) {
+	doc := t.Document()
+	doc.Find("audio, video").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		ct, _ := s.Attr("type")
+		if src == "" {
+			source := s.Find("source[src]").First()
+			src, _ = source.Attr("src")
+			ct, _ = source.Attr("type")
+		}
+
+		src = strings.TrimSpace(src)
+		if src == "" {
+			return
+		}
+		u, err := t.ResolveURL(src)
+		if err != nil || !(strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")) {
 			return
 		}
 
-		name, _ := m["name"].(string)
-		ct, _ := m["encodingFormat"].(string)
-		desc, _ := m["description"].(string)
+		typ := pipeline.EnclosureAudio
+		if goquery.NodeName(s) == "video" {
+			typ = pipeline.EnclosureVideo
+		}
 
 		enc := pipeline.Enclosure{
-			Type:        "Audio",
-			Title:       name,
+			Type:        typ,
 			URL:         u,
-			ContentType: ct,
-			Description: desc,
+			ContentType: strings.TrimSpace(strings.Split(ct, ";")[0]),
 		}
-		log.Info("Add audio enclosure")
-		t.AddEnclosure(enc)
-	}
-}
 
-func ldArticle(t *pipeline.Task, m map[string]interface{}) {
-	/*  {
-	    "@context": "http://schema.org",
-	    "@type": "Article",
-	    "headline": "Der Emergency Quota Act von 1921",
-	    "image": ["https://static.deutschlandfunknova.de/editorial/_entryImage/210514_ellis_island_thumb.jpg?mtime=20210514092308&amp;focal=none&amp;tmtime=20210514123335"],
-	    "author":
-	    {
-	        "@type": "Organization",
-	        "name": "Deutschlandfunk Nova"
-	    },
-	    "publisher": {
-	        "@type": "Organization",
-	        "name": "Deutschlandfunk Nova",
-	        "logo": {
-	            "@type": "ImageObject",
-	            "url": "https://www.deutschlandfunknova.de/img/dlfnova-g.png"
-	        }
-	    },
-	    "datePublished": "2021-05-14",
-	    "dateModified": "2021-05-14",
-	    "description": "Der Emergency Quota Act von 1921 schloss alle Menschen aus asiatischen Ländern und Osteuropa von der Einwanderung in die USA aus.",
-	    "mainEntityOfPage": "https://www.deutschlandfunknova.de/beitrag/emergency-quota-act-einwanderung-in-die-usa-ab-1921"
-	    }     */
-
-	// TODO: these could be added to the metadata
+		title, _ := s.Attr("title")
+		if title == "" {
+			title, _ = s.Attr("aria-label")
+		}
+		if title == "" {
+			title = strings.Join(strings.Fields(s.Closest("figure").Find("figcaption").First().Text()), " ")
+		}
+		enc.Title = title
+
+		if poster, ok := s.Attr("poster"); ok && poster != "" {
+			enc.Image, _ = t.ResolveURL(poster)
+		}
+		if d, ok := s.Attr("data-duration"); ok {
+			enc.Duration = pipeline.ParseDuration(d)
+		}
+
+		log.WithFields(log.Fields{
+			"task":   t.ID,
+			"module": "content",
+			"type":   enc.Type,
+			"url":    enc.URL,
+		}).Info("Add enclosure from media element")
+		t.AddEnclosure(enc)
+	})
 }
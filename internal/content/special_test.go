@@ -0,0 +1,103 @@
+package content
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestMediaEnclosures(t *testing.T) {
+	assert := assert.New(t)
+
+	task := &pipeline.Task{ActualURL: "https://example.com/episode-1"}
+	task.SetHTML(`<html><head>
+        <script type="application/ld+json">{
+            "@context": "https://schema.org",
+            "@type": "PodcastEpisode",
+            "name": "Episode 1",
+            "description": "About the episode",
+            "timeRequired": "PT1H2M3S",
+            "image": "/episode1.jpg",
+            "associatedMedia": {
+                "@type": "MediaObject",
+                "contentUrl": "/episode1.mp3",
+                "encodingFormat": "audio/mpeg",
+                "contentSize": "56.5 MB"
+            }
+        }</script>
+        <script type="application/ld+json">{
+            "@context": "https://schema.org",
+            "@type": "VideoObject",
+            "name": "The Video",
+            "contentUrl": "https://cdn.example.com/video.mp4",
+            "thumbnailUrl": ["https://cdn.example.com/video.jpg"],
+            "duration": "PT2M30S"
+        }</script>
+    </head><body>
+        <audio controls src="/episode1.mp3"></audio>
+        <figure>
+            <video poster="poster.jpg" data-duration="90">
+                <source src="/clip.webm" type="video/webm; codecs=vp9">
+                <source src="/clip.mp4" type="video/mp4">
+            </video>
+            <figcaption>The clip</figcaption>
+        </figure>
+        <video src="blob:https://example.com/1234"></video>
+        <audio></audio>
+    </body></html>`)
+
+	jsonLD(task)
+	err := FindMedia(context.TODO(), task)
+	assert.Nil(err)
+
+	assert.Equal([]pipeline.Enclosure{
+		{
+			Type:        "Audio",
+			Title:       "Episode 1",
+			URL:         "https://example.com/episode1.mp3",
+			ContentType: "audio/mpeg",
+			Description: "About the episode",
+			Duration:    time.Hour + 2*time.Minute + 3*time.Second,
+			Size:        56500000,
+			Image:       "https://example.com/episode1.jpg",
+		},
+		{
+			Type:     "Video",
+			Title:    "The Video",
+			URL:      "https://cdn.example.com/video.mp4",
+			Duration: 150 * time.Second,
+			Image:    "https://cdn.example.com/video.jpg",
+		},
+		{
+			Type:        "Video",
+			Title:       "The clip",
+			URL:         "https://example.com/clip.webm",
+			ContentType: "video/webm",
+			Duration:    90 * time.Second,
+			Image:       "https://example.com/poster.jpg",
+		},
+	}, task.Enclosures)
+}
+
+func TestParseSize(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := map[string]int64{
+		"12345":     12345,
+		"12,345":    12345,
+		"12.5 MB":   12500000,
+		"1 KiB":     1024,
+		"2GB":       2000000000,
+		"":          0,
+		"unknown":   0,
+		"12 apples": 0,
+	}
+
+	for s, expected := range tests {
+		assert.Equal(expected, parseSize(s), s)
+	}
+}
@@ -0,0 +1,131 @@
+package cookies
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Import reads cookies from the given file and adds them to the jar.
+//
+// Supported formats are the Netscape `cookies.txt` format and a JSON export
+// as produced by common browser extensions.
+// Cookies that already exist in the jar are replaced.
+func (j *Jar) Import(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var entries []entry
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		entries, err = parseJSON(trimmed)
+	} else {
+		entries, err = parseNetscape(data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import cookies from %q: %v", path, err)
+	}
+
+	j.add(entries)
+	return nil
+}
+
+// parseJSON reads a list of cookies in JSON format.
+// Accepts a plain list or an object with the list under the key "cookies".
+func parseJSON(data []byte) ([]entry, error) {
+	entries := make([]entry, 0)
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var wrapper struct {
+			Cookies []entry `json:"cookies"`
+		}
+		err := json.Unmarshal(data, &wrapper)
+		if err != nil {
+			return nil, err
+		}
+		entries = wrapper.Cookies
+	} else {
+		err := json.Unmarshal(data, &entries)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, e := range entries {
+		entries[i] = normalizeEntry(e)
+	}
+	return entries, nil
+}
+
+const httpOnlyPrefix = "#HttpOnly_"
+
+// parseNetscape reads cookies from the Netscape `cookies.txt` format.
+//
+// Each line holds one cookie with tab-separated fields:
+//
+//	domain  include-subdomains  path  secure  expires  name  value
+//
+// see: https://curl.se/docs/http-cookies.html
+func parseNetscape(data []byte) ([]entry, error) {
+	entries := make([]entry, 0)
+	s := bufio.NewScanner(bytes.NewReader(data))
+	n := 0
+	for s.Scan() {
+		n++
+		line := strings.TrimRight(s.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			httpOnly = true
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %v: expected 7 fields, got %v", n, len(fields))
+		}
+
+		expires, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: invalid expiry %q", n, fields[4])
+		}
+
+		e := entry{
+			Domain:   fields[0],
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Expires:  expires,
+			Session:  expires == 0,
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		entries = append(entries, normalizeEntry(e))
+	}
+
+	return entries, s.Err()
+}
+
+func normalizeEntry(e entry) entry {
+	if strings.HasPrefix(e.Domain, ".") {
+		e.HostOnly = false
+	}
+	e.Domain = strings.ToLower(strings.TrimPrefix(e.Domain, "."))
+	if e.Path == "" {
+		e.Path = "/"
+	}
+	if e.Expires == 0 {
+		e.Session = true
+	}
+	return e
+}
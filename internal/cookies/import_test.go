@@ -0,0 +1,79 @@
+package cookies
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportNetscape(t *testing.T) {
+	assert := assert.New(t)
+
+	txt := "# Netscape HTTP Cookie File\n" +
+		"# This is a comment\n" +
+		"\n" +
+		".example.com\tTRUE\t/\tFALSE\t0\tsession\tabc\n" +
+		"#HttpOnly_www.example.com\tFALSE\t/news\tTRUE\t4102444800\tlogin\tdef\n" +
+		"old.example.com\tFALSE\t/\tFALSE\t946684800\texpired\tghi\n"
+
+	j := NewJar()
+	err := j.Import(write(t, "cookies.txt", txt))
+	assert.Nil(err)
+
+	assert.Equal([]string{"login=def", "session=abc"}, names(j.Cookies(parse("https://www.example.com/news/"))))
+	assert.Equal([]string{"session=abc"}, names(j.Cookies(parse("https://sub.example.com/news/"))))
+	assert.Equal([]string{"session=abc"}, names(j.Cookies(parse("https://old.example.com/"))))
+
+	// invalid
+	err = j.Import(write(t, "invalid.txt", "example.com\tTRUE\t/\n"))
+	assert.NotNil(err)
+}
+
+func TestImportJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	data := `[
+		{
+			"domain": ".example.com",
+			"expirationDate": 4102444800.5,
+			"hostOnly": false,
+			"httpOnly": true,
+			"name": "login",
+			"path": "/",
+			"secure": true,
+			"session": false,
+			"value": "abc"
+		},
+		{
+			"domain": "www.example.com",
+			"hostOnly": true,
+			"name": "session",
+			"path": "/",
+			"session": true,
+			"value": "def"
+		}
+	]`
+
+	j := NewJar()
+	err := j.Import(write(t, "cookies.json", data))
+	assert.Nil(err)
+	assert.Equal([]string{"login=abc", "session=def"}, names(j.Cookies(parse("https://www.example.com/"))))
+	assert.Equal([]string{"login=abc"}, names(j.Cookies(parse("https://example.com/"))))
+
+	// wrapped in an object
+	j = NewJar()
+	err = j.Import(write(t, "wrapped.json", `{"cookies": `+data+`}`))
+	assert.Nil(err)
+	assert.Equal(2, len(j.Cookies(parse("https://www.example.com/"))))
+}
+
+func write(t *testing.T, name, content string) string {
+	p := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(p, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
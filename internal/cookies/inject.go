@@ -0,0 +1,103 @@
+package cookies
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Host holds cookies and headers that should be sent with every request to
+// a specific host.
+type Host struct {
+	Cookies map[string]string
+	Headers map[string]string
+}
+
+// Inject wraps the given transport so that the configured cookies and headers
+// are added to each request.
+//
+// The hosts are keyed by host name. Settings for a host also apply to its
+// subdomains, settings for a more specific host take precedence.
+// If base is nil, `http.DefaultTransport` is used.
+func Inject(base http.RoundTripper, hosts map[string]Host) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	normalized := make(map[string]Host)
+	for k, v := range hosts {
+		normalized[strings.ToLower(strings.TrimPrefix(k, "."))] = v
+	}
+
+	return &injectTransport{
+		base:  base,
+		hosts: normalized,
+	}
+}
+
+type injectTransport struct {
+	base  http.RoundTripper
+	hosts map[string]Host
+}
+
+func (i *injectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	matching := i.matching(canonicalHost(req.URL.Host))
+	if len(matching) == 0 {
+		return i.base.RoundTrip(req)
+	}
+
+	// a RoundTripper must not modify the original request
+	r := req.Clone(req.Context())
+	for _, h := range matching {
+		for k, v := range h.Headers {
+			r.Header.Set(k, v)
+		}
+	}
+
+	// cookies from config replace cookies with the same name from the jar
+	configured := make(map[string]string)
+	for _, h := range matching {
+		for k, v := range h.Cookies {
+			configured[k] = v
+		}
+	}
+	if len(configured) > 0 {
+		existing := r.Cookies()
+		r.Header.Del("Cookie")
+		for _, c := range existing {
+			if _, ok := configured[c.Name]; !ok {
+				r.AddCookie(c)
+			}
+		}
+		names := make([]string, 0, len(configured))
+		for k := range configured {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			r.AddCookie(&http.Cookie{Name: k, Value: configured[k]})
+		}
+	}
+
+	return i.base.RoundTrip(r)
+}
+
+// matching returns the settings for all hosts that match the given host,
+// the most specific one last.
+func (i *injectTransport) matching(host string) []Host {
+	keys := make([]string, 0)
+	for k := range i.hosts {
+		if domainMatch(host, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		return len(keys[a]) < len(keys[b])
+	})
+
+	result := make([]Host, len(keys))
+	for n, k := range keys {
+		result[n] = i.hosts[k]
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package cookies
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInject(t *testing.T) {
+	assert := assert.New(t)
+
+	var got *http.Request
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r
+		return &http.Response{StatusCode: http.StatusOK, Request: r}, nil
+	})
+
+	tr := Inject(base, map[string]Host{
+		"example.com": Host{
+			Cookies: map[string]string{"login": "abc", "consent": "yes"},
+			Headers: map[string]string{"X-Foo": "foo", "User-Agent": "agent"},
+		},
+		"www.example.com": Host{
+			Headers: map[string]string{"X-Foo": "specific"},
+		},
+	})
+
+	req, _ := http.NewRequest("GET", "https://www.example.com/", nil)
+	req.Header.Set("User-Agent", "default")
+	req.AddCookie(&http.Cookie{Name: "login", Value: "from-jar"})
+	req.AddCookie(&http.Cookie{Name: "session", Value: "def"})
+
+	_, err := tr.RoundTrip(req)
+	assert.Nil(err)
+	assert.Equal("specific", got.Header.Get("X-Foo"))
+	assert.Equal("agent", got.Header.Get("User-Agent"))
+	assert.Equal("session=def; consent=yes; login=abc", got.Header.Get("Cookie"))
+
+	// original request is unchanged
+	assert.Equal("default", req.Header.Get("User-Agent"))
+
+	// other host
+	req, _ = http.NewRequest("GET", "https://other.com/", nil)
+	_, err = tr.RoundTrip(req)
+	assert.Nil(err)
+	assert.Equal("", got.Header.Get("X-Foo"))
+	assert.Equal("", got.Header.Get("Cookie"))
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,347 @@
+package cookies
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Jar is a cookie jar which can be saved to and loaded from a file.
+//
+// It implements the `http.CookieJar` interface.
+// Unlike the jar from `net/http/cookiejar`, session cookies are kept when the
+// jar is saved. This allows to keep a login session across multiple scrapes.
+type Jar struct {
+	path    string
+	entries map[string]entry
+	mx      sync.Mutex
+	// saveMx is held while the jar is written to its file
+	saveMx sync.Mutex
+}
+
+var (
+	jars   = make(map[string]*Jar)
+	jarsMx sync.Mutex
+)
+
+// Open returns the cookie jar that is stored in the given file.
+//
+// The jar is created if the file does not exist.
+// Repeated calls with the same path return the same jar, so that cookies are
+// shared between concurrent scrapes.
+func Open(path string) (*Jar, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	jarsMx.Lock()
+	defer jarsMx.Unlock()
+
+	j, ok := jars[abs]
+	if ok {
+		return j, nil
+	}
+
+	j = NewJar()
+	j.path = abs
+	err = j.load()
+	if err != nil {
+		return nil, err
+	}
+
+	jars[abs] = j
+	return j, nil
+}
+
+// NewJar creates an empty in-memory cookie jar.
+func NewJar() *Jar {
+	return &Jar{
+		entries: make(map[string]entry),
+	}
+}
+
+// entry is a single stored cookie.
+//
+// The JSON representation is compatible with the format used by common
+// browser extensions to export cookies.
+type entry struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	HostOnly bool    `json:"hostOnly"`
+	Secure   bool    `json:"secure"`
+	HttpOnly bool    `json:"httpOnly"`
+	Session  bool    `json:"session"`
+	Expires  float64 `json:"expirationDate,omitempty"`
+}
+
+func (e entry) id() string {
+	return fmt.Sprintf("%v;%v;%v", e.Domain, e.Path, e.Name)
+}
+
+func (e entry) expired(now time.Time) bool {
+	if e.Session || e.Expires == 0 {
+		return false
+	}
+	return now.After(time.Unix(int64(e.Expires), 0))
+}
+
+func (e entry) matches(u *url.URL, now time.Time) bool {
+	if e.expired(now) {
+		return false
+	}
+	if e.Secure && u.Scheme != "https" {
+		return false
+	}
+
+	host := canonicalHost(u.Host)
+	if e.HostOnly {
+		if host != e.Domain {
+			return false
+		}
+	} else if !domainMatch(host, e.Domain) {
+		return false
+	}
+
+	return pathMatch(requestPath(u), e.Path)
+}
+
+// SetCookies implements the `http.CookieJar` interface.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	now := time.Now()
+	host := canonicalHost(u.Host)
+
+	j.mx.Lock()
+	defer j.mx.Unlock()
+
+	for _, c := range cookies {
+		e, err := newEntry(c, u, host, now)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"module": "cookies",
+				"url":    u.String(),
+				"cookie": c.Name,
+				"error":  err,
+			}).Debug("Ignore cookie")
+			continue
+		}
+
+		if e.expired(now) || c.MaxAge < 0 {
+			delete(j.entries, e.id())
+			continue
+		}
+		j.entries[e.id()] = e
+	}
+}
+
+// Cookies implements the `http.CookieJar` interface.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+
+	now := time.Now()
+
+	j.mx.Lock()
+	matching := make([]entry, 0)
+	for _, e := range j.entries {
+		if e.matches(u, now) {
+			matching = append(matching, e)
+		}
+	}
+	j.mx.Unlock()
+
+	// longer paths first, see RFC 6265, 5.4
+	sort.Slice(matching, func(a, b int) bool {
+		if len(matching[a].Path) != len(matching[b].Path) {
+			return len(matching[a].Path) > len(matching[b].Path)
+		}
+		return matching[a].Name < matching[b].Name
+	})
+
+	cookies := make([]*http.Cookie, len(matching))
+	for i, e := range matching {
+		cookies[i] = &http.Cookie{Name: e.Name, Value: e.Value}
+	}
+	return cookies
+}
+
+// Save writes the content of the jar to its file.
+// Expired cookies are dropped.
+// Does nothing for a jar that was not opened from a file.
+func (j *Jar) Save() error {
+	if j.path == "" {
+		return nil
+	}
+
+	j.saveMx.Lock()
+	defer j.saveMx.Unlock()
+
+	j.mx.Lock()
+	now := time.Now()
+	list := make([]entry, 0, len(j.entries))
+	for _, e := range j.entries {
+		if !e.expired(now) {
+			list = append(list, e)
+		}
+	}
+	j.mx.Unlock()
+
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].id() < list[b].id()
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that we do not end up with
+	// a half-written jar
+	f, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), j.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func (j *Jar) load() error {
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	entries, err := parseJSON(data)
+	if err != nil {
+		return fmt.Errorf("failed to read cookie jar %q: %v", j.path, err)
+	}
+	j.add(entries)
+	return nil
+}
+
+func (j *Jar) add(entries []entry) {
+	now := time.Now()
+
+	j.mx.Lock()
+	defer j.mx.Unlock()
+
+	for _, e := range entries {
+		if e.Name == "" || e.Domain == "" || e.expired(now) {
+			continue
+		}
+		j.entries[e.id()] = e
+	}
+}
+
+func newEntry(c *http.Cookie, u *url.URL, host string, now time.Time) (entry, error) {
+	e := entry{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+
+	if e.Path == "" || !strings.HasPrefix(e.Path, "/") {
+		e.Path = defaultPath(u)
+	}
+
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if domain == "" || domain == host {
+		e.Domain = host
+		e.HostOnly = domain == ""
+	} else {
+		if !domainMatch(host, domain) {
+			return e, fmt.Errorf("domain %q does not match host %q", domain, host)
+		}
+		// do not accept cookies for a public suffix, e.g. ".co.uk"
+		ps, _ := publicsuffix.PublicSuffix(domain)
+		if ps == domain {
+			return e, fmt.Errorf("domain %q is a public suffix", domain)
+		}
+		e.Domain = domain
+	}
+
+	if c.MaxAge > 0 {
+		e.Expires = float64(now.Add(time.Duration(c.MaxAge) * time.Second).Unix())
+	} else if !c.Expires.IsZero() {
+		e.Expires = float64(c.Expires.Unix())
+	} else {
+		e.Session = true
+	}
+
+	return e, nil
+}
+
+func canonicalHost(h string) string {
+	host, _, err := net.SplitHostPort(h)
+	if err == nil {
+		h = host
+	}
+	return strings.ToLower(strings.TrimSuffix(h, "."))
+}
+
+// domainMatch tells if the given host is equal to or a subdomain of domain.
+func domainMatch(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	return strings.HasSuffix(host, "."+domain)
+}
+
+func pathMatch(reqPath, cookiePath string) bool {
+	if reqPath == cookiePath {
+		return true
+	}
+	if strings.HasPrefix(reqPath, cookiePath) {
+		return strings.HasSuffix(cookiePath, "/") || reqPath[len(cookiePath)] == '/'
+	}
+	return false
+}
+
+func requestPath(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+// see RFC 6265, 5.1.4
+func defaultPath(u *url.URL) string {
+	p := u.Path
+	if p == "" || p[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(p, "/")
+	if i == 0 {
+		return "/"
+	}
+	return p[:i]
+}
@@ -0,0 +1,130 @@
+package cookies
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJar(t *testing.T) {
+	assert := assert.New(t)
+
+	j := NewJar()
+	u := parse("https://www.example.com/news/article.html")
+	j.SetCookies(u, []*http.Cookie{
+		&http.Cookie{Name: "session", Value: "abc"},
+		&http.Cookie{Name: "domain", Value: "def", Domain: ".example.com", Path: "/"},
+		&http.Cookie{Name: "secure", Value: "ghi", Secure: true},
+		&http.Cookie{Name: "expired", Value: "jkl", Expires: time.Now().Add(-time.Hour)},
+		&http.Cookie{Name: "other", Value: "mno", Domain: "other.com"},
+		&http.Cookie{Name: "suffix", Value: "pqr", Domain: "com"},
+	})
+
+	// host-only cookies with default path, longer paths first
+	assert.Equal([]string{"secure=ghi", "session=abc", "domain=def"}, names(j.Cookies(parse("https://www.example.com/news/other.html"))))
+	// secure cookies require https
+	assert.Equal([]string{"session=abc", "domain=def"}, names(j.Cookies(parse("http://www.example.com/news/other.html"))))
+	// path does not match
+	assert.Equal([]string{"domain=def"}, names(j.Cookies(parse("https://www.example.com/sports"))))
+	// subdomain
+	assert.Equal([]string{"domain=def"}, names(j.Cookies(parse("https://images.example.com/news/"))))
+	assert.Equal([]string{}, names(j.Cookies(parse("https://other.com/"))))
+
+	// delete
+	j.SetCookies(u, []*http.Cookie{
+		&http.Cookie{Name: "domain", Domain: ".example.com", Path: "/", MaxAge: -1},
+	})
+	assert.Equal([]string{}, names(j.Cookies(parse("https://images.example.com/news/"))))
+}
+
+func TestSaveAndOpen(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	j, err := Open(path)
+	assert.Nil(err)
+
+	// same path, same jar
+	other, err := Open(path)
+	assert.Nil(err)
+	assert.True(j == other)
+
+	u := parse("https://example.com/")
+	j.SetCookies(u, []*http.Cookie{
+		&http.Cookie{Name: "session", Value: "abc"},
+		&http.Cookie{Name: "persistent", Value: "def", MaxAge: 3600},
+	})
+	assert.Nil(j.Save())
+
+	// load into a new jar
+	loaded := NewJar()
+	loaded.path = path
+	assert.Nil(loaded.load())
+	assert.Equal([]string{"persistent=def", "session=abc"}, names(loaded.Cookies(u)))
+}
+
+func TestSaveConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cookies.json")
+	j, err := Open(path)
+	assert.Nil(err)
+
+	u := parse("https://example.com/")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			j.SetCookies(u, []*http.Cookie{
+				&http.Cookie{Name: fmt.Sprintf("c%d", i), Value: "abc", MaxAge: 3600},
+			})
+			assert.Nil(j.Save())
+		}(i)
+	}
+	wg.Wait()
+
+	loaded := NewJar()
+	loaded.path = path
+	assert.Nil(loaded.load())
+	assert.Equal(10, len(loaded.Cookies(u)))
+
+	// no temporary files are left
+	files, err := os.ReadDir(dir)
+	assert.Nil(err)
+	assert.Equal(1, len(files))
+}
+
+func TestPathMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(pathMatch("/", "/"))
+	assert.True(pathMatch("/foo/bar", "/foo"))
+	assert.True(pathMatch("/foo/bar", "/foo/"))
+	assert.False(pathMatch("/foobar", "/foo"))
+	assert.False(pathMatch("/", "/foo"))
+}
+
+func names(cookies []*http.Cookie) []string {
+	result := make([]string, len(cookies))
+	for i, c := range cookies {
+		result[i] = c.Name + "=" + c.Value
+	}
+	return result
+}
+
+func parse(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
@@ -0,0 +1,234 @@
+// Package feed parses RSS, Atom and JSON feeds.
+//
+// Supported formats are RSS 0.9x and 2.0, RSS 1.0 (RDF), Atom 1.0 and
+// JSON Feed 1.0 and 1.1. The parser is lenient, broken XML is read as far
+// as possible.
+package feed
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Feed formats
+const (
+	FormatRSS  = "rss"
+	FormatRDF  = "rdf"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+)
+
+// ErrUnknownFormat is returned if the document is not a feed.
+var ErrUnknownFormat = errors.New("unknown feed format")
+
+// Feed is a parsed feed.
+type Feed struct {
+	// Format is one of "rss", "rdf", "atom" or "json".
+	Format  string
+	Version string
+	Title   string
+	// Description is the subtitle or description, as plain text.
+	Description string
+	// Link is the URL of the web site.
+	Link string
+	// FeedURL is the URL of the feed itself.
+	FeedURL    string
+	Language   string
+	Image      string
+	Authors    []Person
+	Categories []string
+	Updated    *time.Time
+	Entries    []Entry
+}
+
+// Entry is an item from a feed.
+type Entry struct {
+	// ID is the unique identifier, the link if the entry has none.
+	ID    string
+	Title string
+	Link  string
+	// Summary is a description of the entry as HTML.
+	Summary string
+	// Content is the full content as HTML.
+	Content    string
+	Authors    []Person
+	Categories []string
+	Published  *time.Time
+	Updated    *time.Time
+	Image      string
+	Enclosures []Enclosure
+}
+
+// Person is the author of a feed or entry.
+type Person struct {
+	Name  string
+	Email string
+	URL   string
+}
+
+// Enclosure is a media file attached to an entry.
+type Enclosure struct {
+	URL  string
+	Type string
+	// Length is the size in bytes, zero if it is not known.
+	Length int64
+	Title  string
+	// Duration is zero if it is not known.
+	Duration time.Duration
+	// Image is the URL of the episode image, e.g. from itunes:image.
+	Image string
+}
+
+// Parse reads a feed in any of the supported formats.
+// Relative URLs are resolved against the URL of the feed.
+func Parse(r io.Reader, feedURL string) (*Feed, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, ErrUnknownFormat
+	}
+
+	var f *Feed
+	if trimmed[0] == '{' {
+		f, err = parseJSON(trimmed)
+	} else {
+		f, err = parseXML(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f.resolveURLs(feedURL)
+	return f, nil
+}
+
+// resolveURLs makes all URLs absolute. Links are resolved against the URL
+// of the feed, the URL of the feed is used if it is known.
+func (f *Feed) resolveURLs(feedURL string) {
+	base := feedURL
+	if base == "" {
+		base = f.FeedURL
+	}
+
+	f.FeedURL = resolve(base, f.FeedURL)
+	f.Link = resolve(base, f.Link)
+	f.Image = resolve(base, f.Image)
+	for i := range f.Entries {
+		e := &f.Entries[i]
+		e.Link = resolve(base, e.Link)
+		e.Image = resolve(base, e.Image)
+		for j := range e.Enclosures {
+			e.Enclosures[j].URL = resolve(base, e.Enclosures[j].URL)
+			e.Enclosures[j].Image = resolve(base, e.Enclosures[j].Image)
+		}
+		if e.ID == "" {
+			e.ID = e.Link
+		}
+	}
+}
+
+func resolve(base, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || base == "" {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// plainText removes markup and entities from titles and descriptions.
+func plainText(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.ContainsAny(s, "<&") {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(s))
+		if err == nil {
+			s = doc.Text()
+		}
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// feeds use many variants of RFC 822 dates, the weekday is removed before
+// parsing
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04:05 MST",
+	"2-Jan-06 15:04:05 MST",
+	"Jan 2 15:04:05 2006",
+	"2 Jan 2006",
+}
+
+// zone abbreviations from RFC 822
+var zones = map[string]string{
+	"UT":  "+0000",
+	"Z":   "+0000",
+	"GMT": "+0000",
+	"UTC": "+0000",
+	"EST": "-0500",
+	"EDT": "-0400",
+	"CST": "-0600",
+	"CDT": "-0500",
+	"MST": "-0700",
+	"MDT": "-0600",
+	"PST": "-0800",
+	"PDT": "-0700",
+}
+
+var weekday = regexp.MustCompile(`(?i)^(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s+`)
+
+// parseDate parses a date from a feed, the result is in UTC.
+// Returns nil if the date cannot be parsed.
+func parseDate(s string) *time.Time {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return nil
+	}
+	s = weekday.ReplaceAllString(s, "")
+	// time.Parse does not know the offset for zone abbreviations
+	if i := strings.LastIndex(s, " "); i > 0 {
+		if offset, ok := zones[s[i+1:]]; ok {
+			s = s[:i+1] + offset
+		}
+	}
+
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			utc := t.UTC()
+			return &utc
+		}
+	}
+	return nil
+}
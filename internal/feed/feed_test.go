@@ -0,0 +1,360 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRSS(t *testing.T) {
+	assert := assert.New(t)
+
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"
+    xmlns:content="http://purl.org/rss/1.0/modules/content/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+    <title>Example &amp; Co</title>
+    <link>https://example.com/</link>
+    <description>News from &lt;b&gt;Example&lt;/b&gt;</description>
+    <language>en-us</language>
+    <lastBuildDate>Tue, 10 Aug 2021 14:30:00 GMT</lastBuildDate>
+    <image><url>/logo.png</url></image>
+    <item>
+        <title>First Post</title>
+        <link>/2021/first-post</link>
+        <description><![CDATA[<p>The summary</p>]]></description>
+        <content:encoded><![CDATA[<p>The full content</p>]]></content:encoded>
+        <dc:creator>Jane Doe</dc:creator>
+        <category>News</category>
+        <category>Tech</category>
+        <pubDate>Mon, 9 Aug 2021 08:00:00 +0200</pubDate>
+        <guid isPermaLink="false">post-1</guid>
+        <media:content url="https://cdn.example.com/first.jpg" medium="image"/>
+        <enclosure url="https://cdn.example.com/first.mp3" type="audio/mpeg" length="12345"/>
+    </item>
+    <item>
+        <title>Second Post</title>
+        <link>https://example.com/2021/second-post</link>
+        <author>john@example.com (John Doe)</author>
+    </item>
+</channel>
+</rss>`
+
+	f, err := Parse(strings.NewReader(data), "https://example.com/feed.xml")
+	assert.Nil(err)
+	assert.Equal(FormatRSS, f.Format)
+	assert.Equal("2.0", f.Version)
+	assert.Equal("Example & Co", f.Title)
+	assert.Equal("News from Example", f.Description)
+	assert.Equal("https://example.com/", f.Link)
+	assert.Equal("https://example.com/logo.png", f.Image)
+	assert.Equal("en-us", f.Language)
+	assert.Equal(time.Date(2021, 8, 10, 14, 30, 0, 0, time.UTC), *f.Updated)
+	assert.Equal(2, len(f.Entries))
+
+	e := f.Entries[0]
+	assert.Equal("post-1", e.ID)
+	assert.Equal("First Post", e.Title)
+	assert.Equal("https://example.com/2021/first-post", e.Link)
+	assert.Equal("<p>The summary</p>", e.Summary)
+	assert.Equal("<p>The full content</p>", e.Content)
+	assert.Equal([]Person{{Name: "Jane Doe"}}, e.Authors)
+	assert.Equal([]string{"News", "Tech"}, e.Categories)
+	assert.Equal(time.Date(2021, 8, 9, 6, 0, 0, 0, time.UTC), *e.Published)
+	assert.Equal("https://cdn.example.com/first.jpg", e.Image)
+	assert.Equal(1, len(e.Enclosures))
+	assert.Equal(Enclosure{
+		URL:    "https://cdn.example.com/first.mp3",
+		Type:   "audio/mpeg",
+		Length: 12345,
+	}, e.Enclosures[0])
+
+	e = f.Entries[1]
+	assert.Equal("https://example.com/2021/second-post", e.ID)
+	assert.Equal([]Person{{Name: "John Doe", Email: "john@example.com"}}, e.Authors)
+	assert.Nil(e.Published)
+}
+
+func TestParsePodcast(t *testing.T) {
+	assert := assert.New(t)
+
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"
+    xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+    <title>The Podcast</title>
+    <itunes:author>Jane Doe</itunes:author>
+    <itunes:image href="https://example.com/cover.jpg"/>
+    <item>
+        <title>Episode 1</title>
+        <itunes:summary>About the episode</itunes:summary>
+        <itunes:duration>1:02:03</itunes:duration>
+        <itunes:image href="/episode1.jpg"/>
+        <enclosure url="/episode1.mp3" type="audio/mpeg" length="5650889"/>
+    </item>
+    <item>
+        <title>Episode 2</title>
+        <itunes:duration>754</itunes:duration>
+        <media:content url="https://example.com/episode2.mp4" type="video/mp4" duration="755">
+            <media:thumbnail url="https://example.com/episode2.jpg"/>
+        </media:content>
+    </item>
+</channel>
+</rss>`
+
+	f, err := Parse(strings.NewReader(data), "https://example.com/podcast.xml")
+	assert.Nil(err)
+	assert.Equal("https://example.com/cover.jpg", f.Image)
+	assert.Equal([]Person{{Name: "Jane Doe"}}, f.Authors)
+	assert.Equal(2, len(f.Entries))
+
+	e := f.Entries[0]
+	assert.Equal("About the episode", e.Summary)
+	assert.Equal("https://example.com/episode1.jpg", e.Image)
+	assert.Equal([]Enclosure{{
+		URL:      "https://example.com/episode1.mp3",
+		Type:     "audio/mpeg",
+		Length:   5650889,
+		Duration: time.Hour + 2*time.Minute + 3*time.Second,
+		Image:    "https://example.com/episode1.jpg",
+	}}, e.Enclosures)
+
+	e = f.Entries[1]
+	assert.Equal([]Enclosure{{
+		URL:      "https://example.com/episode2.mp4",
+		Type:     "video/mp4",
+		Duration: 755 * time.Second,
+		Image:    "https://example.com/episode2.jpg",
+	}}, e.Enclosures)
+}
+
+func TestParseRDF(t *testing.T) {
+	assert := assert.New(t)
+
+	data := `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+    xmlns="http://purl.org/rss/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <channel rdf:about="https://example.com/">
+        <title>Example</title>
+        <link>https://example.com/</link>
+        <description>The description</description>
+        <dc:date>2021-08-10T14:30:00Z</dc:date>
+    </channel>
+    <item rdf:about="https://example.com/one">
+        <title>One</title>
+        <link>https://example.com/one</link>
+        <dc:creator>Jane Doe</dc:creator>
+        <dc:date>2021-08-09T08:00:00+02:00</dc:date>
+    </item>
+    <item rdf:about="https://example.com/two">
+        <title>Two</title>
+        <link>https://example.com/two</link>
+    </item>
+</rdf:RDF>`
+
+	f, err := Parse(strings.NewReader(data), "")
+	assert.Nil(err)
+	assert.Equal(FormatRDF, f.Format)
+	assert.Equal("Example", f.Title)
+	assert.Equal(time.Date(2021, 8, 10, 14, 30, 0, 0, time.UTC), *f.Updated)
+	assert.Equal(2, len(f.Entries))
+	assert.Equal("One", f.Entries[0].Title)
+	assert.Equal("https://example.com/one", f.Entries[0].ID)
+	assert.Equal([]Person{{Name: "Jane Doe"}}, f.Entries[0].Authors)
+	assert.Equal(time.Date(2021, 8, 9, 6, 0, 0, 0, time.UTC), *f.Entries[0].Published)
+}
+
+func TestParseAtom(t *testing.T) {
+	assert := assert.New(t)
+
+	data := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="de">
+    <title type="html">Example &lt;i&gt;Blog&lt;/i&gt;</title>
+    <subtitle>The subtitle</subtitle>
+    <link rel="alternate" href="https://example.com/"/>
+    <link rel="self" href="https://example.com/atom.xml"/>
+    <updated>2021-08-10T14:30:00Z</updated>
+    <author><name>Jane Doe</name><email>jane@example.com</email></author>
+    <id>urn:uuid:60a76c80-d399-11d9-b93C-0003939e0af6</id>
+    <entry>
+        <title>Atom Entry</title>
+        <link href="/2021/atom-entry"/>
+        <link rel="enclosure" type="audio/mpeg" length="1337" href="/audio.mp3"/>
+        <id>tag:example.com,2021:1</id>
+        <published>2021-08-09T08:00:00+02:00</published>
+        <updated>2021-08-09T10:00:00+02:00</updated>
+        <summary>Plain summary</summary>
+        <content type="xhtml">
+            <div xmlns="http://www.w3.org/1999/xhtml"><p>The <b>content</b><br/></p></div>
+        </content>
+        <category term="news" label="News"/>
+    </entry>
+</feed>`
+
+	f, err := Parse(strings.NewReader(data), "https://example.com/atom.xml")
+	assert.Nil(err)
+	assert.Equal(FormatAtom, f.Format)
+	assert.Equal("1.0", f.Version)
+	assert.Equal("Example Blog", f.Title)
+	assert.Equal("The subtitle", f.Description)
+	assert.Equal("https://example.com/", f.Link)
+	assert.Equal("https://example.com/atom.xml", f.FeedURL)
+	assert.Equal("de", f.Language)
+	assert.Equal([]Person{{Name: "Jane Doe", Email: "jane@example.com"}}, f.Authors)
+	assert.Equal(1, len(f.Entries))
+
+	e := f.Entries[0]
+	assert.Equal("tag:example.com,2021:1", e.ID)
+	assert.Equal("https://example.com/2021/atom-entry", e.Link)
+	assert.Equal("Plain summary", e.Summary)
+	assert.Equal("<p>The <b>content</b><br></p>", e.Content)
+	assert.Equal([]string{"News"}, e.Categories)
+	assert.Equal(time.Date(2021, 8, 9, 6, 0, 0, 0, time.UTC), *e.Published)
+	assert.Equal(time.Date(2021, 8, 9, 8, 0, 0, 0, time.UTC), *e.Updated)
+	assert.Equal(1, len(e.Enclosures))
+	assert.Equal("https://example.com/audio.mp3", e.Enclosures[0].URL)
+	assert.Equal(int64(1337), e.Enclosures[0].Length)
+}
+
+func TestParseJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	data := `{
+        "version": "https://jsonfeed.org/version/1.1",
+        "title": "JSON Example",
+        "home_page_url": "https://example.com/",
+        "feed_url": "https://example.com/feed.json",
+        "authors": [{"name": "Jane Doe", "url": "https://example.com/jane"}],
+        "items": [
+            {
+                "id": "1",
+                "url": "/2021/json-entry",
+                "title": "JSON Entry",
+                "content_html": "<p>The content</p>",
+                "date_published": "2021-08-09T08:00:00+02:00",
+                "tags": ["news"],
+                "attachments": [
+                    {"url": "/episode.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 4096}
+                ]
+            },
+            {
+                "id": 2,
+                "url": "https://example.com/2021/text",
+                "content_text": "First paragraph.\n\nSecond <paragraph>.",
+                "author": {"name": "John Doe"}
+            }
+        ]
+    }`
+
+	f, err := Parse(strings.NewReader(data), "")
+	assert.Nil(err)
+	assert.Equal(FormatJSON, f.Format)
+	assert.Equal("1.1", f.Version)
+	assert.Equal("JSON Example", f.Title)
+	assert.Equal("https://example.com/", f.Link)
+	assert.Equal([]Person{{Name: "Jane Doe", URL: "https://example.com/jane"}}, f.Authors)
+	assert.Equal(2, len(f.Entries))
+
+	e := f.Entries[0]
+	assert.Equal("1", e.ID)
+	assert.Equal("https://example.com/2021/json-entry", e.Link)
+	assert.Equal("<p>The content</p>", e.Content)
+	assert.Equal([]string{"news"}, e.Categories)
+	assert.Equal(time.Date(2021, 8, 9, 6, 0, 0, 0, time.UTC), *e.Published)
+	assert.Equal([]Enclosure{{
+		URL:    "https://example.com/episode.mp3",
+		Type:   "audio/mpeg",
+		Length: 4096,
+	}}, e.Enclosures)
+
+	e = f.Entries[1]
+	assert.Equal("2", e.ID)
+	assert.Equal("<p>First paragraph.</p><p>Second &lt;paragraph&gt;.</p>", e.Content)
+	assert.Equal([]Person{{Name: "John Doe"}}, e.Authors)
+}
+
+func TestParseBroken(t *testing.T) {
+	assert := assert.New(t)
+
+	// unescaped ampersand, HTML entity, control character,
+	// unclosed elements and no closing tags at the end
+	data := "<rss version=\"2.0\"><channel>" +
+		"<title>Tom & Jerry&nbsp;News\x0b</title>" +
+		"<link>https://example.com/</link>" +
+		"<item><title>One</title><link>https://example.com/one</link>" +
+		"<description><p>Unescaped<br>HTML</description></item>" +
+		"<item><title>Two</title><link>https://example.com/two</link>"
+
+	f, err := Parse(strings.NewReader(data), "")
+	assert.Nil(err)
+	assert.Equal(FormatRSS, f.Format)
+	assert.Equal("Tom & Jerry News", f.Title)
+	assert.Equal("https://example.com/", f.Link)
+	assert.Equal(2, len(f.Entries))
+	if len(f.Entries) == 2 {
+		assert.Equal("https://example.com/one", f.Entries[0].Link)
+		assert.Equal("Two", f.Entries[1].Title)
+	}
+
+	// undeclared namespace prefix
+	data = `<rss version="2.0"><channel><title>Example</title>
+        <item><title>One</title><dc:creator>Jane Doe</dc:creator></item>
+    </channel></rss>`
+
+	f, err = Parse(strings.NewReader(data), "")
+	assert.Nil(err)
+	assert.Equal([]Person{{Name: "Jane Doe"}}, f.Entries[0].Authors)
+}
+
+func TestParseUnknown(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []string{
+		"",
+		"<html><body><p>Not a feed</p></body></html>",
+		`{"name": "not a feed"}`,
+		"plain text",
+	}
+
+	for _, data := range tests {
+		_, err := Parse(strings.NewReader(data), "")
+		assert.NotNil(err, data)
+	}
+
+	_, err := Parse(strings.NewReader(tests[1]), "")
+	assert.Equal(ErrUnknownFormat, err)
+}
+
+func TestParseDate(t *testing.T) {
+	assert := assert.New(t)
+
+	expected := time.Date(2021, 8, 10, 14, 30, 0, 0, time.UTC)
+	tests := []string{
+		"Tue, 10 Aug 2021 14:30:00 GMT",
+		"Tue, 10 Aug 2021 14:30:00 +0000",
+		"Tuesday, 10 Aug 2021 10:30:00 EDT",
+		"10 Aug 2021 16:30:00 +0200",
+		"Tue, 10 Aug 21 14:30:00 UT",
+		"10 August 2021 14:30:00 GMT",
+		"2021-08-10T14:30:00Z",
+		"2021-08-10T16:30:00+02:00",
+		"2021-08-10T14:30:00.000Z",
+		"2021-08-10 14:30:00",
+		"  Tue,  10 Aug 2021\n14:30:00 GMT ",
+	}
+
+	for _, s := range tests {
+		d := parseDate(s)
+		if assert.NotNil(d, s) {
+			assert.Equal(expected, *d, s)
+		}
+	}
+
+	assert.Nil(parseDate(""))
+	assert.Nil(parseDate("yesterday"))
+}
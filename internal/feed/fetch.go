@@ -0,0 +1,39 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxSize limits the size of a feed that is downloaded.
+const maxSize = 8 << 20
+
+// Fetch downloads and parses the feed with the given URL.
+func Fetch(ctx context.Context, client *http.Client, feedURL string) (*Feed, error) {
+	log.WithFields(log.Fields{
+		"module": "feed",
+		"url":    feedURL,
+	}).Info("Fetch feed")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, */*;q=0.8")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got HTTP status %v", res.StatusCode)
+	}
+
+	return Parse(io.LimitReader(res.Body, maxSize), res.Request.URL.String())
+}
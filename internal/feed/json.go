@@ -0,0 +1,162 @@
+package feed
+
+import (
+	"encoding/json"
+	"html"
+	"strings"
+	"time"
+)
+
+// jsonFeed is a JSON Feed, version 1.1 with the author from version 1.0.
+// The same types are used to read and to write feeds.
+// See: https://www.jsonfeed.org/version/1.1/
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageURL string       `json:"home_page_url,omitempty"`
+	FeedURL     string       `json:"feed_url,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Icon        string       `json:"icon,omitempty"`
+	Favicon     string       `json:"favicon,omitempty"`
+	Language    string       `json:"language,omitempty"`
+	Author      *jsonAuthor  `json:"author,omitempty"`
+	Authors     []jsonAuthor `json:"authors,omitempty"`
+	Items       []jsonItem   `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name   string `json:"name,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+type jsonItem struct {
+	ID            json.RawMessage  `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	ExternalURL   string           `json:"external_url,omitempty"`
+	Title         string           `json:"title,omitempty"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	BannerImage   string           `json:"banner_image,omitempty"`
+	DatePublished string           `json:"date_published,omitempty"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Author        *jsonAuthor      `json:"author,omitempty"`
+	Authors       []jsonAuthor     `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Attachments   []jsonAttachment `json:"attachments,omitempty"`
+}
+
+type jsonAttachment struct {
+	URL       string  `json:"url"`
+	MimeType  string  `json:"mime_type"`
+	Title     string  `json:"title,omitempty"`
+	Size      float64 `json:"size_in_bytes,omitempty"`
+	DurationS float64 `json:"duration_in_seconds,omitempty"`
+}
+
+func parseJSON(data []byte) (*Feed, error) {
+	var jf jsonFeed
+	err := json.Unmarshal(data, &jf)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(jf.Version, "jsonfeed.org") && jf.Items == nil {
+		return nil, ErrUnknownFormat
+	}
+
+	f := &Feed{
+		Format:      FormatJSON,
+		Version:     jsonVersion(jf.Version),
+		Title:       plainText(jf.Title),
+		Description: plainText(jf.Description),
+		Link:        jf.HomePageURL,
+		FeedURL:     jf.FeedURL,
+		Language:    jf.Language,
+		Image:       jf.Icon,
+		Authors:     jsonAuthors(jf.Author, jf.Authors),
+	}
+	if f.Image == "" {
+		f.Image = jf.Favicon
+	}
+
+	for _, item := range jf.Items {
+		e := Entry{
+			ID:         jsonID(item.ID),
+			Title:      plainText(item.Title),
+			Link:       item.URL,
+			Summary:    html.EscapeString(strings.TrimSpace(item.Summary)),
+			Content:    item.ContentHTML,
+			Authors:    jsonAuthors(item.Author, item.Authors),
+			Categories: item.Tags,
+			Published:  parseDate(item.DatePublished),
+			Updated:    parseDate(item.DateModified),
+			Image:      item.Image,
+		}
+		if e.Link == "" {
+			e.Link = item.ExternalURL
+		}
+		if e.Content == "" && item.ContentText != "" {
+			e.Content = textToHTML(item.ContentText)
+		}
+		if e.Image == "" {
+			e.Image = item.BannerImage
+		}
+		for _, a := range item.Attachments {
+			e.Enclosures = append(e.Enclosures, Enclosure{
+				URL:      a.URL,
+				Type:     a.MimeType,
+				Length:   int64(a.Size),
+				Title:    a.Title,
+				Duration: time.Duration(a.DurationS * float64(time.Second)),
+			})
+		}
+		f.Entries = append(f.Entries, e)
+	}
+
+	return f, nil
+}
+
+// jsonVersion returns "1.1" for "https://jsonfeed.org/version/1.1".
+func jsonVersion(s string) string {
+	i := strings.LastIndex(s, "/")
+	return s[i+1:]
+}
+
+// jsonID reads the id of an item, which should be a string but is a number
+// in some feeds.
+func jsonID(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func jsonAuthors(author *jsonAuthor, authors []jsonAuthor) []Person {
+	if len(authors) == 0 && author != nil {
+		authors = []jsonAuthor{*author}
+	}
+
+	var persons []Person
+	for _, a := range authors {
+		if a.Name == "" && a.URL == "" {
+			continue
+		}
+		persons = append(persons, Person{Name: a.Name, URL: a.URL})
+	}
+	return persons
+}
+
+// textToHTML turns plain text into paragraphs.
+func textToHTML(s string) string {
+	var b strings.Builder
+	for _, p := range strings.Split(strings.TrimSpace(s), "\n\n") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			b.WriteString("<p>" + html.EscapeString(p) + "</p>")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// retainFor is how long an entry is remembered after it was last seen in
+// its feed.
+const retainFor = 30 * 24 * time.Hour
+
+// State remembers the entries of feeds which were already processed.
+// It is saved as JSON, with the entry IDs for each feed URL and the time
+// each entry was last seen in the feed.
+type State struct {
+	path string
+	seen map[string]map[string]time.Time
+	mx   sync.Mutex
+}
+
+// OpenState loads the state from the given file.
+//
+// The state is empty if the file does not exist. If the path is empty,
+// the state is kept in memory only.
+func OpenState(path string) (*State, error) {
+	s := &State{
+		path: path,
+		seen: make(map[string]map[string]time.Time),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &s.seen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed state %q: %v", path, err)
+	}
+	return s, nil
+}
+
+// Seen tells whether the entry with the given ID was processed.
+func (s *State) Seen(feedURL, id string) bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	_, ok := s.seen[feedURL][id]
+	return ok
+}
+
+// Add marks the entry with the given ID as processed.
+func (s *State) Add(feedURL, id string) {
+	if id == "" {
+		return
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.seen[feedURL] == nil {
+		s.seen[feedURL] = make(map[string]time.Time)
+	}
+	s.seen[feedURL][id] = time.Now().UTC()
+}
+
+// Retain updates the entries of a feed which are in the given list and
+// forgets the others if they were not seen for some time,
+// so that the state does not grow with each new entry.
+//
+// Entries are kept for a while after they drop out of the feed, in case
+// the feed could only be read in part or the entry comes back.
+func (s *State) Retain(feedURL string, ids []string) {
+	now := time.Now().UTC()
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	seen := s.seen[feedURL]
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			seen[id] = now
+		}
+	}
+	for id, last := range seen {
+		if now.Sub(last) > retainFor {
+			delete(seen, id)
+		}
+	}
+}
+
+// Save writes the state to its file.
+func (s *State) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	// map keys are sorted by encoding/json
+	s.mx.Lock()
+	data, err := json.MarshalIndent(s.seen, "", "  ")
+	s.mx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that we do not end up with
+	// a half-written state
+	tmp := s.path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,61 @@
+package feed
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	feedURL := "https://example.com/feed.xml"
+
+	s, err := OpenState(path)
+	assert.Nil(err)
+	assert.False(s.Seen(feedURL, "1"))
+
+	s.Add(feedURL, "1")
+	s.Add(feedURL, "2")
+	s.Add("https://example.com/other.xml", "1")
+	assert.True(s.Seen(feedURL, "1"))
+	assert.False(s.Seen(feedURL, "3"))
+	assert.Nil(s.Save())
+
+	s, err = OpenState(path)
+	assert.Nil(err)
+	assert.True(s.Seen(feedURL, "1"))
+	assert.True(s.Seen(feedURL, "2"))
+	assert.True(s.Seen("https://example.com/other.xml", "1"))
+
+	// entries which are no longer in the feed are kept for a while
+	s.Retain(feedURL, []string{"2", "3"})
+	assert.True(s.Seen(feedURL, "1"))
+	assert.True(s.Seen(feedURL, "2"))
+
+	// and forgotten if they were not seen for some time
+	old := time.Now().Add(-retainFor - time.Hour)
+	s.seen[feedURL]["1"] = old
+	s.seen[feedURL]["2"] = old
+	s.Retain(feedURL, []string{"2", "3"})
+	assert.False(s.Seen(feedURL, "1"))
+	assert.True(s.Seen(feedURL, "2"))
+	assert.True(s.Seen("https://example.com/other.xml", "1"))
+
+	// also when the feed gets shorter
+	s.Add(feedURL, "3")
+	s.seen[feedURL]["2"] = old
+	s.Retain(feedURL, []string{"3"})
+	assert.False(s.Seen(feedURL, "2"))
+	assert.True(s.Seen(feedURL, "3"))
+
+	// in memory
+	s, err = OpenState("")
+	assert.Nil(err)
+	s.Add(feedURL, "1")
+	assert.True(s.Seen(feedURL, "1"))
+	assert.Nil(s.Save())
+}
@@ -0,0 +1,404 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// Write writes the feed in the given format, one of "atom", "rss" or "json".
+// Summaries and content are HTML.
+func Write(w io.Writer, f *Feed, format string) error {
+	switch format {
+	case FormatAtom:
+		return writeXML(w, atomFeed(f))
+	case FormatRSS:
+		return writeXML(w, rssFeed(f))
+	case FormatJSON:
+		return writeJSON(w, f)
+	}
+	return fmt.Errorf("unsupported feed format %q", format)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	err = e.Encode(v)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// updated returns the date of the latest entry, or now if no entry has a
+// date. Atom requires a date for the feed and each entry.
+func (f *Feed) updated() time.Time {
+	if f.Updated != nil {
+		return *f.Updated
+	}
+	var latest time.Time
+	for _, e := range f.Entries {
+		if d := e.date(); d != nil && d.After(latest) {
+			latest = *d
+		}
+	}
+	if latest.IsZero() {
+		return time.Now().UTC()
+	}
+	return latest
+}
+
+func (e *Entry) date() *time.Time {
+	if e.Updated != nil {
+		return e.Updated
+	}
+	return e.Published
+}
+
+type xmlText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+type xmlLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Length int64  `xml:"length,attr,omitempty"`
+	Title  string `xml:"title,attr,omitempty"`
+}
+
+type xmlPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+	URI   string `xml:"uri,omitempty"`
+}
+
+type xmlCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type xmlThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+type xmlMedia struct {
+	URL      string        `xml:"url,attr"`
+	Type     string        `xml:"type,attr,omitempty"`
+	Medium   string        `xml:"medium,attr,omitempty"`
+	FileSize int64         `xml:"fileSize,attr,omitempty"`
+	Duration int64         `xml:"duration,attr,omitempty"`
+	Title    string        `xml:"media:title,omitempty"`
+	Thumb    *xmlThumbnail `xml:"media:thumbnail"`
+}
+
+type xmlAtomFeed struct {
+	XMLName  xml.Name       `xml:"feed"`
+	NS       string         `xml:"xmlns,attr"`
+	NSMedia  string         `xml:"xmlns:media,attr"`
+	ID       string         `xml:"id"`
+	Title    string         `xml:"title"`
+	Subtitle string         `xml:"subtitle,omitempty"`
+	Links    []xmlLink      `xml:"link"`
+	Updated  string         `xml:"updated"`
+	Authors  []xmlPerson    `xml:"author"`
+	Logo     string         `xml:"logo,omitempty"`
+	Entries  []xmlAtomEntry `xml:"entry"`
+}
+
+type xmlAtomEntry struct {
+	ID         string        `xml:"id"`
+	Title      string        `xml:"title"`
+	Links      []xmlLink     `xml:"link"`
+	Published  string        `xml:"published,omitempty"`
+	Updated    string        `xml:"updated"`
+	Authors    []xmlPerson   `xml:"author"`
+	Categories []xmlCategory `xml:"category"`
+	Summary    *xmlText      `xml:"summary"`
+	Content    *xmlText      `xml:"content"`
+	Thumbnail  *xmlThumbnail `xml:"media:thumbnail"`
+}
+
+// id is the URL of the feed or the web site. Without either, the ID is a
+// URN derived from the title, so that it is the same each time the feed is
+// written.
+func (f *Feed) id() string {
+	id := pipeline.FirstOf(f.FeedURL, f.Link)
+	if id == "" {
+		id = "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte(f.Title)).String()
+	}
+	return id
+}
+
+func atomFeed(f *Feed) *xmlAtomFeed {
+	x := &xmlAtomFeed{
+		NS:       nsAtom,
+		NSMedia:  nsMedia,
+		ID:       f.id(),
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.updated().Format(time.RFC3339),
+		Authors:  xmlPersons(f.Authors),
+		Logo:     f.Image,
+	}
+	if f.Link != "" {
+		x.Links = append(x.Links, xmlLink{Rel: "alternate", Type: "text/html", Href: f.Link})
+	}
+	if f.FeedURL != "" {
+		x.Links = append(x.Links, xmlLink{Rel: "self", Type: "application/atom+xml", Href: f.FeedURL})
+	}
+
+	for _, e := range f.Entries {
+		xe := xmlAtomEntry{
+			ID:      pipeline.FirstOf(e.ID, e.Link),
+			Title:   e.Title,
+			Updated: f.updated().Format(time.RFC3339),
+			Authors: xmlPersons(e.Authors),
+		}
+		if d := e.date(); d != nil {
+			xe.Updated = d.Format(time.RFC3339)
+		}
+		if e.Published != nil {
+			xe.Published = e.Published.Format(time.RFC3339)
+		}
+		if e.Link != "" {
+			xe.Links = append(xe.Links, xmlLink{Rel: "alternate", Type: "text/html", Href: e.Link})
+		}
+		for _, enc := range e.Enclosures {
+			xe.Links = append(xe.Links, xmlLink{
+				Rel:    "enclosure",
+				Type:   enc.Type,
+				Href:   enc.URL,
+				Length: enc.Length,
+				Title:  enc.Title,
+			})
+		}
+		for _, c := range e.Categories {
+			xe.Categories = append(xe.Categories, xmlCategory{Term: c})
+		}
+		if e.Summary != "" {
+			xe.Summary = &xmlText{Type: "html", Text: e.Summary}
+		}
+		if e.Content != "" {
+			xe.Content = &xmlText{Type: "html", Text: e.Content}
+		}
+		if e.Image != "" {
+			xe.Thumbnail = &xmlThumbnail{URL: e.Image}
+		}
+		x.Entries = append(x.Entries, xe)
+	}
+	return x
+}
+
+func xmlPersons(persons []Person) []xmlPerson {
+	var xs []xmlPerson
+	for _, p := range persons {
+		if p.Name == "" {
+			continue
+		}
+		xs = append(xs, xmlPerson{Name: p.Name, Email: p.Email, URI: p.URL})
+	}
+	return xs
+}
+
+type xmlCDATA struct {
+	Text string `xml:",cdata"`
+}
+
+type xmlRSS struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	NSContent string     `xml:"xmlns:content,attr"`
+	NSDC      string     `xml:"xmlns:dc,attr"`
+	NSAtom    string     `xml:"xmlns:atom,attr"`
+	NSMedia   string     `xml:"xmlns:media,attr"`
+	Channel   xmlChannel `xml:"channel"`
+}
+
+type xmlChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          *xmlLink  `xml:"atom:link"`
+	Image         *xmlImage `xml:"image"`
+	Items         []xmlItem `xml:"item"`
+}
+
+type xmlImage struct {
+	URL   string `xml:"url"`
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+type xmlGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Text        string `xml:",chardata"`
+}
+
+type xmlEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type xmlItem struct {
+	Title       string        `xml:"title,omitempty"`
+	Link        string        `xml:"link,omitempty"`
+	GUID        *xmlGUID      `xml:"guid"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Creators    []string      `xml:"dc:creator"`
+	Categories  []string      `xml:"category"`
+	Description *xmlCDATA     `xml:"description"`
+	Content     *xmlCDATA     `xml:"content:encoded"`
+	Enclosure   *xmlEnclosure `xml:"enclosure"`
+	Media       []xmlMedia    `xml:"media:content"`
+}
+
+func rssFeed(f *Feed) *xmlRSS {
+	ch := xmlChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		Language:      f.Language,
+		LastBuildDate: f.updated().Format(time.RFC1123Z),
+	}
+	if f.FeedURL != "" {
+		ch.Self = &xmlLink{Rel: "self", Type: "application/rss+xml", Href: f.FeedURL}
+	}
+	if f.Image != "" {
+		ch.Image = &xmlImage{URL: f.Image, Title: f.Title, Link: f.Link}
+	}
+
+	for _, e := range f.Entries {
+		item := xmlItem{
+			Title:      e.Title,
+			Link:       e.Link,
+			Categories: e.Categories,
+		}
+		if id := pipeline.FirstOf(e.ID, e.Link); id != "" {
+			item.GUID = &xmlGUID{IsPermaLink: strconv.FormatBool(id == e.Link), Text: id}
+		}
+		if e.Published != nil {
+			item.PubDate = e.Published.Format(time.RFC1123Z)
+		}
+		for _, p := range e.Authors {
+			if p.Name != "" {
+				item.Creators = append(item.Creators, p.Name)
+			}
+		}
+		if e.Summary != "" {
+			item.Description = &xmlCDATA{Text: e.Summary}
+		}
+		if e.Content != "" {
+			item.Content = &xmlCDATA{Text: e.Content}
+		}
+
+		// RSS allows a single enclosure, Media RSS has the details
+		for i, enc := range e.Enclosures {
+			if i == 0 {
+				item.Enclosure = &xmlEnclosure{URL: enc.URL, Length: enc.Length, Type: enc.Type}
+			}
+			m := xmlMedia{
+				URL:      enc.URL,
+				Type:     enc.Type,
+				FileSize: enc.Length,
+				Duration: int64(enc.Duration.Seconds()),
+				Title:    enc.Title,
+			}
+			if enc.Image != "" {
+				m.Thumb = &xmlThumbnail{URL: enc.Image}
+			}
+			item.Media = append(item.Media, m)
+		}
+		if e.Image != "" {
+			item.Media = append(item.Media, xmlMedia{URL: e.Image, Medium: "image"})
+		}
+
+		ch.Items = append(ch.Items, item)
+	}
+
+	return &xmlRSS{
+		Version:   "2.0",
+		NSContent: nsContent,
+		NSDC:      nsDC,
+		NSAtom:    nsAtom,
+		NSMedia:   nsMedia,
+		Channel:   ch,
+	}
+}
+
+func writeJSON(w io.Writer, f *Feed) error {
+	jf := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Icon:        f.Image,
+		Language:    f.Language,
+		Authors:     writeJSONAuthors(f.Authors),
+		Items:       make([]jsonItem, 0),
+	}
+
+	for _, e := range f.Entries {
+		id, err := json.Marshal(pipeline.FirstOf(e.ID, e.Link))
+		if err != nil {
+			return err
+		}
+		item := jsonItem{
+			ID:          id,
+			URL:         e.Link,
+			Title:       e.Title,
+			ContentHTML: e.Content,
+			Summary:     plainText(e.Summary),
+			Image:       e.Image,
+			Authors:     writeJSONAuthors(e.Authors),
+			Tags:        e.Categories,
+		}
+		if e.Published != nil {
+			item.DatePublished = e.Published.Format(time.RFC3339)
+		}
+		if e.Updated != nil {
+			item.DateModified = e.Updated.Format(time.RFC3339)
+		}
+		for _, enc := range e.Enclosures {
+			item.Attachments = append(item.Attachments, jsonAttachment{
+				URL:       enc.URL,
+				MimeType:  enc.Type,
+				Title:     enc.Title,
+				Size:      float64(enc.Length),
+				DurationS: enc.Duration.Seconds(),
+			})
+		}
+		jf.Items = append(jf.Items, item)
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	e.SetEscapeHTML(false)
+	return e.Encode(jf)
+}
+
+func writeJSONAuthors(persons []Person) []jsonAuthor {
+	var as []jsonAuthor
+	for _, p := range persons {
+		if p.Name != "" || p.URL != "" {
+			as = append(as, jsonAuthor{Name: p.Name, URL: p.URL})
+		}
+	}
+	return as
+}
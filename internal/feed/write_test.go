@@ -0,0 +1,130 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2021, 8, 9, 8, 0, 0, 0, time.UTC)
+	updated := time.Date(2021, 8, 10, 14, 30, 0, 0, time.UTC)
+	return &Feed{
+		Title:       "Reading List",
+		Description: "Articles we read",
+		Link:        "https://example.com/",
+		FeedURL:     "https://example.com/feed",
+		Language:    "en",
+		Entries: []Entry{
+			{
+				ID:         "https://example.com/one",
+				Title:      "One & Only",
+				Link:       "https://example.com/one",
+				Summary:    "The summary",
+				Content:    `<p>The <b>content</b> with ]]> in it</p>`,
+				Authors:    []Person{{Name: "Jane Doe", URL: "https://example.com/jane"}},
+				Categories: []string{"news", "tech"},
+				Published:  &published,
+				Updated:    &updated,
+				Image:      "https://example.com/one.jpg",
+				Enclosures: []Enclosure{{
+					URL:      "https://example.com/one.mp3",
+					Type:     "audio/mpeg",
+					Length:   12345,
+					Duration: 90 * time.Second,
+				}},
+			},
+			{
+				ID:    "https://example.com/two",
+				Title: "Two",
+				Link:  "https://example.com/two",
+			},
+		},
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatAtom, FormatRSS, FormatJSON} {
+		assert := assert.New(t)
+		f := testFeed()
+
+		var b strings.Builder
+		err := Write(&b, f, format)
+		assert.Nil(err, format)
+
+		parsed, err := Parse(strings.NewReader(b.String()), "")
+		if !assert.Nil(err, format) {
+			continue
+		}
+		assert.Equal(format, parsed.Format)
+		assert.Equal(f.Title, parsed.Title, format)
+		assert.Equal(f.Link, parsed.Link, format)
+		assert.Equal(f.FeedURL, parsed.FeedURL, format)
+		assert.Equal(2, len(parsed.Entries), format)
+
+		e, expected := parsed.Entries[0], f.Entries[0]
+		assert.Equal(expected.ID, e.ID, format)
+		assert.Equal(expected.Title, e.Title, format)
+		assert.Equal(expected.Link, e.Link, format)
+		assert.Equal(expected.Content, e.Content, format)
+		assert.Equal(expected.Summary, e.Summary, format)
+		assert.Equal(expected.Categories, e.Categories, format)
+		assert.Equal("Jane Doe", e.Authors[0].Name, format)
+		assert.Equal(*expected.Published, *e.Published, format)
+		assert.Equal(expected.Image, e.Image, format)
+		if assert.Equal(1, len(e.Enclosures), format) {
+			assert.Equal(expected.Enclosures[0].URL, e.Enclosures[0].URL, format)
+			assert.Equal(expected.Enclosures[0].Type, e.Enclosures[0].Type, format)
+			assert.Equal(expected.Enclosures[0].Length, e.Enclosures[0].Length, format)
+		}
+	}
+}
+
+func TestWriteUnknown(t *testing.T) {
+	assert := assert.New(t)
+
+	var b strings.Builder
+	err := Write(&b, testFeed(), "rdf")
+	assert.NotNil(err)
+}
+
+func TestWriteJSONSummary(t *testing.T) {
+	assert := assert.New(t)
+
+	// the summary is HTML, JSON Feed has plain text
+	f := &Feed{
+		Title: "Reading List",
+		Entries: []Entry{{
+			ID:      "https://example.com/one",
+			Summary: "Fish &amp; Chips at <b>Tom&#39;s</b>",
+		}},
+	}
+	var b strings.Builder
+	err := Write(&b, f, FormatJSON)
+	assert.Nil(err)
+	assert.Contains(b.String(), `"summary": "Fish & Chips at Tom's"`)
+
+	parsed, err := Parse(strings.NewReader(b.String()), "")
+	assert.Nil(err)
+	assert.Equal("Fish &amp; Chips at Tom&#39;s", parsed.Entries[0].Summary)
+}
+
+func TestWriteAtomID(t *testing.T) {
+	assert := assert.New(t)
+
+	updated := time.Date(2021, 8, 15, 12, 0, 0, 0, time.UTC)
+	f := &Feed{Title: "Feeds", Updated: &updated}
+	var b strings.Builder
+	err := Write(&b, f, FormatAtom)
+	assert.Nil(err)
+	assert.NotContains(b.String(), "<id></id>")
+	assert.Contains(b.String(), "<id>urn:uuid:")
+
+	// the same ID for the same feed
+	var b2 strings.Builder
+	err = Write(&b2, f, FormatAtom)
+	assert.Nil(err)
+	assert.Equal(b.String(), b2.String())
+}
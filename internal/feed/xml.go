@@ -0,0 +1,658 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// XML namespaces
+const (
+	nsAtom    = "http://www.w3.org/2005/Atom"
+	nsAtom03  = "http://purl.org/atom/ns#"
+	nsRDF     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsRSS090  = "http://my.netscape.com/rdf/simple/0.9/"
+	nsRSS10   = "http://purl.org/rss/1.0/"
+	nsContent = "http://purl.org/rss/1.0/modules/content/"
+	nsDC      = "http://purl.org/dc/elements/1.1/"
+	nsMedia   = "http://search.yahoo.com/mrss/"
+	nsITunes  = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+	nsXML     = "http://www.w3.org/XML/1998/namespace"
+)
+
+// Prefixes are used if a feed does not declare the namespace.
+var prefixes = map[string]string{
+	nsAtom:    "atom",
+	nsRDF:     "rdf",
+	nsContent: "content",
+	nsDC:      "dc",
+	nsMedia:   "media",
+	nsITunes:  "itunes",
+	nsXML:     "xml",
+}
+
+// node is an element or a text node in the XML tree.
+// Text nodes have no name.
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	data     string
+	children []*node
+}
+
+func (n *node) is(space, local string) bool {
+	if n.name.Local != local {
+		return false
+	}
+	return n.name.Space == space || n.name.Space == prefixes[space]
+}
+
+// in tells if the node is in any of the given namespaces.
+func (n *node) in(spaces ...string) bool {
+	for _, s := range spaces {
+		if n.name.Space == s {
+			return true
+		}
+	}
+	return false
+}
+
+// all returns the child elements with the given name.
+func (n *node) all(space, local string) []*node {
+	result := make([]*node, 0)
+	for _, c := range n.children {
+		if c.is(space, local) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// first returns the first child element with the given name, or nil.
+func (n *node) first(space, local string) *node {
+	for _, c := range n.children {
+		if c.is(space, local) {
+			return c
+		}
+	}
+	return nil
+}
+
+// value returns the text of the first child element with the given name.
+func (n *node) value(space, local string) string {
+	c := n.first(space, local)
+	if c == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.text())
+}
+
+func (n *node) attr(local string) string {
+	for _, a := range n.attrs {
+		if a.Name.Local == local {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
+
+// text returns the text of the node and its descendants.
+func (n *node) text() string {
+	if n.name.Local == "" {
+		return n.data
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(c.text())
+	}
+	return b.String()
+}
+
+// html returns the content as HTML. Escaped HTML is the text of the element,
+// unescaped HTML is parsed into child elements and rendered again.
+func (n *node) html() string {
+	if !hasElements(n) {
+		return strings.TrimSpace(n.text())
+	}
+
+	var b strings.Builder
+	for _, c := range n.children {
+		c.render(&b)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+var voidElements = map[string]bool{
+	"br":     true,
+	"hr":     true,
+	"img":    true,
+	"source": true,
+	"wbr":    true,
+}
+
+func (n *node) render(b *strings.Builder) {
+	if n.name.Local == "" {
+		b.WriteString(html.EscapeString(n.data))
+		return
+	}
+
+	b.WriteString("<" + n.name.Local)
+	for _, a := range n.attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		b.WriteString(" " + a.Name.Local + "=\"" + html.EscapeString(a.Value) + "\"")
+	}
+	b.WriteString(">")
+	if voidElements[n.name.Local] {
+		return
+	}
+	for _, c := range n.children {
+		c.render(b)
+	}
+	b.WriteString("</" + n.name.Local + ">")
+}
+
+func hasElements(n *node) bool {
+	for _, c := range n.children {
+		if c.name.Local != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// void elements from unescaped HTML, <link> is used in feeds
+var autoClose = []string{"br", "hr", "img", "input", "area", "col", "param", "wbr"}
+
+// parseTree reads the XML document into a tree. Errors after the root
+// element has started are ignored and the partial tree is returned.
+func parseTree(data []byte) (*node, error) {
+	d := xml.NewDecoder(bytes.NewReader(stripControl(data)))
+	d.Strict = false
+	d.AutoClose = autoClose
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = charset.NewReaderLabel
+
+	var root *node
+	stack := make([]*node, 0)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if root != nil {
+				return root, nil
+			}
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: tok.Name, attrs: tok.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 && root != nil {
+				return root, nil
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, &node{data: string(tok)})
+			}
+		}
+	}
+}
+
+// stripControl removes control characters which are not allowed in XML.
+func stripControl(data []byte) []byte {
+	return bytes.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, data)
+}
+
+func parseXML(data []byte) (*Feed, error) {
+	root, err := parseTree(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case root.name.Local == "rss":
+		return parseRSS(root), nil
+	case root.name.Local == "RDF":
+		return parseRDF(root), nil
+	case root.name.Local == "feed":
+		return parseAtom(root), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// parseRSS reads RSS 0.9x and 2.0.
+func parseRSS(root *node) *Feed {
+	f := &Feed{
+		Format:  FormatRSS,
+		Version: root.attr("version"),
+	}
+
+	ch := root.first("", "channel")
+	if ch == nil {
+		return f
+	}
+
+	readChannel(f, ch)
+	f.Image = ch.first("", "image").valueOrEmpty("", "url")
+	if f.Image == "" {
+		f.Image = ch.first(nsITunes, "image").attrOrEmpty("href")
+	}
+	for _, cat := range ch.all("", "category") {
+		f.Categories = appendText(f.Categories, cat.text())
+	}
+	if p := parseEmail(ch.value("", "managingEditor")); p.Name != "" || p.Email != "" {
+		f.Authors = append(f.Authors, p)
+	} else if a := ch.value(nsITunes, "author"); a != "" {
+		f.Authors = append(f.Authors, Person{Name: a})
+	}
+	for _, name := range []string{"lastBuildDate", "pubDate"} {
+		if d := parseDate(ch.value("", name)); d != nil {
+			f.Updated = d
+			break
+		}
+	}
+
+	// some RSS 0.9x feeds have the items outside the channel
+	items := append(ch.all("", "item"), root.all("", "item")...)
+	for _, item := range items {
+		f.Entries = append(f.Entries, rssEntry(item))
+	}
+	return f
+}
+
+// parseRDF reads RSS 1.0 and 0.90, items are siblings of the channel.
+func parseRDF(root *node) *Feed {
+	f := &Feed{
+		Format:  FormatRDF,
+		Version: "1.0",
+	}
+
+	for _, c := range root.children {
+		if c.name.Local == "" || !c.in(nsRSS10, nsRSS090, "") {
+			continue
+		}
+		switch c.name.Local {
+		case "channel":
+			readChannel(f, c)
+		case "image":
+			f.Image = c.value(c.name.Space, "url")
+		case "item":
+			f.Entries = append(f.Entries, rssEntry(c))
+		}
+		if c.name.Space == nsRSS090 {
+			f.Version = "0.90"
+		}
+	}
+	return f
+}
+
+// readChannel reads the elements which are common to RSS and RDF channels.
+func readChannel(f *Feed, ch *node) {
+	space := ch.name.Space
+	f.Title = plainText(ch.value(space, "title"))
+	f.Link = ch.value(space, "link")
+	f.Description = plainText(ch.value(space, "description"))
+	f.Language = ch.value(space, "language")
+	if f.Language == "" {
+		f.Language = ch.value(nsDC, "language")
+	}
+
+	for _, l := range ch.all(nsAtom, "link") {
+		if l.attr("rel") == "self" {
+			f.FeedURL = l.attr("href")
+		}
+	}
+
+	for _, c := range ch.all(nsDC, "creator") {
+		f.Authors = append(f.Authors, Person{Name: strings.TrimSpace(c.text())})
+	}
+	for _, s := range ch.all(nsDC, "subject") {
+		f.Categories = appendText(f.Categories, s.text())
+	}
+	f.Updated = parseDate(ch.value(nsDC, "date"))
+}
+
+func rssEntry(item *node) Entry {
+	space := item.name.Space
+	e := Entry{
+		Title:   plainText(item.value(space, "title")),
+		Link:    item.value(space, "link"),
+		Summary: item.first(space, "description").htmlOrEmpty(),
+		Content: item.first(nsContent, "encoded").htmlOrEmpty(),
+	}
+
+	if guid := item.first(space, "guid"); guid != nil {
+		e.ID = strings.TrimSpace(guid.text())
+		if e.Link == "" && guid.attr("isPermaLink") != "false" && isHTTP(e.ID) {
+			e.Link = e.ID
+		}
+	}
+	if e.ID == "" {
+		e.ID = item.attr("about")
+	}
+
+	if p := parseEmail(item.value(space, "author")); p.Name != "" || p.Email != "" {
+		e.Authors = append(e.Authors, p)
+	}
+	for _, c := range item.all(nsDC, "creator") {
+		e.Authors = append(e.Authors, Person{Name: strings.TrimSpace(c.text())})
+	}
+
+	for _, c := range item.all(space, "category") {
+		e.Categories = appendText(e.Categories, c.text())
+	}
+	for _, s := range item.all(nsDC, "subject") {
+		e.Categories = appendText(e.Categories, s.text())
+	}
+
+	e.Published = parseDate(item.value(space, "pubDate"))
+	if e.Published == nil {
+		e.Published = parseDate(item.value(nsDC, "date"))
+	}
+	e.Updated = parseDate(item.value(nsAtom, "updated"))
+
+	for _, enc := range item.all(space, "enclosure") {
+		e.Enclosures = append(e.Enclosures, Enclosure{
+			URL:    enc.attr("url"),
+			Type:   enc.attr("type"),
+			Length: parseLength(enc.attr("length")),
+		})
+	}
+
+	readMedia(&e, item)
+	readITunes(&e, item)
+	return e
+}
+
+// readITunes reads the tags for podcasts. The duration and image of the
+// episode apply to its enclosures.
+// See: https://help.apple.com/itc/podcasts_connect/#/itcb54353390
+func readITunes(e *Entry, item *node) {
+	if len(e.Authors) == 0 {
+		if a := item.value(nsITunes, "author"); a != "" {
+			e.Authors = append(e.Authors, Person{Name: a})
+		}
+	}
+	if e.Summary == "" {
+		e.Summary = item.first(nsITunes, "summary").htmlOrEmpty()
+	}
+
+	image := item.first(nsITunes, "image").attrOrEmpty("href")
+	if e.Image == "" {
+		e.Image = image
+	}
+
+	duration := pipeline.ParseDuration(item.value(nsITunes, "duration"))
+	for i := range e.Enclosures {
+		enc := &e.Enclosures[i]
+		if enc.Duration == 0 {
+			enc.Duration = duration
+		}
+		if enc.Image == "" {
+			enc.Image = image
+		}
+	}
+}
+
+// readMedia reads Media RSS elements, images are used as the entry image,
+// other media as enclosures.
+func readMedia(e *Entry, item *node) {
+	contents := item.all(nsMedia, "content")
+	for _, g := range item.all(nsMedia, "group") {
+		contents = append(contents, g.all(nsMedia, "content")...)
+	}
+
+	for _, c := range contents {
+		u := c.attr("url")
+		if u == "" {
+			continue
+		}
+		typ := c.attr("type")
+		if c.attr("medium") == "image" || strings.HasPrefix(typ, "image/") {
+			if e.Image == "" {
+				e.Image = u
+			}
+			continue
+		}
+		if !hasEnclosure(e, u) {
+			e.Enclosures = append(e.Enclosures, Enclosure{
+				URL:      u,
+				Type:     typ,
+				Length:   parseLength(c.attr("fileSize")),
+				Title:    plainText(c.value(nsMedia, "title")),
+				Duration: pipeline.ParseDuration(c.attr("duration")),
+				Image:    c.first(nsMedia, "thumbnail").attrOrEmpty("url"),
+			})
+		}
+	}
+
+	if e.Image == "" {
+		if th := item.first(nsMedia, "thumbnail"); th != nil {
+			e.Image = th.attr("url")
+		}
+	}
+}
+
+func hasEnclosure(e *Entry, u string) bool {
+	for _, enc := range e.Enclosures {
+		if enc.URL == u {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAtom reads Atom 1.0 and 0.3.
+func parseAtom(root *node) *Feed {
+	space := root.name.Space
+	f := &Feed{
+		Format:      FormatAtom,
+		Version:     "1.0",
+		Title:       atomText(root.first(space, "title")),
+		Description: atomText(root.first(space, "subtitle")),
+		Language:    root.attr("lang"),
+		Updated:     parseDate(root.value(space, "updated")),
+		Authors:     atomPersons(root, space),
+	}
+	if space == nsAtom03 {
+		f.Version = "0.3"
+		f.Description = atomText(root.first(space, "tagline"))
+		f.Updated = parseDate(root.value(space, "modified"))
+	}
+
+	f.Link, f.FeedURL = atomLinks(root, space)
+	f.Image = root.value(space, "logo")
+	if f.Image == "" {
+		f.Image = root.value(space, "icon")
+	}
+	f.Categories = atomCategories(root, space)
+
+	for _, entry := range root.all(space, "entry") {
+		f.Entries = append(f.Entries, atomEntry(entry, space))
+	}
+	return f
+}
+
+func atomEntry(entry *node, space string) Entry {
+	e := Entry{
+		ID:         entry.value(space, "id"),
+		Title:      atomText(entry.first(space, "title")),
+		Summary:    atomHTML(entry.first(space, "summary")),
+		Content:    atomHTML(entry.first(space, "content")),
+		Authors:    atomPersons(entry, space),
+		Categories: atomCategories(entry, space),
+		Published:  parseDate(entry.value(space, "published")),
+		Updated:    parseDate(entry.value(space, "updated")),
+	}
+	if space == nsAtom03 {
+		e.Published = parseDate(entry.value(space, "issued"))
+		e.Updated = parseDate(entry.value(space, "modified"))
+	}
+
+	e.Link, _ = atomLinks(entry, space)
+	for _, l := range entry.all(space, "link") {
+		if l.attr("rel") == "enclosure" {
+			e.Enclosures = append(e.Enclosures, Enclosure{
+				URL:    l.attr("href"),
+				Type:   l.attr("type"),
+				Length: parseLength(l.attr("length")),
+				Title:  l.attr("title"),
+			})
+		}
+	}
+
+	readMedia(&e, entry)
+	return e
+}
+
+// atomLinks returns the alternate and the self link.
+func atomLinks(n *node, space string) (string, string) {
+	var alternate, self string
+	for _, l := range n.all(space, "link") {
+		href := l.attr("href")
+		switch l.attr("rel") {
+		case "", "alternate":
+			if alternate == "" || l.attr("type") == "text/html" {
+				alternate = href
+			}
+		case "self":
+			self = href
+		}
+	}
+	return alternate, self
+}
+
+func atomPersons(n *node, space string) []Person {
+	var persons []Person
+	for _, a := range n.all(space, "author") {
+		persons = append(persons, Person{
+			Name:  a.value(space, "name"),
+			Email: a.value(space, "email"),
+			URL:   a.value(space, "uri"),
+		})
+	}
+	return persons
+}
+
+func atomCategories(n *node, space string) []string {
+	var categories []string
+	for _, c := range n.all(space, "category") {
+		label := c.attr("label")
+		if label == "" {
+			label = c.attr("term")
+		}
+		categories = appendText(categories, label)
+	}
+	return categories
+}
+
+// atomText returns an Atom text construct as plain text.
+func atomText(n *node) string {
+	if n == nil {
+		return ""
+	}
+	if n.attr("type") == "xhtml" {
+		return plainText(n.html())
+	}
+	return plainText(n.text())
+}
+
+// atomHTML returns an Atom text construct as HTML.
+func atomHTML(n *node) string {
+	if n == nil {
+		return ""
+	}
+	switch n.attr("type") {
+	case "xhtml":
+		// the content is wrapped in a <div>
+		if div := n.first(nsXHTML, "div"); div != nil {
+			return div.html()
+		}
+		return n.html()
+	case "html", "text/html":
+		return n.htmlOrEmpty()
+	}
+	return html.EscapeString(strings.TrimSpace(n.text()))
+}
+
+const nsXHTML = "http://www.w3.org/1999/xhtml"
+
+func (n *node) htmlOrEmpty() string {
+	if n == nil {
+		return ""
+	}
+	return n.html()
+}
+
+func (n *node) attrOrEmpty(local string) string {
+	if n == nil {
+		return ""
+	}
+	return n.attr(local)
+}
+
+func (n *node) valueOrEmpty(space, local string) string {
+	if n == nil {
+		return ""
+	}
+	return n.value(space, local)
+}
+
+// parseEmail reads an RSS author like "jane@example.com (Jane Doe)".
+func parseEmail(s string) Person {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "("); i >= 0 && strings.HasSuffix(s, ")") {
+		return Person{
+			Email: strings.TrimSpace(s[:i]),
+			Name:  strings.TrimSpace(s[i+1 : len(s)-1]),
+		}
+	}
+	if strings.Contains(s, "@") && !strings.Contains(s, " ") {
+		return Person{Email: s}
+	}
+	return Person{Name: s}
+}
+
+func parseLength(s string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+func appendText(list []string, s string) []string {
+	s = plainText(s)
+	if s == "" {
+		return list
+	}
+	return append(list, s)
+}
+
+func isHTTP(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
@@ -0,0 +1,274 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+var (
+	// ErrBlocked means the site responded with a bot challenge or an
+	// interstitial instead of the requested page.
+	ErrBlocked = errors.New("blocked by bot protection")
+	// ErrNotFound means the page does not exist,
+	// even if the server responded with 200 OK.
+	ErrNotFound = errors.New("page not found")
+)
+
+// DetectionError is returned if the response is not the requested page.
+// Err is either ErrBlocked or ErrNotFound, use errors.Is to check.
+type DetectionError struct {
+	Err    error
+	URL    string
+	Status int
+	// Signature names what was detected, e.g. "cloudflare".
+	Signature string
+}
+
+func (e *DetectionError) Error() string {
+	return fmt.Sprintf("%v: %v (%v, HTTP status %v)", e.URL, e.Err, e.Signature, e.Status)
+}
+
+func (e *DetectionError) Unwrap() error {
+	return e.Err
+}
+
+// challenge describes the page of a bot protection service.
+type challenge struct {
+	Name string
+	// Header is matched against response headers, name and value.
+	// An empty value matches any value.
+	Header [2]string
+	// Markers are matched against the lower case HTML source.
+	Markers []string
+}
+
+var challenges = []challenge{
+	{
+		Name:   "cloudflare",
+		Header: [2]string{"Cf-Mitigated", "challenge"},
+		Markers: []string{
+			"cf-browser-verification",
+			"cf_chl_opt",
+			"cf-challenge-running",
+			"<title>just a moment...</title>",
+			"<title>attention required! | cloudflare</title>",
+			"checking your browser before accessing",
+		},
+	},
+	{
+		Name: "perimeterx",
+		Markers: []string{
+			"px-captcha",
+			"captcha.px-cdn.net",
+			"window._pxappid",
+			"access to this page has been denied",
+		},
+	},
+	{
+		Name:   "datadome",
+		Header: [2]string{"X-Datadome", ""},
+		Markers: []string{
+			"captcha-delivery.com",
+		},
+	},
+	{
+		Name: "imperva",
+		Markers: []string{
+			"_incapsula_resource",
+			"incapsula incident id",
+		},
+	},
+	{
+		Name: "akamai",
+		Markers: []string{
+			"errors.edgesuite.net",
+		},
+	},
+	{
+		Name: "sucuri",
+		Markers: []string{
+			"sucuri website firewall",
+		},
+	},
+	{
+		Name: "ddos-guard",
+		Markers: []string{
+			"<title>ddos-guard</title>",
+			"check.ddos-guard.net",
+		},
+	},
+	{
+		Name: "aws-waf",
+		Markers: []string{
+			"awswafintegration",
+			"gokuprops",
+		},
+	},
+}
+
+// Challenge and error pages have very little text. Larger pages are regular
+// content, even if they contain one of the markers, e.g. an embedded captcha.
+const (
+	maxChallengeWords = 200
+	maxNotFoundWords  = 300
+)
+
+// detect checks if the response is a bot challenge or a "not found" page.
+// Returns a DetectionError if so.
+func detect(url string, status int, h http.Header, s string) error {
+	blocked := func(name string) error {
+		return &DetectionError{Err: ErrBlocked, URL: url, Status: status, Signature: name}
+	}
+
+	for _, c := range challenges {
+		name, value := c.Header[0], c.Header[1]
+		if name == "" {
+			continue
+		}
+		v := h.Get(name)
+		if v != "" && (value == "" || strings.EqualFold(v, value)) && status != http.StatusOK {
+			return blocked(c.Name)
+		}
+	}
+
+	p := readPage(s)
+	if p.Words <= maxChallengeWords || status != http.StatusOK {
+		lower := strings.ToLower(s)
+		for _, c := range challenges {
+			for _, m := range c.Markers {
+				if strings.Contains(lower, m) {
+					return blocked(c.Name)
+				}
+			}
+		}
+	}
+
+	if status == http.StatusOK && p.Words <= maxNotFoundWords {
+		if IsNotFoundTitle(p.Title) || IsNotFoundTitle(p.Heading) {
+			return &DetectionError{Err: ErrNotFound, URL: url, Status: status, Signature: "title"}
+		}
+
+		for _, phrase := range notFoundPhrases {
+			if strings.Contains(p.Text, phrase) {
+				return &DetectionError{Err: ErrNotFound, URL: url, Status: status, Signature: "phrase"}
+			}
+		}
+	}
+
+	return nil
+}
+
+var (
+	notFoundPart  = `(?:error\s+)?(?:404(?:\s+error)?|(?:404\s+)?(?:error\s+)?(?:page\s+)?not\s+found|fehler\s+404|seite\s+nicht\s+gefunden|page\s+(?:introuvable|non\s+trouvée)|página\s+no\s+encontrada|pagina\s+non\s+trovata|pagina\s+niet\s+gevonden)`
+	titleSep      = `(?:\s*[|:·•»]\s*|\s+[-–—]\s+)`
+	titleSite     = `[^|:·•»]{1,60}?`
+	notFoundTitle = regexp.MustCompile(`(?i)^\W*(?:` + titleSite + titleSep + `)?` +
+		notFoundPart + `(?:` + titleSep + notFoundPart + `)*` +
+		`(?:` + titleSep + titleSite + `)?\W*$`)
+)
+
+// IsNotFoundTitle tells if the title or headline of a page says that the
+// page was not found, e.g. "404 - Page not found".
+//
+// The whole title must consist of the message, with at most the site name
+// before or after it. Titles which only mention "404", like an article about
+// "not found" errors, do not count.
+func IsNotFoundTitle(s string) bool {
+	return notFoundTitle.MatchString(strings.TrimSpace(s))
+}
+
+// phrases on "not found" pages, in lower case
+var notFoundPhrases = []string{
+	// en
+	"the page you requested could not be found",
+	"the page you are looking for could not be found",
+	"the page you are looking for does not exist",
+	"the page you were looking for doesn't exist",
+	"we can't find the page you're looking for",
+	"we couldn't find the page you were looking for",
+	"this page doesn't exist",
+	// de
+	"die angeforderte seite wurde nicht gefunden",
+	"die seite konnte nicht gefunden werden",
+	"diese seite existiert nicht",
+	// fr
+	"la page que vous recherchez n'existe pas",
+	"la page demandée est introuvable",
+	// es
+	"la página que buscas no existe",
+	"la página solicitada no existe",
+	// it
+	"la pagina che stai cercando non esiste",
+	// nl
+	"de pagina die je zoekt bestaat niet",
+}
+
+// page holds the parts of a document which are used for detection.
+type page struct {
+	Title   string
+	Heading string
+	// Text is the lower case text of the body, without scripts.
+	Text  string
+	Words int
+}
+
+func readPage(s string) page {
+	var (
+		p                    page
+		inTitle, inH1, skip  bool
+		title, heading, text strings.Builder
+	)
+
+	reader := func(t html.Token) error {
+		switch t.Type {
+		case html.StartTagToken:
+			switch t.DataAtom {
+			case atom.Title:
+				inTitle = true
+			case atom.H1:
+				inH1 = heading.Len() == 0
+			case atom.Script, atom.Style, atom.Noscript, atom.Template:
+				skip = true
+			}
+		case html.EndTagToken:
+			switch t.DataAtom {
+			case atom.Title:
+				inTitle = false
+			case atom.H1:
+				inH1 = false
+			case atom.Script, atom.Style, atom.Noscript, atom.Template:
+				skip = false
+			}
+		case html.TextToken:
+			switch {
+			case inTitle:
+				title.WriteString(t.Data)
+			case skip:
+			default:
+				if inH1 {
+					heading.WriteString(t.Data)
+				}
+				text.WriteString(t.Data)
+				text.WriteString(" ")
+			}
+		}
+		return nil
+	}
+
+	pipeline.ReadHTML(s, reader)
+
+	fields := strings.Fields(strings.ToLower(text.String()))
+	p.Title = strings.TrimSpace(title.String())
+	p.Heading = strings.TrimSpace(heading.String())
+	p.Text = strings.Join(fields, " ")
+	p.Words = len(fields)
+	return p
+}
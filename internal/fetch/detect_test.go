@@ -0,0 +1,149 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestDetectBlocked(t *testing.T) {
+	assert := assert.New(t)
+
+	// Cloudflare challenge
+	html := `<html><head><title>Just a moment...</title></head>
+    <body><div id="cf-browser-verification">Checking your browser before accessing example.com.</div>
+    <script>window._cf_chl_opt = {};</script></body></html>`
+	err := detect("https://example.com", 503, http.Header{}, html)
+	var d *DetectionError
+	assert.True(errors.As(err, &d))
+	assert.True(errors.Is(err, ErrBlocked))
+	assert.Equal("cloudflare", d.Signature)
+	assert.Equal(503, d.Status)
+
+	// header only
+	h := http.Header{}
+	h.Set("cf-mitigated", "challenge")
+	err = detect("https://example.com", 403, h, "<html></html>")
+	assert.True(errors.Is(err, ErrBlocked))
+
+	// PerimeterX with status 200
+	html = `<html><head><title>Access to this page has been denied.</title></head>
+    <body><div id="px-captcha"></div><p>Press &amp; Hold to confirm you are a human.</p></body></html>`
+	err = detect("https://example.com", 200, http.Header{}, html)
+	assert.True(errors.As(err, &d))
+	assert.Equal("perimeterx", d.Signature)
+
+	// markers in a regular article are ignored
+	text := strings.Repeat("Words in an article. ", 100)
+	html = `<html><head><title>Article</title></head>
+    <body><p>` + text + `</p><script src="https://captcha-delivery.com/c.js"></script></body></html>`
+	err = detect("https://example.com", 200, http.Header{}, html)
+	assert.Nil(err)
+}
+
+func TestDetectNotFound(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><head><title>Page not found | Example</title></head>
+    <body><h1>Oops</h1><p>Try the search.</p></body></html>`
+	err := detect("https://example.com/missing", 200, http.Header{}, html)
+	var d *DetectionError
+	assert.True(errors.As(err, &d))
+	assert.True(errors.Is(err, ErrNotFound))
+	assert.Equal("title", d.Signature)
+
+	// heading
+	html = `<html><head><title>Example</title></head>
+    <body><h1>404</h1><p>Nothing here.</p></body></html>`
+	err = detect("https://example.com/missing", 200, http.Header{}, html)
+	assert.True(errors.Is(err, ErrNotFound))
+
+	// phrase
+	html = `<html><head><title>Example</title></head>
+    <body><p>Leider: Die Seite konnte
+    nicht gefunden werden.</p></body></html>`
+	err = detect("https://example.com/fehlt", 200, http.Header{}, html)
+	assert.True(errors.As(err, &d))
+	assert.Equal("phrase", d.Signature)
+
+	// an article about "not found"
+	text := strings.Repeat("Words in an article. ", 100)
+	html = `<html><head><title>Why "page not found" errors hurt your ranking</title></head>
+    <body><p>` + text + `</p></body></html>`
+	err = detect("https://example.com/article", 200, http.Header{}, html)
+	assert.Nil(err)
+
+	// a short article which mentions "404" in the title
+	html = `<html><head><title>How to fix a 404 not found error in nginx</title></head>
+    <body><p>Check the root directive.</p></body></html>`
+	err = detect("https://example.com/nginx-404", 200, http.Header{}, html)
+	assert.Nil(err)
+
+	// no article text in the script
+	html = `<html><head><title>Example</title></head>
+    <body><p>Content</p><script>var msg = "this page doesn't exist";</script></body></html>`
+	err = detect("https://example.com/page", 200, http.Header{}, html)
+	assert.Nil(err)
+}
+
+func TestIsNotFoundTitle(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, s := range []string{
+		"404",
+		"Error 404",
+		"404 Not Found",
+		"404 - Page not found",
+		"Page not found | Example",
+		"Example » Error 404: Page Not Found",
+		"Fehler 404 - Seite nicht gefunden - Spiegel-Online",
+		"Page introuvable",
+	} {
+		assert.True(IsNotFoundTitle(s), s)
+	}
+
+	for _, s := range []string{
+		"How to fix a 404 not found error in nginx",
+		"Why 'Page not found' pages matter",
+		"Route 404 closed for repairs",
+		"Missing hiker still not found",
+		"The 404 page: a guide | Example",
+	} {
+		assert.False(IsNotFoundTitle(s), s)
+	}
+}
+
+func TestFetchWithFallback(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.UserAgent(), "Chrome") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<html><head><title>Access to this page has been denied.</title></head>
+                <body><div id="px-captcha"></div></body></html>`)
+			return
+		}
+		w.Header().Set("Content-Language", "de-de, en")
+		fmt.Fprint(w, `<html><head><title>Article</title></head><body><p>Content</p></body></html>`)
+	}))
+	defer server.Close()
+
+	task := &pipeline.Task{URL: server.URL}
+	err := Fetch(context.TODO(), task)
+	assert.True(errors.Is(err, ErrBlocked))
+
+	task = &pipeline.Task{URL: server.URL}
+	err = FetchWithFallback(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, task.StatusCode)
+	assert.Contains(task.HTML(), "Content")
+	assert.Equal("de-DE", task.Language)
+}
@@ -2,6 +2,7 @@ package fetch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"golang.org/x/net/publicsuffix"
 	"net/http"
@@ -9,27 +10,48 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/akeil/scrapen/internal/lang"
 	"github.com/akeil/scrapen/internal/pipeline"
 )
 
 // Fetch fetches the HTML content for the given item.
+//
+// Returns a DetectionError if the site responds with a bot challenge or a
+// "not found" page.
 func Fetch(ctx context.Context, t *pipeline.Task) error {
+	return fetchProfile(ctx, t, profiles["default"])
+}
+
+// FetchWithFallback works like Fetch, but repeats the request with the
+// fallback browser profile if the site responds with a bot challenge.
+func FetchWithFallback(ctx context.Context, t *pipeline.Task) error {
+	err := Fetch(ctx, t)
+	if !errors.Is(err, ErrBlocked) {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "fetch",
+		"url":    t.ContentURL(),
+	}).Info("Blocked, retry with fallback profile")
+
+	return fetchProfile(ctx, t, profiles["fallback"])
+}
+
+func fetchProfile(ctx context.Context, t *pipeline.Task, profile browserProfile) error {
 	log.WithFields(log.Fields{
 		"task":   t.ID,
 		"module": "fetch",
 		"url":    t.ContentURL(),
 	}).Info("Fetch content")
 
-	opts := &cookiejar.Options{PublicSuffixList: publicsuffix.List}
-	jar, err := cookiejar.New(opts)
+	client, err := httpClient(t)
 	if err != nil {
 		return err
 	}
-	client := &http.Client{
-		Jar: jar,
-	}
 
-	actURL, html, err := fetchURL(ctx, client, t, t.URL)
+	actURL, html, header, err := fetchURL(ctx, client, profile, t, t.URL)
 	if err != nil {
 		return err
 	}
@@ -46,7 +68,7 @@ func Fetch(ctx context.Context, t *pipeline.Task) error {
 			"url":    redirect,
 		}).Info("Redirect from <meta>")
 
-		actURL, html, err = fetchURL(ctx, client, t, redirect)
+		actURL, html, header, err = fetchURL(ctx, client, profile, t, redirect)
 		if err != nil {
 			return err
 		}
@@ -68,22 +90,24 @@ func Fetch(ctx context.Context, t *pipeline.Task) error {
 		t.SetAltHTML(html)
 		t.AltURL = actURL
 
-		actURL, html, err = fetchURL(ctx, client, t, canonicalURL)
+		actURL, html, header, err = fetchURL(ctx, client, profile, t, canonicalURL)
 		if err != nil {
 			return err
 		}
 		t.SetHTML(html)
 		t.ActualURL = actURL
+		t.Language = lang.Normalize(header.Get("Content-Language"))
 
 	} else {
 		t.SetHTML(html)
 		t.ActualURL = actURL
+		t.Language = lang.Normalize(header.Get("Content-Language"))
 
 		// If an AMP (https://amp.dev/) version is available, fetch that.
 		// Often easier to make readable.
 		amp := findAmpUrl(html)
 		if amp != "" {
-			err = fetchAMP(ctx, client, t, amp)
+			err = fetchAMP(ctx, client, profile, t, amp)
 			if err != nil {
 				log.WithFields(log.Fields{
 					"task":   t.ID,
@@ -97,7 +121,24 @@ func Fetch(ctx context.Context, t *pipeline.Task) error {
 	return nil
 }
 
-func fetchAMP(ctx context.Context, client *http.Client, t *pipeline.Task, url string) error {
+// httpClient returns the client that is configured for the task.
+// If there is none, a client with a new cookie jar is created.
+func httpClient(t *pipeline.Task) (*http.Client, error) {
+	if t.Client != nil {
+		return t.Client, nil
+	}
+
+	opts := &cookiejar.Options{PublicSuffixList: publicsuffix.List}
+	jar, err := cookiejar.New(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Jar: jar,
+	}, nil
+}
+
+func fetchAMP(ctx context.Context, client *http.Client, profile browserProfile, t *pipeline.Task, url string) error {
 	log.WithFields(log.Fields{
 		"task":   t.ID,
 		"module": "fetch",
@@ -108,7 +149,7 @@ func fetchAMP(ctx context.Context, client *http.Client, t *pipeline.Task, url st
 		return err
 	}
 
-	actURL, html, err := fetchURL(ctx, client, t, url)
+	actURL, html, _, err := fetchURL(ctx, client, profile, t, url)
 	if err != nil {
 		return err
 	}
@@ -118,7 +159,7 @@ func fetchAMP(ctx context.Context, client *http.Client, t *pipeline.Task, url st
 	return nil
 }
 
-func fetchURL(ctx context.Context, client *http.Client, t *pipeline.Task, url string) (string, string, error) {
+func fetchURL(ctx context.Context, client *http.Client, profile browserProfile, t *pipeline.Task, url string) (string, string, http.Header, error) {
 	log.WithFields(log.Fields{
 		"task":   t.ID,
 		"module": "fetch",
@@ -127,9 +168,9 @@ func fetchURL(ctx context.Context, client *http.Client, t *pipeline.Task, url st
 
 	var actURL string
 
-	res, err := doRequest(ctx, client, url)
+	res, err := doRequest(ctx, client, profile, url)
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
 
 	// if failed, repeat the request with cookies
@@ -141,9 +182,9 @@ func fetchURL(ctx context.Context, client *http.Client, t *pipeline.Task, url st
 				"url":    url,
 				"status": res.StatusCode,
 			}).Info("Repeat request with cookies")
-			res, err = doRequest(ctx, client, url)
+			res, err = doRequest(ctx, client, profile, url)
 			if err != nil {
-				return "", "", err
+				return "", "", nil, err
 			}
 		}
 	}
@@ -160,41 +201,53 @@ func fetchURL(ctx context.Context, client *http.Client, t *pipeline.Task, url st
 		"url":    t.ActualURL,
 	}).Info(fmt.Sprintf("Status %v", t.StatusCode))
 
-	err = errorFromStatus(res)
+	defer res.Body.Close()
+
+	// the body of an error response may tell why the request failed
+	s, err := readBody(t, res)
 	if err != nil {
-		return "", "", err
+		statusErr := errorFromStatus(actURL, res)
+		if statusErr != nil {
+			return "", "", nil, statusErr
+		}
+		return "", "", nil, err
 	}
-	defer res.Body.Close()
 
-	// decompress
-	r, err := decompressed(t, res.Body, res.Header)
+	err = detect(actURL, res.StatusCode, res.Header, s)
 	if err != nil {
-		return "", "", err
+		log.WithFields(log.Fields{
+			"task":   t.ID,
+			"module": "fetch",
+			"url":    actURL,
+		}).Warn(err)
+		return "", "", nil, err
 	}
 
-	// decode charset
-	s, err := readUTF8(t, r, res.Header)
+	err = errorFromStatus(actURL, res)
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
 
-	return actURL, s, nil
+	return actURL, s, res.Header, nil
 }
 
-func doRequest(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// readBody reads the decompressed and decoded response body.
+func readBody(t *pipeline.Task, res *http.Response) (string, error) {
+	r, err := decompressed(t, res.Body, res.Header)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	// Add cookies collected in previous requests
-	log.Info(fmt.Sprintf("Set cookies for %v", req.URL))
-	for _, c := range client.Jar.Cookies(req.URL) {
-		log.Info(fmt.Sprintf("Cookie: %v", c.String()))
-		req.AddCookie(c)
+	return readUTF8(t, r, res.Header)
+}
+
+func doRequest(ctx context.Context, client *http.Client, profile browserProfile, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	setHeaders(req)
+	setHeaders(req, profile)
 
 	for k, v := range req.Header {
 		log.WithFields(log.Fields{
@@ -218,7 +271,9 @@ func doRequest(ctx context.Context, client *http.Client, url string) (*http.Resp
 	}
 
 	// Set cookies for all subsequent requests
-	client.Jar.SetCookies(res.Request.URL, res.Cookies())
+	if client.Jar != nil {
+		client.Jar.SetCookies(res.Request.URL, res.Cookies())
+	}
 
 	return res, nil
 }
@@ -236,10 +291,16 @@ var profiles = map[string]browserProfile{
 		Accept:         "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.9",
 		AcceptLanguage: "en-US,en;q=0.9,de;q=0.8",
 	},
+	// Taken from Firefox on Linux,
+	// used if a site blocks requests with the default profile
+	"fallback": browserProfile{
+		UserAgent:      "Mozilla/5.0 (X11; Linux x86_64; rv:86.0) Gecko/20100101 Firefox/86.0",
+		Accept:         "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+		AcceptLanguage: "en-US,en;q=0.5",
+	},
 }
 
-func setHeaders(req *http.Request) {
-	profile := profiles["default"]
+func setHeaders(req *http.Request, profile browserProfile) {
 	// Problem:
 	// *some* URL shorteners will return a HTML site with a redirect
 	// if they think the requests comes from a browser
@@ -267,7 +328,17 @@ func didReceiveCookie(res *http.Response) bool {
 	return c != ""
 }
 
-func errorFromStatus(res *http.Response) error {
+func errorFromStatus(url string, res *http.Response) error {
+	switch res.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return &DetectionError{
+			Err:       ErrNotFound,
+			URL:       url,
+			Status:    res.StatusCode,
+			Signature: "status",
+		}
+	}
+
 	// TODO: should we accept more status codes?
 	if res.StatusCode != http.StatusOK {
 		return fmt.Errorf("got HTTP status %v", res.StatusCode)
@@ -0,0 +1,128 @@
+package har
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			io.WriteString(w, "<p>Content</p>")
+		case "/compressed":
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Encoding", "gzip")
+			z := gzip.NewWriter(w)
+			io.WriteString(z, "<p>Compressed</p>")
+			z.Close()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	rec := NewRecorder(nil)
+	client := &http.Client{Transport: rec}
+
+	body, status := get(t, client, srv.URL+"/redirect", false)
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("<p>Content</p>", body)
+
+	body, _ = get(t, client, srv.URL+"/compressed", true)
+	assert.Equal("<p>Compressed</p>", body)
+
+	h := rec.HAR()
+	assert.Equal(3, len(h.Log.Entries))
+	assert.Equal(http.StatusFound, h.Log.Entries[0].Response.Status)
+	assert.Equal("<p>Content</p>", h.Log.Entries[1].Response.Content.Text)
+	assert.Equal("base64", h.Log.Entries[2].Response.Content.Encoding)
+
+	path := filepath.Join(t.TempDir(), "test.har")
+	assert.Nil(rec.Append(path))
+
+	// a second recorder adds to the file
+	rec2 := NewRecorder(nil)
+	get(t, &http.Client{Transport: rec2}, srv.URL+"/page", false)
+	assert.Nil(rec2.Append(path))
+	h, err := Load(path)
+	assert.Nil(err)
+	assert.Equal(4, len(h.Log.Entries))
+
+	// replay with the server stopped
+	srv.Close()
+	rep, err := LoadReplayer(path)
+	assert.Nil(err)
+	client = &http.Client{Transport: rep}
+
+	body, status = get(t, client, srv.URL+"/redirect", false)
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("<p>Content</p>", body)
+
+	body, _ = get(t, client, srv.URL+"/compressed", true)
+	assert.Equal("<p>Compressed</p>", body)
+
+	_, err = client.Get(srv.URL + "/unknown")
+	assert.NotNil(err)
+}
+
+func TestReplayOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	h := &HAR{}
+	for _, text := range []string{"first", "second"} {
+		h.Log.Entries = append(h.Log.Entries, Entry{
+			Request: Request{Method: "GET", URL: "https://example.com/"},
+			Response: Response{
+				Status:  200,
+				Content: Content{Text: text},
+			},
+		})
+	}
+
+	client := &http.Client{Transport: NewReplayer(h)}
+	for _, expected := range []string{"first", "second", "second"} {
+		body, _ := get(t, client, "https://example.com/", false)
+		assert.Equal(expected, body)
+	}
+}
+
+func get(t *testing.T, client *http.Client, url string, gzipped bool) (string, int) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// explicitly set, so that the transport does not decompress
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var r io.Reader = res.Body
+	if gzipped {
+		r, err = gzip.NewReader(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), res.StatusCode
+}
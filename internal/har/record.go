@@ -0,0 +1,216 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Recorder is a `http.RoundTripper` that records all requests and responses.
+type Recorder struct {
+	base    http.RoundTripper
+	entries []Entry
+	mx      sync.Mutex
+}
+
+// NewRecorder creates a recorder which sends requests through the given
+// transport. If base is nil, `http.DefaultTransport` is used.
+func NewRecorder(base http.RoundTripper) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Recorder{
+		base:    base,
+		entries: make([]Entry, 0),
+	}
+}
+
+// RoundTrip implements the `http.RoundTripper` interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	res, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// read the complete body so that it can be recorded,
+	// the caller receives a copy.
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(data))
+
+	elapsed := float64(time.Since(started).Microseconds()) / 1000
+
+	e := Entry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request:         recordRequest(req),
+		Response:        recordResponse(res, data),
+		Timings: Timings{
+			Wait: elapsed,
+		},
+	}
+
+	log.WithFields(log.Fields{
+		"module": "har",
+		"url":    e.Request.URL,
+		"status": e.Response.Status,
+	}).Debug("Record entry")
+
+	r.mx.Lock()
+	r.entries = append(r.entries, e)
+	r.mx.Unlock()
+
+	return res, nil
+}
+
+// HAR returns the recorded entries as a HTTP Archive.
+func (r *Recorder) HAR() *HAR {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+
+	return &HAR{
+		Log: Log{
+			Version: "1.2",
+			Creator: Creator{
+				Name:    "scrapen",
+				Version: "0.1",
+			},
+			Entries: entries,
+		},
+	}
+}
+
+// Save writes the recorded entries to a HAR file.
+func (r *Recorder) Save(path string) error {
+	return r.HAR().Save(path)
+}
+
+// Append adds the recorded entries to the HAR file at the given path.
+// The file is created if it does not exist.
+func (r *Recorder) Append(path string) error {
+	h, err := Load(path)
+	if os.IsNotExist(err) {
+		return r.Save(path)
+	} else if err != nil {
+		return err
+	}
+
+	h.Log.Entries = append(h.Log.Entries, r.HAR().Log.Entries...)
+	return h.Save(path)
+}
+
+func recordRequest(req *http.Request) Request {
+	cookies := make([]Cookie, 0)
+	for _, c := range req.Cookies() {
+		cookies = append(cookies, Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	return Request{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: protoOrDefault(req.Proto),
+		Cookies:     cookies,
+		Headers:     headerList(req.Header),
+		QueryString: headerList(req.URL.Query()),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+}
+
+func recordResponse(res *http.Response, data []byte) Response {
+	cookies := make([]Cookie, 0)
+	for _, c := range res.Cookies() {
+		cookies = append(cookies, Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	c := Content{
+		Size:     len(data),
+		MimeType: contentType,
+	}
+	if isText(contentType, res.Header.Get("Content-Encoding"), data) {
+		c.Text = string(data)
+	} else {
+		c.Text = base64.StdEncoding.EncodeToString(data)
+		c.Encoding = "base64"
+	}
+
+	return Response{
+		Status:      res.StatusCode,
+		StatusText:  statusText(res),
+		HTTPVersion: protoOrDefault(res.Proto),
+		Cookies:     cookies,
+		Headers:     headerList(res.Header),
+		Content:     c,
+		RedirectURL: res.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(data),
+	}
+}
+
+// isText tells if the body can be stored as plain text.
+func isText(contentType, encoding string, data []byte) bool {
+	if encoding != "" && !strings.EqualFold(encoding, "identity") {
+		return false
+	}
+
+	m, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	textual := strings.HasPrefix(m, "text/") ||
+		strings.HasSuffix(m, "json") ||
+		strings.HasSuffix(m, "xml")
+
+	return textual && utf8.Valid(data)
+}
+
+func headerList(h map[string][]string) []Header {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]Header, 0)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			result = append(result, Header{Name: k, Value: v})
+		}
+	}
+	return result
+}
+
+func statusText(res *http.Response) string {
+	// Status is "200 OK"
+	prefix := fmt.Sprintf("%v ", res.StatusCode)
+	if strings.HasPrefix(res.Status, prefix) {
+		return strings.TrimPrefix(res.Status, prefix)
+	}
+	return http.StatusText(res.StatusCode)
+}
+
+func protoOrDefault(p string) string {
+	if p == "" {
+		return "HTTP/1.1"
+	}
+	return p
+}
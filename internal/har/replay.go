@@ -0,0 +1,117 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Replayer is a `http.RoundTripper` which serves responses from a HTTP Archive
+// instead of sending requests over the network.
+//
+// Requests are matched by method and URL. If the same request was recorded
+// multiple times, the responses are served in the recorded order; the last
+// one is repeated.
+type Replayer struct {
+	entries map[string][]Entry
+	served  map[string]int
+	mx      sync.Mutex
+}
+
+// NewReplayer creates a replaying transport for the given archive.
+func NewReplayer(h *HAR) *Replayer {
+	r := &Replayer{
+		entries: make(map[string][]Entry),
+		served:  make(map[string]int),
+	}
+	for _, e := range h.Log.Entries {
+		k := key(e.Request.Method, e.Request.URL)
+		r.entries[k] = append(r.entries[k], e)
+	}
+	return r
+}
+
+// LoadReplayer creates a replaying transport from the given HAR file.
+func LoadReplayer(path string) (*Replayer, error) {
+	h, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(h), nil
+}
+
+// RoundTrip implements the `http.RoundTripper` interface.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	k := key(req.Method, req.URL.String())
+
+	r.mx.Lock()
+	entries, ok := r.entries[k]
+	if !ok {
+		r.mx.Unlock()
+		return nil, fmt.Errorf("no recorded response for %v %v", req.Method, req.URL)
+	}
+	n := r.served[k]
+	if n >= len(entries) {
+		n = len(entries) - 1
+	}
+	r.served[k] = n + 1
+	r.mx.Unlock()
+
+	log.WithFields(log.Fields{
+		"module": "har",
+		"url":    req.URL.String(),
+	}).Debug("Replay entry")
+
+	return toResponse(req, entries[n].Response)
+}
+
+func toResponse(req *http.Request, rec Response) (*http.Response, error) {
+	var data []byte
+	var err error
+	if rec.Content.Encoding == "base64" {
+		data, err = base64.StdEncoding.DecodeString(rec.Content.Text)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		data = []byte(rec.Content.Text)
+	}
+
+	h := make(http.Header)
+	for _, hd := range rec.Headers {
+		h.Add(hd.Name, hd.Value)
+	}
+	// the recorded length may not match, e.g. for a manually edited archive
+	h.Set("Content-Length", strconv.Itoa(len(data)))
+
+	proto := protoOrDefault(rec.HTTPVersion)
+	major, minor, ok := http.ParseHTTPVersion(proto)
+	if !ok {
+		proto, major, minor = "HTTP/1.1", 1, 1
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%v %v", rec.Status, rec.StatusText),
+		StatusCode:    rec.Status,
+		Proto:         proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+func key(method, url string) string {
+	if method == "" {
+		method = "GET"
+	}
+	return method + " " + url
+}
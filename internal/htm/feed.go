@@ -0,0 +1,140 @@
+package htm
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/akeil/scrapen/internal/feed"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// FeedOptions holds settings for ComposeFeed.
+type FeedOptions struct {
+	// Format is "atom", "rss" or "json".
+	Format      string
+	Title       string
+	Description string
+	// Link is the URL of the web site that publishes the feed.
+	Link string
+	// FeedURL is the URL of the feed itself.
+	FeedURL string
+	// ImageBaseURL is the URL where the contents of the Store are
+	// published. Images and enclosures from the store are referenced with
+	// the base URL and their key. If empty, images are inlined as data URLs
+	// and enclosures keep their original URL.
+	ImageBaseURL string
+}
+
+// ComposeFeed writes the given tasks as a feed with one entry per task.
+// Entries have the full HTML content.
+func ComposeFeed(w io.Writer, ts []*pipeline.Task, o FeedOptions) error {
+	f := &feed.Feed{
+		Title:       o.Title,
+		Description: o.Description,
+		Link:        o.Link,
+		FeedURL:     o.FeedURL,
+	}
+
+	for _, t := range ts {
+		e, err := feedEntry(t, o.ImageBaseURL)
+		if err != nil {
+			return err
+		}
+		f.Entries = append(f.Entries, e)
+	}
+
+	return feed.Write(w, f, o.Format)
+}
+
+func feedEntry(t *pipeline.Task, base string) (feed.Entry, error) {
+	link := t.ContentURL()
+	e := feed.Entry{
+		ID:         link,
+		Title:      t.Title,
+		Link:       link,
+		Categories: t.Keywords,
+		Published:  t.PubDate,
+		Updated:    t.ModifiedDate,
+	}
+	if t.CanonicalURL != "" {
+		e.ID = t.CanonicalURL
+	}
+	// the description is plain text, the summary HTML like in parsed feeds;
+	// the JSON Feed writer turns it back into plain text
+	if t.Description != "" {
+		e.Summary = html.EscapeString(t.Description)
+	}
+
+	for _, a := range t.Authors {
+		e.Authors = append(e.Authors, feed.Person{Name: a.Name, URL: a.URL})
+	}
+	if len(e.Authors) == 0 && t.Author != "" {
+		e.Authors = append(e.Authors, feed.Person{Name: t.Author})
+	}
+
+	src := dataURL(t)
+	if base != "" {
+		src = func(storeID string) (string, error) {
+			return storeURL(base, storeID), nil
+		}
+	}
+
+	doc := t.Document()
+	if doc != nil {
+		body, err := doc.Find("body").First().Html()
+		if err != nil {
+			return e, err
+		}
+		var b strings.Builder
+		err = writeImages(&b, body, src)
+		if err != nil {
+			return e, err
+		}
+		e.Content = strings.TrimSpace(b.String())
+	}
+
+	e.Image = entryImage(t, base)
+
+	for _, enc := range t.Enclosures {
+		u := enc.URL
+		if base != "" && enc.Key != "" {
+			u = storeURL(base, enc.Key)
+		}
+		e.Enclosures = append(e.Enclosures, feed.Enclosure{
+			URL:      u,
+			Type:     enc.ContentType,
+			Length:   enc.Size,
+			Title:    enc.Title,
+			Duration: enc.Duration,
+			Image:    enc.Image,
+		})
+	}
+
+	return e, nil
+}
+
+// entryImage returns the URL for the main image of the task. A downloaded
+// image is referenced by its original URL if there is no base URL, data URLs
+// are too large for the image of an entry.
+func entryImage(t *pipeline.Task, base string) string {
+	storeID := pipeline.ParseStoreID(t.ImageURL)
+	if storeID == "" {
+		return t.ImageURL
+	}
+	if base != "" {
+		return storeURL(base, storeID)
+	}
+	for _, img := range t.Images {
+		if img.Key == storeID {
+			return img.OriginalURL
+		}
+	}
+	return ""
+}
+
+func storeURL(base, storeID string) string {
+	return strings.TrimSuffix(base, "/") + "/" + url.PathEscape(storeID)
+}
@@ -0,0 +1,78 @@
+package htm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/feed"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestComposeFeed(t *testing.T) {
+	assert := assert.New(t)
+
+	store := pipeline.NewMemoryStore()
+	store.Put("img.png", "image/png", []byte("PNG"))
+	store.Put("main.jpg", "image/jpeg", []byte("JPG"))
+
+	pub := time.Date(2021, 8, 9, 8, 0, 0, 0, time.UTC)
+	task := &pipeline.Task{
+		URL:          "https://example.com/article?utm_source=x",
+		ActualURL:    "https://example.com/article",
+		CanonicalURL: "https://example.com/article",
+		Title:        "The Article",
+		Description:  "About <things> & Tom's",
+		Author:       "Jane Doe",
+		PubDate:      &pub,
+		Keywords:     []string{"news"},
+		ImageURL:     pipeline.StoreURL("main.jpg"),
+		Images: []pipeline.ImageInfo{
+			{Key: "main.jpg", ContentURL: pipeline.StoreURL("main.jpg"), OriginalURL: "https://example.com/main.jpg"},
+		},
+		Enclosures: []pipeline.Enclosure{
+			{Type: "Audio", URL: "https://example.com/a.mp3", ContentType: "audio/mpeg", Size: 100, Key: "a.mp3"},
+		},
+		Store: store,
+	}
+	task.SetHTML(`<html><body><p>Text</p><img src="store://img.png"/></body></html>`)
+
+	// inline images
+	var b strings.Builder
+	err := ComposeFeed(&b, []*pipeline.Task{task}, FeedOptions{Format: "json", Title: "Reading List"})
+	assert.Nil(err)
+	// plain text in JSON Feed
+	assert.Contains(b.String(), `"summary": "About <things> & Tom's"`)
+
+	f, err := feed.Parse(strings.NewReader(b.String()), "")
+	assert.Nil(err)
+	assert.Equal("Reading List", f.Title)
+	assert.Equal(1, len(f.Entries))
+	e := f.Entries[0]
+	assert.Equal("https://example.com/article", e.ID)
+	assert.Equal("The Article", e.Title)
+	assert.Equal(`<p>Text</p><img src="data:image/png;base64,UE5H"/>`, e.Content)
+	assert.Equal("About &lt;things&gt; &amp; Tom&#39;s", e.Summary)
+	assert.Equal([]feed.Person{{Name: "Jane Doe"}}, e.Authors)
+	assert.Equal(pub, *e.Published)
+	assert.Equal("https://example.com/main.jpg", e.Image)
+	assert.Equal("https://example.com/a.mp3", e.Enclosures[0].URL)
+
+	// referenced with a base URL
+	b.Reset()
+	err = ComposeFeed(&b, []*pipeline.Task{task}, FeedOptions{
+		Format:       "atom",
+		ImageBaseURL: "https://files.example.com/store/",
+	})
+	assert.Nil(err)
+
+	f, err = feed.Parse(strings.NewReader(b.String()), "")
+	assert.Nil(err)
+	e = f.Entries[0]
+	assert.Equal(`<p>Text</p><img src="https://files.example.com/store/img.png"/>`, e.Content)
+	assert.Equal("https://files.example.com/store/main.jpg", e.Image)
+	assert.Equal("https://files.example.com/store/a.mp3", e.Enclosures[0].URL)
+	assert.Equal(int64(100), e.Enclosures[0].Length)
+}
@@ -21,7 +21,14 @@ var style string
 func Compose(w io.Writer, t *pipeline.Task) error {
 	var b strings.Builder
 
-	b.WriteString("<html>")
+	b.WriteString("<html")
+	if t.Language != "" {
+		b.WriteString(fmt.Sprintf(" lang=\"%v\"", html.EscapeString(t.Language)))
+	}
+	if t.Direction != "" {
+		b.WriteString(fmt.Sprintf(" dir=\"%v\"", html.EscapeString(t.Direction)))
+	}
+	b.WriteString(">")
 	writeHead(&b, t)
 	err := writeBody(&b, t)
 	if err != nil {
@@ -67,7 +74,7 @@ func writeMetadata(b *strings.Builder, t *pipeline.Task) error {
 	if t.ImageURL != "" {
 		attr := []html.Attribute{html.Attribute{Key: "src", Val: t.ImageURL}}
 		b.WriteString("<img ")
-		dataImage(attr, t, b)
+		writeImageAttrs(attr, dataURL(t), b)
 		b.WriteString("/>")
 	}
 
@@ -97,6 +104,17 @@ func writeMetadata(b *strings.Builder, t *pipeline.Task) error {
 		b.WriteString("</p>")
 	}
 
+	if t.ModifiedDate != nil {
+		b.WriteString("<p>")
+		b.WriteString("Updated ")
+		b.WriteString("<time datetime=\"")
+		b.WriteString(t.ModifiedDate.Format(time.RFC3339))
+		b.WriteString("\">")
+		b.WriteString(t.ModifiedDate.Local().Format(time.ANSIC))
+		b.WriteString("</time>")
+		b.WriteString("</p>")
+	}
+
 	if t.Site != "" {
 		b.WriteString("<p><a href=\"")
 		b.WriteString(t.SiteScheme)
@@ -125,6 +143,15 @@ func writeMetadata(b *strings.Builder, t *pipeline.Task) error {
 }
 
 func writeContent(b *strings.Builder, t *pipeline.Task) error {
+	return writeImages(b, t.HTML(), dataURL(t))
+}
+
+// srcFunc returns the src for an image from the store.
+type srcFunc func(storeID string) (string, error)
+
+// writeImages writes the given HTML and replaces the src of images from the
+// store.
+func writeImages(b *strings.Builder, s string, src srcFunc) error {
 	handler := func(tk html.Token, w io.StringWriter) (bool, error) {
 		if tk.DataAtom != atom.Img {
 			return false, nil
@@ -136,7 +163,7 @@ func writeContent(b *strings.Builder, t *pipeline.Task) error {
 		case html.StartTagToken:
 			w.WriteString("<")
 			w.WriteString(tk.Data)
-			err = dataImage(tk.Attr, t, w)
+			err = writeImageAttrs(tk.Attr, src, w)
 			if err != nil {
 				return false, err
 			}
@@ -145,7 +172,7 @@ func writeContent(b *strings.Builder, t *pipeline.Task) error {
 		case html.SelfClosingTagToken:
 			w.WriteString("<")
 			w.WriteString(tk.Data)
-			err = dataImage(tk.Attr, t, w)
+			err = writeImageAttrs(tk.Attr, src, w)
 			if err != nil {
 				return false, err
 			}
@@ -156,24 +183,18 @@ func writeContent(b *strings.Builder, t *pipeline.Task) error {
 		return false, nil
 	}
 
-	return pipeline.WalkHTML(b, t.HTML(), handler)
+	return pipeline.WalkHTML(b, s, handler)
 }
 
-func dataImage(a []html.Attribute, t *pipeline.Task, w io.StringWriter) error {
+func writeImageAttrs(a []html.Attribute, src srcFunc, w io.StringWriter) error {
 	for _, attr := range a {
 		if attr.Key == "src" {
 			storeID := pipeline.ParseStoreID(attr.Val)
 			if storeID != "" {
-				contentType, data, err := t.GetAsset(storeID)
+				v, err := src(storeID)
 				if err != nil {
 					return err
 				}
-				enc := base64.StdEncoding.EncodeToString(data)
-				v := "data:"
-				// TODO: contentType
-				v += contentType + ";"
-				v += "base64,"
-				v += enc
 				pipeline.WriteAttr(html.Attribute{
 					Key: "src",
 					Val: v,
@@ -189,6 +210,23 @@ func dataImage(a []html.Attribute, t *pipeline.Task, w io.StringWriter) error {
 	return nil
 }
 
+// dataURL inlines images from the store as data URLs.
+func dataURL(t *pipeline.Task) srcFunc {
+	return func(storeID string) (string, error) {
+		contentType, data, err := t.GetAsset(storeID)
+		if err != nil {
+			return "", err
+		}
+		enc := base64.StdEncoding.EncodeToString(data)
+		v := "data:"
+		// TODO: contentType
+		v += contentType + ";"
+		v += "base64,"
+		v += enc
+		return v, nil
+	}
+}
+
 func writeFooter(b *strings.Builder, t *pipeline.Task) {
 	b.WriteString("<footer>")
 	b.WriteString("<p>")
@@ -215,12 +253,55 @@ func writeFooter(b *strings.Builder, t *pipeline.Task) {
 
 	b.WriteString("</p>")
 
+	writeLicense(b, t)
 	writeFeeds(b, t)
 	writeEnclosures(b, t)
 
 	b.WriteString("</footer>")
 }
 
+func writeLicense(b *strings.Builder, t *pipeline.Task) {
+	l := t.License
+	if l == nil {
+		return
+	}
+
+	b.WriteString("<p>")
+	hasLicense := l.Name != "" || l.URL != ""
+	if hasLicense {
+		b.WriteString("License: ")
+		name := l.Name
+		if name == "" {
+			name = l.URL
+		}
+		if l.URL != "" {
+			b.WriteString("<a rel=\"license\" href=\"")
+			b.WriteString(html.EscapeString(l.URL))
+			b.WriteString("\">")
+			b.WriteString(html.EscapeString(name))
+			b.WriteString("</a>")
+		} else {
+			b.WriteString(html.EscapeString(name))
+		}
+	}
+
+	if l.Holder != "" || l.Year != "" {
+		if hasLicense {
+			b.WriteString(" | ")
+		}
+		b.WriteString("&copy;")
+		if l.Year != "" {
+			b.WriteString(" ")
+			b.WriteString(html.EscapeString(l.Year))
+		}
+		if l.Holder != "" {
+			b.WriteString(" ")
+			b.WriteString(html.EscapeString(l.Holder))
+		}
+	}
+	b.WriteString("</p>")
+}
+
 func writeFeeds(b *strings.Builder, t *pipeline.Task) {
 	if len(t.Feeds) == 0 {
 		return
@@ -252,16 +333,49 @@ func writeEnclosures(b *strings.Builder, t *pipeline.Task) {
 	for _, enc := range t.Enclosures {
 		b.WriteString("<li>")
 		switch enc.Type {
-		case "Audio":
+		case pipeline.EnclosureAudio:
 			b.WriteString(fmt.Sprintf("<audio controls src=%q type=%q></audio>", enc.URL, enc.ContentType))
-			b.WriteString("<br/>")
-			b.WriteString(fmt.Sprintf("<strong>%v</strong>", enc.Title))
+		case pipeline.EnclosureVideo:
+			b.WriteString(fmt.Sprintf("<video controls src=%q type=%q", enc.URL, enc.ContentType))
+			if enc.Image != "" {
+				b.WriteString(fmt.Sprintf(" poster=%q", enc.Image))
+			}
+			b.WriteString("></video>")
+		default:
+			b.WriteString(fmt.Sprintf("<a href=%q>%v</a>", enc.URL, html.EscapeString(enc.URL)))
+		}
+		b.WriteString("<br/>")
+		if enc.Title != "" {
+			b.WriteString(fmt.Sprintf("<strong>%v</strong>", html.EscapeString(enc.Title)))
+		}
+		if info := enclosureInfo(enc); info != "" {
+			b.WriteString(" (" + info + ")")
+		}
+		if enc.Description != "" {
 			b.WriteString("<br/>")
 			b.WriteString(enc.Description)
-			break
 		}
 		b.WriteString("</li>")
 	}
 
 	b.WriteString("</ul>")
 }
+
+// enclosureInfo formats the duration and size of an enclosure,
+// e.g. "1:02:03, 56.5 MB".
+func enclosureInfo(enc pipeline.Enclosure) string {
+	parts := make([]string, 0)
+	if enc.Duration > 0 {
+		d := enc.Duration.Round(time.Second)
+		h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+		if h > 0 {
+			parts = append(parts, fmt.Sprintf("%d:%02d:%02d", h, m, s))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d:%02d", m, s))
+		}
+	}
+	if enc.Size > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f MB", float64(enc.Size)/1e6))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,391 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+)
+
+// Graph holds all JSON-LD nodes from a document.
+//
+// see: https://json-ld.org/ and https://schema.org/
+type Graph struct {
+	// top-level nodes, in document order
+	nodes []*Node
+	// all nodes, including nested ones
+	all []*Node
+	ids map[string]*Node
+}
+
+// Node is a single JSON-LD object.
+type Node struct {
+	data  map[string]interface{}
+	graph *Graph
+}
+
+// Parse reads all `<script type="application/ld+json">` elements from the
+// document.
+//
+// Supports a single object, a top-level array and objects with a `@graph`.
+// Scripts with invalid JSON are skipped.
+func Parse(doc *goquery.Document) *Graph {
+	g := New()
+	if doc == nil {
+		return g
+	}
+
+	doc.Selection.Find("script").Each(func(i int, s *goquery.Selection) {
+		tp, _ := s.Attr("type")
+		if strings.ToLower(strings.TrimSpace(tp)) != "application/ld+json" {
+			return
+		}
+
+		var data interface{}
+		err := json.Unmarshal([]byte(cleanScript(s.Text())), &data)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"module": "jsonld",
+				"error":  err,
+			}).Warning("Failed to parse JSON-LD")
+			return
+		}
+
+		g.Add(data)
+	})
+
+	return g
+}
+
+// ParseString reads JSON-LD from a string, e.g. for testing.
+func ParseString(s string) (*Graph, error) {
+	g := New()
+
+	var data interface{}
+	err := json.Unmarshal([]byte(cleanScript(s)), &data)
+	if err != nil {
+		return nil, err
+	}
+	g.Add(data)
+	return g, nil
+}
+
+// New creates an empty graph.
+func New() *Graph {
+	return &Graph{
+		nodes: make([]*Node, 0),
+		all:   make([]*Node, 0),
+		ids:   make(map[string]*Node),
+	}
+}
+
+// some sites wrap the script content in comments or CDATA
+func cleanScript(s string) string {
+	s = strings.TrimSpace(s)
+	for _, prefix := range []string{"<!--", "//<![CDATA[", "<![CDATA["} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	for _, suffix := range []string{"-->", "//]]>", "]]>"} {
+		s = strings.TrimSuffix(s, suffix)
+	}
+	return strings.TrimSpace(s)
+}
+
+// Add adds decoded JSON-LD data to the graph.
+// The data is expected in the form produced by `json.Unmarshal`, i.e. a
+// `map[string]interface{}` or a `[]interface{}`.
+func (g *Graph) Add(data interface{}) {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			g.Add(item)
+		}
+	case map[string]interface{}:
+		if graph, ok := v["@graph"]; ok {
+			g.Add(graph)
+			// the wrapper itself may also describe something
+			if _, ok := v["@type"]; !ok {
+				return
+			}
+		}
+		n := g.index(v)
+		g.nodes = append(g.nodes, n)
+	}
+}
+
+// index adds the object and all nested objects to the list of all nodes.
+func (g *Graph) index(m map[string]interface{}) *Node {
+	n := &Node{data: m, graph: g}
+
+	// do not replace a complete node with a reference to it
+	id, _ := m["@id"].(string)
+	if id != "" {
+		existing, ok := g.ids[id]
+		if !ok || len(existing.data) < len(m) {
+			g.ids[id] = n
+		}
+	}
+	if !isReference(m) {
+		g.all = append(g.all, n)
+	}
+
+	for k, v := range m {
+		if k == "@graph" {
+			continue
+		}
+		g.indexValue(v)
+	}
+	return n
+}
+
+func (g *Graph) indexValue(v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		g.index(val)
+	case []interface{}:
+		for _, item := range val {
+			g.indexValue(item)
+		}
+	}
+}
+
+// Nodes returns the top-level nodes.
+func (g *Graph) Nodes() []*Node {
+	return g.nodes
+}
+
+// Find returns all nodes (including nested ones) that have one of the given
+// types. Top-level nodes come first.
+func (g *Graph) Find(types ...string) []*Node {
+	result := make([]*Node, 0)
+	seen := make(map[*Node]bool)
+	for _, list := range [][]*Node{g.nodes, g.all} {
+		for _, n := range list {
+			if seen[n] || isReference(n.data) {
+				continue
+			}
+			if n.Is(types...) {
+				seen[n] = true
+				result = append(result, n)
+			}
+		}
+	}
+	return result
+}
+
+// First returns the first node with one of the given types, or nil.
+func (g *Graph) First(types ...string) *Node {
+	found := g.Find(types...)
+	if len(found) == 0 {
+		return nil
+	}
+	return found[0]
+}
+
+// ByID returns the node with the given `@id`, or nil.
+func (g *Graph) ByID(id string) *Node {
+	return g.ids[id]
+}
+
+// isReference tells if the object is only a reference to another node,
+// e.g. {"@id": "https://example.com/#org"}
+func isReference(m map[string]interface{}) bool {
+	if _, ok := m["@id"]; !ok {
+		return false
+	}
+	for k := range m {
+		if k != "@id" && k != "@type" {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve returns the full node if this node is a reference.
+func (n *Node) resolve() *Node {
+	if n == nil || n.graph == nil || !isReference(n.data) {
+		return n
+	}
+	id, _ := n.data["@id"].(string)
+	full, ok := n.graph.ids[id]
+	if !ok || full == n {
+		return n
+	}
+	return full
+}
+
+// ID returns the `@id` of the node.
+func (n *Node) ID() string {
+	id, _ := n.data["@id"].(string)
+	return id
+}
+
+// Types returns the `@type` of the node.
+// A type can be a single string or a list of strings.
+// Prefixes like "schema:" or "https://schema.org/" are removed.
+func (n *Node) Types() []string {
+	types := make([]string, 0)
+	for _, v := range values(n.data["@type"]) {
+		if s, ok := v.(string); ok {
+			types = append(types, stripVocab(s))
+		}
+	}
+	return types
+}
+
+// Is tells if the node has at least one of the given types.
+func (n *Node) Is(types ...string) bool {
+	for _, have := range n.Types() {
+		for _, want := range types {
+			if strings.EqualFold(have, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Has tells if the node has a value for the given key.
+func (n *Node) Has(key string) bool {
+	_, ok := n.data[key]
+	return ok
+}
+
+// Value returns the raw value for the given key.
+func (n *Node) Value(key string) interface{} {
+	return n.data[key]
+}
+
+// String returns the first string value for the given key.
+// Value objects like {"@value": "..."} are supported.
+func (n *Node) String(key string) string {
+	s := n.Strings(key)
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0]
+}
+
+// Strings returns all string values for the given key.
+func (n *Node) Strings(key string) []string {
+	result := make([]string, 0)
+	for _, v := range values(n.data[key]) {
+		switch val := v.(type) {
+		case string:
+			val = strings.TrimSpace(val)
+			if val != "" {
+				result = append(result, val)
+			}
+		case float64:
+			result = append(result, strconv.FormatFloat(val, 'f', -1, 64))
+		case map[string]interface{}:
+			if s, ok := val["@value"].(string); ok && strings.TrimSpace(s) != "" {
+				result = append(result, strings.TrimSpace(s))
+			}
+		}
+	}
+	return result
+}
+
+// Node returns the first object for the given key.
+// References to other nodes are resolved.
+func (n *Node) Node(key string) *Node {
+	nodes := n.Nodes(key)
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
+}
+
+// Nodes returns all objects for the given key.
+// References to other nodes are resolved, including plain strings that
+// are used as an `@id`.
+func (n *Node) Nodes(key string) []*Node {
+	result := make([]*Node, 0)
+	for _, v := range values(n.data[key]) {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			if _, ok := val["@value"]; ok {
+				continue
+			}
+			node := &Node{data: val, graph: n.graph}
+			result = append(result, node.resolve())
+		case string:
+			if n.graph != nil {
+				if ref, ok := n.graph.ids[val]; ok {
+					result = append(result, ref)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// URL returns a URL for the given key.
+// The value may be a plain string or an object with a "url" or "contentUrl",
+// like an ImageObject.
+func (n *Node) URL(key string) string {
+	urls := n.URLs(key)
+	if len(urls) == 0 {
+		return ""
+	}
+	return urls[0]
+}
+
+// URLs returns all URLs for the given key, see URL.
+func (n *Node) URLs(key string) []string {
+	result := make([]string, 0)
+	for _, v := range values(n.data[key]) {
+		switch val := v.(type) {
+		case string:
+			val = strings.TrimSpace(val)
+			if val == "" {
+				continue
+			}
+			// might be a reference to an ImageObject
+			if n.graph != nil {
+				if ref, ok := n.graph.ids[val]; ok && ref.Has("url") {
+					result = append(result, ref.String("url"))
+					continue
+				}
+			}
+			result = append(result, val)
+		case map[string]interface{}:
+			node := (&Node{data: val, graph: n.graph}).resolve()
+			for _, k := range []string{"url", "contentUrl"} {
+				u := node.String(k)
+				if u != "" {
+					result = append(result, u)
+					break
+				}
+			}
+		}
+	}
+	return result
+}
+
+// values returns a single value as a list.
+func values(v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{v}
+}
+
+var vocabPrefixes = []string{
+	"http://schema.org/",
+	"https://schema.org/",
+	"schema:",
+}
+
+func stripVocab(s string) string {
+	for _, prefix := range vocabPrefixes {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	return s
+}
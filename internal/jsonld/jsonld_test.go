@@ -0,0 +1,100 @@
+package jsonld
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><head>
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@graph": [
+			{
+				"@type": "Organization",
+				"@id": "https://example.com/#organization",
+				"name": "Example Org",
+				"logo": {"@type": "ImageObject", "url": "https://example.com/logo.png"}
+			},
+			{
+				"@type": ["NewsArticle", "schema:Article"],
+				"@id": "https://example.com/article#article",
+				"headline": "The Headline",
+				"author": [
+					{"@id": "https://example.com/#author"},
+					{"@type": "Person", "name": "Second Author"}
+				],
+				"publisher": {"@id": "https://example.com/#organization"},
+				"image": {"@id": "https://example.com/#image"}
+			},
+			{
+				"@type": "Person",
+				"@id": "https://example.com/#author",
+				"name": "First Author"
+			},
+			{
+				"@type": "ImageObject",
+				"@id": "https://example.com/#image",
+				"url": "https://example.com/image.jpg"
+			}
+		]
+	}
+	</script>
+	<script type="application/ld+json">
+	[{"@type": "BreadcrumbList"}, {"@type": "WebSite", "name": "Example"}]
+	</script>
+	<script type="application/ld+json">{ invalid }</script>
+	</head><body></body></html>`
+
+	d, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	g := Parse(d)
+
+	assert.Equal(6, len(g.Nodes()))
+
+	a := g.First("Article")
+	assert.NotNil(a)
+	assert.Equal([]string{"NewsArticle", "Article"}, a.Types())
+	assert.Equal("The Headline", a.String("headline"))
+
+	// resolve references
+	authors := a.Nodes("author")
+	assert.Equal(2, len(authors))
+	assert.Equal("First Author", authors[0].String("name"))
+	assert.Equal("Second Author", authors[1].String("name"))
+
+	pub := a.Node("publisher")
+	assert.Equal("Example Org", pub.String("name"))
+	assert.Equal("https://example.com/logo.png", pub.URL("logo"))
+	assert.Equal("https://example.com/image.jpg", a.URL("image"))
+
+	// nested nodes can be found
+	assert.Equal(2, len(g.Find("ImageObject")))
+	assert.Nil(g.First("Recipe"))
+	assert.Equal("Example", g.First("WebSite").String("name"))
+}
+
+func TestValues(t *testing.T) {
+	assert := assert.New(t)
+
+	g, err := ParseString(`<!--{
+		"@type": "Thing",
+		"name": {"@value": "Value Object"},
+		"keywords": ["a", "b", " "],
+		"year": 2021,
+		"image": ["https://example.com/a.jpg", {"url": "https://example.com/b.jpg"}]
+	}-->`)
+	assert.Nil(err)
+
+	n := g.First("Thing")
+	assert.Equal("Value Object", n.String("name"))
+	assert.Equal([]string{"a", "b"}, n.Strings("keywords"))
+	assert.Equal("2021", n.String("year"))
+	assert.Equal([]string{"https://example.com/a.jpg", "https://example.com/b.jpg"}, n.URLs("image"))
+	assert.Equal("", n.String("missing"))
+}
@@ -0,0 +1,224 @@
+package lang
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+// Normalize parses a language tag like "de_DE", "en-us" or "de, en" and
+// returns it in canonical form, e.g. "de-DE".
+// Returns an empty string if the value is not a valid tag.
+func Normalize(s string) string {
+	// Content-Language may hold a list
+	s = strings.TrimSpace(strings.Split(s, ",")[0])
+	if s == "" {
+		return ""
+	}
+
+	tag, err := language.Parse(s)
+	if err != nil || tag == language.Und {
+		return ""
+	}
+	return tag.String()
+}
+
+// scripts which are written right-to-left
+var rtlScripts = map[string]bool{
+	"Arab": true,
+	"Hebr": true,
+	"Thaa": true,
+	"Syrc": true,
+	"Nkoo": true,
+	"Adlm": true,
+	"Rohg": true,
+}
+
+// Direction returns the text direction, "ltr" or "rtl", for the given
+// language tag. Returns an empty string if the tag is not valid.
+func Direction(tag string) string {
+	t, err := language.Parse(tag)
+	if err != nil || t == language.Und {
+		return ""
+	}
+	script, _ := t.Script()
+	if rtlScripts[script.String()] {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// minimum number of letters for detection
+const minLetters = 40
+
+// only the beginning of long texts is evaluated
+const maxRunes = 10000
+
+// Detect guesses the language of the given text.
+//
+// Languages with their own script are detected by their script, languages
+// with latin script are compared with trigram profiles. Returns the ISO 639-1
+// code or an empty string if the language is not known.
+func Detect(text string) string {
+	runes := []rune(text)
+	if len(runes) > maxRunes {
+		runes = runes[:maxRunes]
+	}
+	text = string(runes)
+
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range runes {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scriptOrder {
+			if unicode.Is(scriptTables[s], r) {
+				counts[s]++
+				break
+			}
+		}
+	}
+	if letters < minLetters {
+		return ""
+	}
+
+	// japanese mixes kana and han
+	if counts["Hiragana"]+counts["Katakana"] > letters/10 {
+		return "ja"
+	}
+
+	for _, s := range scriptOrder {
+		if s == "Latin" || counts[s]*2 < letters {
+			continue
+		}
+		return scriptLanguage(s, text)
+	}
+
+	if counts["Latin"]*2 < letters {
+		return ""
+	}
+	return detectLatin(text)
+}
+
+var scriptOrder = []string{
+	"Latin",
+	"Arabic",
+	"Hebrew",
+	"Cyrillic",
+	"Greek",
+	"Hangul",
+	"Hiragana",
+	"Katakana",
+	"Han",
+	"Thai",
+	"Devanagari",
+}
+
+var scriptTables = map[string]*unicode.RangeTable{
+	"Latin":      unicode.Latin,
+	"Arabic":     unicode.Arabic,
+	"Hebrew":     unicode.Hebrew,
+	"Cyrillic":   unicode.Cyrillic,
+	"Greek":      unicode.Greek,
+	"Hangul":     unicode.Hangul,
+	"Hiragana":   unicode.Hiragana,
+	"Katakana":   unicode.Katakana,
+	"Han":        unicode.Han,
+	"Thai":       unicode.Thai,
+	"Devanagari": unicode.Devanagari,
+}
+
+// scriptLanguage determines the language for a text in the given script.
+// Some scripts are used by several languages, these are told apart by
+// characters that are specific to one language.
+func scriptLanguage(script, text string) string {
+	switch script {
+	case "Arabic":
+		if strings.ContainsAny(text, "ٹڈڑںے") {
+			return "ur"
+		}
+		if strings.ContainsAny(text, "پچژگ") {
+			return "fa"
+		}
+		return "ar"
+	case "Hebrew":
+		return "he"
+	case "Cyrillic":
+		if strings.ContainsAny(text, "іїєґІЇЄҐ") {
+			return "uk"
+		}
+		return "ru"
+	case "Greek":
+		return "el"
+	case "Hangul":
+		return "ko"
+	case "Hiragana", "Katakana":
+		return "ja"
+	case "Han":
+		return "zh"
+	case "Thai":
+		return "th"
+	case "Devanagari":
+		return "hi"
+	}
+	return ""
+}
+
+// the best match must be at least this similar
+const minSimilarity = 0.1
+
+func detectLatin(text string) string {
+	p := newProfile(text)
+
+	best := ""
+	score := minSimilarity
+	for code, other := range latinProfiles() {
+		s := p.similarity(other)
+		if s > score {
+			best = code
+			score = s
+		}
+	}
+	return best
+}
+
+// profile holds the relative frequencies of character trigrams.
+type profile map[string]float64
+
+func newProfile(text string) profile {
+	p := make(profile)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		w := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(w); i++ {
+			p[string(w[i:i+3])]++
+		}
+	}
+
+	// normalize to unit length for cosine similarity
+	var sum float64
+	for _, v := range p {
+		sum += v * v
+	}
+	norm := math.Sqrt(sum)
+	for k, v := range p {
+		p[k] = v / norm
+	}
+	return p
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r)
+}
+
+// similarity is the cosine similarity of two profiles.
+func (p profile) similarity(other profile) float64 {
+	var s float64
+	for k, v := range p {
+		s += v * other[k]
+	}
+	return s
+}
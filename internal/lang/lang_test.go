@@ -0,0 +1,52 @@
+package lang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("de-DE", Normalize("de_DE"))
+	assert.Equal("en-US", Normalize(" en-us "))
+	assert.Equal("de", Normalize("de, en"))
+	assert.Equal("", Normalize(""))
+	assert.Equal("", Normalize("not a language"))
+}
+
+func TestDirection(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("ltr", Direction("en"))
+	assert.Equal("ltr", Direction("de-DE"))
+	assert.Equal("rtl", Direction("ar"))
+	assert.Equal("rtl", Direction("he-IL"))
+	assert.Equal("rtl", Direction("fa"))
+	assert.Equal("", Direction(""))
+}
+
+func TestDetect(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]string{
+		"en": "Scientists have discovered a new species of frog in the rainforest of Peru. The small animal lives high in the trees and was found by a team from the university.",
+		"de": "Wissenschaftler haben im Regenwald von Peru eine neue Froschart entdeckt. Das kleine Tier lebt hoch oben in den Bäumen und wurde von einem Team der Universität gefunden.",
+		"fr": "Des scientifiques ont découvert une nouvelle espèce de grenouille dans la forêt tropicale du Pérou. Le petit animal vit en haut des arbres.",
+		"es": "Científicos han descubierto una nueva especie de rana en la selva de Perú. El pequeño animal vive en lo alto de los árboles y fue encontrado por un equipo.",
+		"it": "Gli scienziati hanno scoperto una nuova specie di rana nella foresta pluviale del Perù. Il piccolo animale vive in alto sugli alberi.",
+		"nl": "Wetenschappers hebben in het regenwoud van Peru een nieuwe kikkersoort ontdekt. Het kleine dier leeft hoog in de bomen en werd gevonden door een team.",
+		"pt": "Cientistas descobriram uma nova espécie de sapo na floresta tropical do Peru. O pequeno animal vive no alto das árvores e foi encontrado por uma equipe.",
+		"ru": "Учёные обнаружили новый вид лягушек в тропических лесах Перу. Маленькое животное живёт высоко на деревьях.",
+		"ar": "اكتشف العلماء نوعا جديدا من الضفادع في الغابات المطيرة في بيرو. يعيش الحيوان الصغير عاليا في الأشجار",
+		"ja": "科学者たちはペルーの熱帯雨林で新種のカエルを発見しました。この小さな動物は木の高いところに住んでいます。",
+	}
+
+	for want, text := range cases {
+		assert.Equal(want, Detect(text), text)
+	}
+
+	// too short
+	assert.Equal("", Detect("Hello World"))
+}
@@ -0,0 +1,109 @@
+package lang
+
+import "sync"
+
+// Sample texts for languages with latin script.
+//
+// The trigram profiles are created from these texts. They consist mostly of
+// common words, which is what matters for telling languages apart.
+var samples = map[string]string{
+	"en": `The government said on Monday that it would not change the plan,
+	but many people in the country believe that the new rules are not fair.
+	There have been a lot of questions about how the money will be spent and
+	who is going to pay for it. When the minister was asked about this, she
+	said that they were still working on the details and that more
+	information would be available in the next few weeks. Some of the
+	experts who have looked at the proposal think it could help families
+	with children, while others are worried that it will only make things
+	more difficult for those who already have very little. What is clear is
+	that this is something everyone should be thinking about, because it
+	affects all of us and the way we live and work together.`,
+
+	"de": `Die Bundesregierung hat am Montag erklärt, dass sie an dem Plan
+	festhalten will, doch viele Menschen im Land halten die neuen Regeln
+	nicht für gerecht. Es gibt noch viele Fragen dazu, wie das Geld
+	ausgegeben werden soll und wer am Ende dafür bezahlen muss. Als die
+	Ministerin danach gefragt wurde, sagte sie, man arbeite noch an den
+	Einzelheiten und weitere Informationen würden in den nächsten Wochen
+	folgen. Einige Fachleute, die sich den Vorschlag angesehen haben,
+	glauben, dass er Familien mit Kindern helfen könnte, während andere
+	befürchten, dass es für diejenigen, die ohnehin wenig haben, nur noch
+	schwieriger wird. Klar ist aber, dass sich jeder mit diesem Thema
+	beschäftigen sollte, weil es uns alle betrifft und die Art und Weise,
+	wie wir zusammen leben und arbeiten.`,
+
+	"fr": `Le gouvernement a déclaré lundi qu'il ne changerait pas le projet,
+	mais beaucoup de gens dans le pays pensent que les nouvelles règles ne
+	sont pas justes. Il y a encore de nombreuses questions sur la manière
+	dont l'argent sera dépensé et sur ceux qui vont payer pour cela. Quand
+	on a interrogé la ministre à ce sujet, elle a dit qu'ils travaillaient
+	encore sur les détails et que des informations supplémentaires seraient
+	disponibles dans les prochaines semaines. Certains experts qui ont
+	examiné la proposition pensent qu'elle pourrait aider les familles avec
+	des enfants, tandis que d'autres craignent qu'elle ne rende les choses
+	encore plus difficiles pour ceux qui ont déjà très peu. Ce qui est
+	certain, c'est que tout le monde devrait y réfléchir, parce que cela
+	nous concerne tous ainsi que notre façon de vivre et de travailler
+	ensemble.`,
+
+	"es": `El gobierno dijo el lunes que no cambiaría el plan, pero muchas
+	personas en el país creen que las nuevas reglas no son justas. Todavía
+	hay muchas preguntas sobre cómo se va a gastar el dinero y quién lo va a
+	pagar. Cuando le preguntaron a la ministra sobre esto, dijo que todavía
+	estaban trabajando en los detalles y que habría más información en las
+	próximas semanas. Algunos expertos que han estudiado la propuesta creen
+	que podría ayudar a las familias con hijos, mientras que otros temen
+	que solo haga las cosas más difíciles para quienes ya tienen muy poco.
+	Lo que está claro es que todos deberíamos pensar en ello, porque nos
+	afecta a todos y a la forma en que vivimos y trabajamos juntos.`,
+
+	"it": `Il governo ha detto lunedì che non cambierà il piano, ma molte
+	persone nel paese pensano che le nuove regole non siano giuste. Ci sono
+	ancora molte domande su come verranno spesi i soldi e su chi dovrà
+	pagare. Quando è stato chiesto alla ministra, ha detto che stanno ancora
+	lavorando sui dettagli e che nelle prossime settimane ci saranno più
+	informazioni. Alcuni esperti che hanno esaminato la proposta pensano
+	che potrebbe aiutare le famiglie con bambini, mentre altri temono che
+	renderà le cose ancora più difficili per chi ha già molto poco. Quello
+	che è chiaro è che tutti dovrebbero pensarci, perché riguarda tutti noi
+	e il modo in cui viviamo e lavoriamo insieme.`,
+
+	"nl": `De regering heeft maandag gezegd dat zij het plan niet zal
+	veranderen, maar veel mensen in het land vinden de nieuwe regels niet
+	eerlijk. Er zijn nog veel vragen over hoe het geld wordt uitgegeven en
+	wie ervoor gaat betalen. Toen de minister daarnaar werd gevraagd, zei ze
+	dat ze nog aan de details werken en dat er in de komende weken meer
+	informatie zal komen. Sommige deskundigen die het voorstel hebben
+	bekeken, denken dat het gezinnen met kinderen kan helpen, terwijl
+	anderen vrezen dat het de dingen alleen maar moeilijker maakt voor
+	mensen die al heel weinig hebben. Wat duidelijk is, is dat iedereen
+	erover zou moeten nadenken, omdat het ons allemaal raakt en de manier
+	waarop we samen leven en werken.`,
+
+	"pt": `O governo disse na segunda-feira que não vai mudar o plano, mas
+	muitas pessoas no país acham que as novas regras não são justas. Ainda
+	há muitas perguntas sobre como o dinheiro vai ser gasto e quem vai pagar
+	por isso. Quando a ministra foi questionada sobre o assunto, disse que
+	ainda estão a trabalhar nos detalhes e que haverá mais informações nas
+	próximas semanas. Alguns especialistas que analisaram a proposta acham
+	que ela pode ajudar as famílias com filhos, enquanto outros temem que
+	apenas torne as coisas mais difíceis para quem já tem muito pouco. O que
+	é claro é que todos deveriam pensar nisso, porque afeta a todos nós e a
+	forma como vivemos e trabalhamos juntos.`,
+}
+
+var (
+	profiles     map[string]profile
+	profilesOnce sync.Once
+)
+
+// latinProfiles returns the trigram profiles, these are created on first use.
+func latinProfiles() map[string]profile {
+	profilesOnce.Do(func() {
+		profiles = make(map[string]profile)
+		for code, text := range samples {
+			profiles[code] = newProfile(text)
+		}
+	})
+	return profiles
+}
@@ -0,0 +1,264 @@
+package metadata
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// Roles for people related to the content.
+const (
+	roleAuthor      = "author"
+	roleEditor      = "editor"
+	roleContributor = "contributor"
+)
+
+// addAuthors adds authors from the given source.
+// Authors without a name and a URL are skipped.
+func (m *metadata) addAuthors(key string, authors ...pipeline.Author) {
+	for _, a := range authors {
+		a.Name = cleanAuthor(a.Name)
+		if a.Name == "" && a.URL == "" {
+			continue
+		}
+		if a.Role == "" {
+			a.Role = roleAuthor
+		}
+		m.authors[key] = append(m.authors[key], a)
+	}
+}
+
+// findAuthorLinks reads `rel=author` links.
+//
+// Usually, `<a>` elements link to the author's page and hold the name,
+// `<link>` elements only have the URL.
+func findAuthorLinks(m *metadata, doc *goquery.Document) {
+	doc.Find("a[rel~=author], link[rel~=author]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		a := pipeline.Author{URL: strings.TrimSpace(href)}
+		if goquery.NodeName(s) == "a" {
+			a.Name = s.Text()
+		}
+		m.addAuthors("rel/author", a)
+	})
+}
+
+var authorPath = regexp.MustCompile(`(?i)/(author|authors|autor|autoren|people|person|profile|profil|staff|team|writers?|contributors?|journalists?)/`)
+
+var bylineClass = regexp.MustCompile(`(?i)(^|[\s_-])(byline|by-line|author|authors|autor|writer)([\s_-]|$)`)
+
+// findBylines looks for the byline near the main headline,
+// e.g. `<p class="byline">By <a href="/authors/jane">Jane Doe</a></p>`.
+func findBylines(m *metadata, doc *goquery.Document) {
+	h := doc.Find("article h1").First()
+	if h.Length() == 0 {
+		h = doc.Find("h1").First()
+	}
+	if h.Length() == 0 {
+		return
+	}
+
+	var found *goquery.Selection
+	p := h.Parent()
+	for i := 0; i < 3 && p.Length() > 0 && found == nil; i++ {
+		p.Find("*").EachWithBreak(func(i int, s *goquery.Selection) bool {
+			class, _ := s.Attr("class")
+			if bylineClass.MatchString(class) {
+				found = s
+				return false
+			}
+			return true
+		})
+		p = p.Parent()
+	}
+	if found == nil {
+		return
+	}
+
+	// links to author pages are most reliable
+	found.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		rel, _ := s.Attr("rel")
+		if authorPath.MatchString(href) || contains(strings.Fields(rel), "author") {
+			for _, name := range splitAuthors(s.Text()) {
+				m.addAuthors("byline", pipeline.Author{Name: name, URL: href})
+			}
+		}
+	})
+	if len(m.authors["byline"]) == 0 {
+		bylineText(m, found)
+	}
+
+	// an avatar image is only useful if there is a single author
+	img := found.Find("img[src]")
+	if img.Length() == 1 && len(m.authors["byline"]) == 1 {
+		src, _ := img.Attr("src")
+		m.authors["byline"][0].Image = src
+	}
+}
+
+// bylineText reads the names from the text of a byline.
+// Links only provide the URL, to avoid picking up links to categories or
+// social media.
+func bylineText(m *metadata, byline *goquery.Selection) {
+	for _, name := range splitAuthors(byline.Text()) {
+		a := pipeline.Author{Name: name}
+		byline.Find("a[href]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+			if strings.EqualFold(cleanAuthor(s.Text()), name) {
+				a.URL, _ = s.Attr("href")
+				return false
+			}
+			return true
+		})
+		m.addAuthors("byline", a)
+	}
+}
+
+// metaAuthors reads authors from the content of a <meta> tag.
+func metaAuthors(content string) []pipeline.Author {
+	content = strings.TrimSpace(content)
+	if isURL(content) {
+		return []pipeline.Author{{URL: content}}
+	}
+
+	authors := make([]pipeline.Author, 0)
+	for _, name := range splitAuthors(content) {
+		authors = append(authors, pipeline.Author{Name: name})
+	}
+	return authors
+}
+
+var (
+	authorPrefix = regexp.MustCompile(`(?i)^((written|posted|authored)\s+by|by)\s*:?\s+|^(autor(in)?|author|text)\s*:\s*`)
+	// "von", "par" and "por" are also particles in names like "von der Leyen",
+	// they are only removed in front of a capitalized name. In bylines, they
+	// are capitalized at the start of the sentence.
+	particlePrefix       = regexp.MustCompile(`^(?:von|par|por)\s*:?\s+(\p{Lu})`)
+	bylineParticlePrefix = regexp.MustCompile(`^(?i:von|par|por)\s*:?\s+(\p{Lu})`)
+	authorSep            = regexp.MustCompile(`(?i)\s*(;|\||&|·|•|—|\s+-\s+|\s+and\s+|\s+und\s+|\s+et\s+|\s+y\s+)\s*`)
+	commaSep             = regexp.MustCompile(`\s*,\s*`)
+	familyGiven          = regexp.MustCompile(`^[\p{L}'-]+\s*,\s*[\p{L}'.-]+$`)
+	spaces               = regexp.MustCompile(`\s+`)
+	digits               = regexp.MustCompile(`\d`)
+)
+
+// splitAuthors splits a byline with multiple names,
+// e.g. "By Jane Doe, John Doe and Max Mustermann".
+//
+// Two single words with a comma are one name in the form "Family, Given",
+// e.g. "Doe, Jane".
+func splitAuthors(s string) []string {
+	s = strings.TrimSpace(spaces.ReplaceAllString(s, " "))
+	s = authorPrefix.ReplaceAllString(s, "")
+	s = bylineParticlePrefix.ReplaceAllString(s, "$1")
+
+	result := make([]string, 0)
+	for _, part := range authorSep.Split(s, -1) {
+		names := commaSep.Split(part, -1)
+		if familyGiven.MatchString(part) {
+			names = []string{invertName(part)}
+		}
+
+		for _, name := range names {
+			name = cleanAuthor(name)
+			// drop dates, reading time and the like
+			if len(name) > 100 || len(strings.Fields(name)) > 6 || digits.MatchString(name) {
+				continue
+			}
+			if name != "" {
+				result = append(result, name)
+			}
+		}
+	}
+	return result
+}
+
+// cleanAuthor trims prefixes like "By" from a name and drops values which
+// are not names, like twitter handles.
+func cleanAuthor(s string) string {
+	s = strings.TrimSpace(spaces.ReplaceAllString(s, " "))
+	s = authorPrefix.ReplaceAllString(s, "")
+	s = particlePrefix.ReplaceAllString(s, "$1")
+	s = strings.Trim(s, " ,;:|-–")
+
+	if strings.HasPrefix(s, "@") || isURL(s) {
+		return ""
+	}
+	return s
+}
+
+// generic names are dropped if the page names real authors
+var genericAuthor = regexp.MustCompile(`(?i)^(the\s+)?(staff|editors?|editorial( staff| team| board)?|newsroom|news ?desk|redaktion|admin(istrator)?)$`)
+
+// selectAuthors merges the authors from all sources, by preference.
+//
+// Authors with the same name are de-duplicated, URLs and images which are
+// missing from the preferred source are taken from other sources.
+func selectAuthors(m *metadata) []pipeline.Author {
+	result := make([]pipeline.Author, 0)
+	index := make(map[string]int)
+	for _, k := range authorPref {
+		for _, a := range m.authors[k] {
+			if a.Name == "" {
+				continue
+			}
+
+			key := strings.ToLower(a.Name)
+			i, seen := index[key]
+			if !seen {
+				index[key] = len(result)
+				result = append(result, a)
+				continue
+			}
+
+			if result[i].URL == "" {
+				result[i].URL = a.URL
+			}
+			if result[i].Image == "" {
+				result[i].Image = a.Image
+			}
+		}
+	}
+
+	named := make([]pipeline.Author, 0, len(result))
+	for _, a := range result {
+		if !genericAuthor.MatchString(a.Name) {
+			named = append(named, a)
+		}
+	}
+	if hasNamedAuthor(named) {
+		result = named
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func hasNamedAuthor(authors []pipeline.Author) bool {
+	for _, a := range authors {
+		if a.Name != "" && a.Role == roleAuthor {
+			return true
+		}
+	}
+	return false
+}
+
+// authorNames joins the names of all authors (not editors or contributors).
+func authorNames(authors []pipeline.Author) string {
+	names := make([]string, 0)
+	for _, a := range authors {
+		if a.Role == roleAuthor {
+			names = append(names, a.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
@@ -0,0 +1,147 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestSplitAuthors(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string][]string{
+		"Jane Doe":                                {"Jane Doe"},
+		"By Jane Doe":                             {"Jane Doe"},
+		"  Von   Max Mustermann ":                 {"Max Mustermann"},
+		"Written by Jane Doe, John Doe and Max":   {"Jane Doe", "John Doe", "Max"},
+		"Par Jean Dupont et Marie Curie":          {"Jean Dupont", "Marie Curie"},
+		"Autor: Max Mustermann | 15.08.2021":      {"Max Mustermann"},
+		"By Jane Doe · Aug 15, 2021 · 5 min read": {"Jane Doe"},
+		"@handle":                    {},
+		"Von der Leyen":              {"Von der Leyen"},
+		"von Jane Doe":               {"Jane Doe"},
+		"Doe, Jane":                  {"Jane Doe"},
+		"By Doe, Jane; Roe, Richard": {"Jane Doe", "Richard Roe"},
+		"Jane Doe, John Doe":         {"Jane Doe", "John Doe"},
+	}
+
+	for s, want := range cases {
+		assert.Equal(want, splitAuthors(s), s)
+	}
+}
+
+func TestCleanAuthor(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("Von der Leyen", cleanAuthor("Von der Leyen"))
+	assert.Equal("Ursula von der Leyen", cleanAuthor("Ursula von der Leyen"))
+	assert.Equal("Jane Doe", cleanAuthor("by Jane Doe"))
+	assert.Equal("Jean Dupont", cleanAuthor("par Jean Dupont"))
+	assert.Equal("Par Avion", cleanAuthor("Par Avion"))
+}
+
+func TestAuthors(t *testing.T) {
+	assert := assert.New(t)
+
+	// JSON-LD first, generic names and handles are dropped
+	html := `<html><head>
+		<meta name="author" content="Staff" />
+		<meta property="twitter:creator" content="@handle" />
+		<script type="application/ld+json">
+		{
+			"@context": "https://schema.org",
+			"@type": "NewsArticle",
+			"author": [
+				{"@type": "Person", "name": "Jane Doe", "url": "https://example.com/jane"},
+				{"@type": "Person", "name": "John Doe"},
+				"Max Mustermann",
+				{"@type": "Person", "name": "Jane Doe"}
+			],
+			"editor": {"@type": "Person", "name": "Erika Mustermann"}
+		}
+		</script>
+	</head><body>
+		<a rel="author" href="https://example.com/john">John Doe</a>
+	</body></html>`
+
+	i, err := readMeta(html)
+	assert.Nil(err)
+	assert.Equal("Jane Doe, John Doe, Max Mustermann", i.Author)
+	assert.Equal([]pipeline.Author{
+		{Name: "Jane Doe", URL: "https://example.com/jane", Role: "author"},
+		// URL from rel=author
+		{Name: "John Doe", URL: "https://example.com/john", Role: "author"},
+		{Name: "Max Mustermann", Role: "author"},
+		{Name: "Erika Mustermann", Role: "editor"},
+	}, i.Authors)
+
+	// byline near the headline
+	html = `<html><head>
+		<meta name="author" content="Staff" />
+	</head><body>
+		<article>
+			<header>
+				<h1>The Headline</h1>
+				<div class="article-byline">
+					<img src="https://example.com/avatar.jpg" />
+					Von <a href="https://example.com/autoren/max">Max Mustermann</a>
+					<span>in <a href="/politik">Politik</a></span>
+				</div>
+			</header>
+		</article>
+	</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("Max Mustermann", i.Author)
+	assert.Equal([]pipeline.Author{
+		{
+			Name:  "Max Mustermann",
+			URL:   "https://example.com/autoren/max",
+			Image: "https://example.com/avatar.jpg",
+			Role:  "author",
+		},
+	}, i.Authors)
+
+	// merged from all sources
+	html = `<html><head>
+		<meta name="author" content="Erika Mustermann" />
+		<script type="application/ld+json">
+		{
+			"@context": "https://schema.org",
+			"@type": "NewsArticle",
+			"author": {"@type": "Person", "name": "Jane Doe"}
+		}
+		</script>
+	</head><body>
+		<a rel="author" href="https://example.com/john">John Doe</a>
+		<a rel="author" href="https://example.com/jane">jane doe</a>
+	</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("Jane Doe, John Doe, Erika Mustermann", i.Author)
+	assert.Equal("https://example.com/jane", i.Authors[0].URL)
+
+	// "Family, Given" is one name
+	html = `<html><head>
+		<meta name="citation_author" content="Doe, Jane" />
+		<meta name="author" content="Roe, Richard" />
+	</head><body>foo</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("Jane Doe, Richard Roe", i.Author)
+
+	// meta tags as a fallback
+	html = `<html><head>
+		<meta name="author" content="By Jane Doe and John Doe" />
+	</head><body>foo</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("Jane Doe, John Doe", i.Author)
+	assert.Equal(2, len(i.Authors))
+}
@@ -0,0 +1,272 @@
+package metadata
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// isCitationMeta tells if a meta tag is read by findCitation.
+func isCitationMeta(name string) bool {
+	key := citationKey(name)
+	for _, prefix := range []string{"citation_", "dc.", "dcterms.", "prism."} {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return key == "bepress_citation_doi"
+}
+
+// findCitation reads bibliographic metadata from Highwire Press
+// (`citation_*`), Dublin Core (`DC.*`) and PRISM (`prism.*`) meta tags.
+//
+// These are used by scholarly publishers and repositories,
+// see: https://scholar.google.com/intl/en/scholar/inclusion.html#indexing
+//
+// Names are not consistently cased, e.g. "DC.Title" and "dc.title" are both
+// common, so the keys are stored in lower case.
+func findCitation(m *metadata, doc *goquery.Document) {
+	doc.Selection.Find("meta[name]").Each(func(i int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		content, _ := s.Attr("content")
+		content = strings.TrimSpace(content)
+		if content == "" {
+			return
+		}
+
+		key := citationKey(name)
+		switch key {
+		case "citation_author", "dc.creator", "prism.creator":
+			m.addAuthors(key, pipeline.Author{Name: invertName(content)})
+		case "citation_authors":
+			for _, name := range strings.Split(content, ";") {
+				m.addAuthors(key, pipeline.Author{Name: invertName(name)})
+			}
+		case "citation_title", "dc.title", "prism.title":
+			setFirst(m.title, key, content)
+		case "citation_abstract", "dc.description":
+			setFirst(m.description, key, content)
+		case "citation_publication_date", "citation_online_date", "citation_date",
+			"prism.publicationdate", "prism.coverdate",
+			"dc.date", "dc.date.issued", "dcterms.issued", "dcterms.created":
+			setFirst(m.pubDate, key, content)
+		case "dc.date.modified", "dcterms.modified", "prism.modificationdate":
+			setFirst(m.modified, key, content)
+		case "dc.language", "dcterms.language", "citation_language":
+			setFirst(m.language, key, content)
+		case "dc.publisher", "dcterms.publisher":
+			setFirst(m.siteName, key, content)
+			setFirst(m.citation, key, content)
+		case "citation_keywords", "prism.keyword", "dc.subject":
+			m.keywords = append(m.keywords, splitList(content)...)
+		default:
+			if contains(citationKeys, key) {
+				setFirst(m.citation, key, content)
+			}
+		}
+	})
+}
+
+// citationKey normalizes the name of a meta tag, e.g. "DC.Title" becomes
+// "dc.title" and "prism:doi" becomes "prism.doi".
+func citationKey(name string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if strings.HasPrefix(key, "prism:") {
+		key = "prism." + strings.TrimPrefix(key, "prism:")
+	}
+	return key
+}
+
+// setFirst sets a value unless a value for the key exists,
+// these meta tags are often repeated.
+func setFirst(m map[string]string, k, v string) {
+	_, exists := m[k]
+	if !exists {
+		setValue(m, k, v)
+	}
+}
+
+// invertName turns "Doe, Jane" into "Jane Doe".
+func invertName(s string) string {
+	s = strings.TrimSpace(s)
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return s
+	}
+	last := strings.TrimSpace(parts[0])
+	first := strings.TrimSpace(parts[1])
+	if first == "" {
+		return last
+	}
+	return first + " " + last
+}
+
+// Preference lists for citation fields.
+var (
+	citationDOIPref = []string{
+		"citation_doi",
+		"prism.doi",
+		"bepress_citation_doi",
+		"dc.identifier",
+		"dcterms.identifier",
+		"prism.url",
+	}
+	citationJournalPref = []string{
+		"citation_journal_title",
+		"prism.publicationname",
+		"citation_conference_title",
+		"citation_inbook_title",
+		"citation_book_title",
+		"dc.relation.ispartof",
+		"dc.source",
+	}
+	citationVolumePref = []string{
+		"citation_volume",
+		"prism.volume",
+	}
+	citationIssuePref = []string{
+		"citation_issue",
+		"prism.number",
+		"prism.issueidentifier",
+	}
+	citationFirstPagePref = []string{
+		"citation_firstpage",
+		"prism.startingpage",
+	}
+	citationLastPagePref = []string{
+		"citation_lastpage",
+		"prism.endingpage",
+	}
+	citationPublisherPref = []string{
+		"citation_publisher",
+		"dc.publisher",
+		"dcterms.publisher",
+		"prism.publisher",
+	}
+	citationInstitutionPref = []string{
+		"citation_dissertation_institution",
+		"citation_technical_report_institution",
+	}
+	citationISSNPref = []string{
+		"citation_issn",
+		"prism.issn",
+		"prism.eissn",
+	}
+	citationISBNPref = []string{
+		"citation_isbn",
+		"prism.isbn",
+	}
+	citationPDFPref = []string{
+		"citation_pdf_url",
+	}
+	// all keys that are read into m.citation
+	citationKeys = concat(
+		citationDOIPref,
+		citationJournalPref,
+		citationVolumePref,
+		citationIssuePref,
+		citationFirstPagePref,
+		citationLastPagePref,
+		citationPublisherPref,
+		citationInstitutionPref,
+		citationISSNPref,
+		citationISBNPref,
+		citationPDFPref,
+	)
+)
+
+func concat(lists ...[]string) []string {
+	result := make([]string, 0)
+	for _, l := range lists {
+		result = append(result, l...)
+	}
+	return result
+}
+
+// selectCitation creates the citation from the collected values.
+// Returns nil if the page has no bibliographic data.
+func selectCitation(m *metadata, t *pipeline.Task) *pipeline.Citation {
+	c := &pipeline.Citation{
+		Journal:     selectValue(m.citation, citationJournalPref),
+		Volume:      selectValue(m.citation, citationVolumePref),
+		Issue:       selectValue(m.citation, citationIssuePref),
+		FirstPage:   selectValue(m.citation, citationFirstPagePref),
+		LastPage:    selectValue(m.citation, citationLastPagePref),
+		Publisher:   selectValue(m.citation, citationPublisherPref),
+		Institution: selectValue(m.citation, citationInstitutionPref),
+		ISSN:        selectValue(m.citation, citationISSNPref),
+		ISBN:        selectValue(m.citation, citationISBNPref),
+		PDFURL:      selectValue(m.citation, citationPDFPref),
+	}
+
+	// DC.identifier may hold anything, use the first one that is a DOI
+	for _, k := range citationDOIPref {
+		c.DOI = normalizeDOI(m.citation[k])
+		if c.DOI != "" {
+			break
+		}
+	}
+
+	if c.PDFURL != "" {
+		resolved, err := t.ResolveURL(c.PDFURL)
+		if err == nil {
+			c.PDFURL = resolved
+		}
+	}
+
+	// a publisher alone is common on regular web pages
+	empty := pipeline.Citation{Publisher: c.Publisher}
+	if *c == empty {
+		return nil
+	}
+
+	c.Type = citationType(m)
+	return c
+}
+
+// selectValue returns the first value from the preference list.
+func selectValue(values map[string]string, pref []string) string {
+	for _, k := range pref {
+		v, ok := values[k]
+		if ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// citationType determines the CSL item type.
+func citationType(m *metadata) string {
+	has := func(k string) bool {
+		_, ok := m.citation[k]
+		return ok
+	}
+
+	switch {
+	case has("citation_conference_title"):
+		return "paper-conference"
+	case has("citation_dissertation_institution"):
+		return "thesis"
+	case has("citation_technical_report_institution"):
+		return "report"
+	case has("citation_inbook_title"):
+		return "chapter"
+	case has("citation_journal_title"), has("prism.publicationname"), has("citation_issn"):
+		return "article-journal"
+	case has("citation_isbn"), has("citation_book_title"):
+		return "book"
+	}
+	return "article"
+}
+
+var doiPattern = regexp.MustCompile(`10\.\d{4,9}/\S+`)
+
+// normalizeDOI extracts the DOI from values like "doi:10.1000/182" or
+// "https://doi.org/10.1000/182".
+// Returns an empty string if the value does not contain a DOI.
+func normalizeDOI(s string) string {
+	return strings.TrimRight(doiPattern.FindString(s), ".,;")
+}
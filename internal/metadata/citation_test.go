@@ -0,0 +1,98 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestCitation(t *testing.T) {
+	assert := assert.New(t)
+
+	task := &pipeline.Task{URL: "https://journal.example.com/article/123"}
+	task.SetHTML(`<html><head>
+        <title>Journal of Examples | Article</title>
+        <meta name="citation_title" content="On the Nature of Examples">
+        <meta name="citation_author" content="Doe, Jane">
+        <meta name="citation_author" content="Roe, Richard">
+        <meta name="citation_publication_date" content="2021/08/15">
+        <meta name="citation_journal_title" content="Journal of Examples">
+        <meta name="citation_volume" content="12">
+        <meta name="citation_issue" content="3">
+        <meta name="citation_firstpage" content="101">
+        <meta name="citation_lastpage" content="117">
+        <meta name="citation_doi" content="doi:10.1234/joe.2021.123">
+        <meta name="citation_issn" content="1234-5678">
+        <meta name="citation_pdf_url" content="/article/123.pdf">
+        <meta name="citation_keywords" content="examples; theory">
+        <meta name="DC.Publisher" content="Example Press">
+        <meta name="DC.Creator" content="Doe, Jane">
+    </head><body><p>Content</p></body></html>`)
+
+	err := ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal("On the Nature of Examples", task.Title)
+	assert.Equal("Jane Doe, Richard Roe", task.Author)
+	assert.Equal("2021-08-15", task.PubDate.Format("2006-01-02"))
+	assert.Equal([]string{"examples", "theory"}, task.Keywords)
+	assert.Equal("Example Press", task.SiteName)
+
+	c := task.Citation
+	if assert.NotNil(c) {
+		assert.Equal("article-journal", c.Type)
+		assert.Equal("10.1234/joe.2021.123", c.DOI)
+		assert.Equal("Journal of Examples", c.Journal)
+		assert.Equal("12", c.Volume)
+		assert.Equal("3", c.Issue)
+		assert.Equal("101", c.FirstPage)
+		assert.Equal("117", c.LastPage)
+		assert.Equal("1234-5678", c.ISSN)
+		assert.Equal("Example Press", c.Publisher)
+		assert.Equal("https://journal.example.com/article/123.pdf", c.PDFURL)
+	}
+
+	// PRISM and Dublin Core only
+	task = &pipeline.Task{URL: "https://example.com/paper"}
+	task.SetHTML(`<html><head>
+        <meta name="dc.title" content="A Paper">
+        <meta name="dc.identifier" content="urn:example:1">
+        <meta name="dc.identifier" content="https://doi.org/10.5555/12345678">
+        <meta name="prism:publicationName" content="Example Letters">
+        <meta name="prism:startingPage" content="7">
+        <meta name="citation_date" content="2019">
+    </head><body><p>Content</p></body></html>`)
+
+	err = ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal("A Paper", task.Title)
+	assert.Equal(2019, task.PubDate.Year())
+	if assert.NotNil(task.Citation) {
+		assert.Equal("article-journal", task.Citation.Type)
+		assert.Equal("Example Letters", task.Citation.Journal)
+		assert.Equal("7", task.Citation.FirstPage)
+	}
+
+	// regular web page
+	task = &pipeline.Task{URL: "https://example.com/blog"}
+	task.SetHTML(`<html><head>
+        <title>Blog</title>
+        <meta name="DC.publisher" content="Example">
+    </head><body><p>Content</p></body></html>`)
+
+	err = ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	assert.Nil(task.Citation)
+}
+
+func TestNormalizeDOI(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("10.1000/182", normalizeDOI("10.1000/182"))
+	assert.Equal("10.1000/182", normalizeDOI("doi:10.1000/182"))
+	assert.Equal("10.1000/182", normalizeDOI("https://doi.org/10.1000/182"))
+	assert.Equal("10.1000/abc(1)-2", normalizeDOI("DOI 10.1000/abc(1)-2."))
+	assert.Equal("", normalizeDOI("urn:isbn:123456"))
+}
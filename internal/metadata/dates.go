@@ -0,0 +1,355 @@
+package metadata
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// findTimes looks for `<time>` elements near the main headline.
+//
+// These are stored as "time/published" and "time/modified", an element is
+// considered to be the modification date if its class says so.
+func findTimes(m *metadata, doc *goquery.Document) {
+	h := doc.Find("article h1").First()
+	if h.Length() == 0 {
+		h = doc.Find("h1").First()
+	}
+
+	// <time pubdate> is obsolete, but still in use
+	doc.Find("time[pubdate]").First().Each(func(i int, s *goquery.Selection) {
+		setValue(m.pubDate, "time/published", timeValue(s))
+	})
+
+	if h.Length() == 0 {
+		return
+	}
+
+	// search the headline's surroundings, the closest ones first
+	p := h.Parent()
+	for i := 0; i < 3 && p.Length() > 0; i++ {
+		p.Find("time").Each(func(i int, s *goquery.Selection) {
+			class, _ := s.Attr("class")
+			class = strings.ToLower(class)
+			if strings.Contains(class, "updated") || strings.Contains(class, "modified") {
+				if _, ok := m.modified["time/modified"]; !ok {
+					setValue(m.modified, "time/modified", timeValue(s))
+				}
+			} else if _, ok := m.pubDate["time/published"]; !ok {
+				setValue(m.pubDate, "time/published", timeValue(s))
+			}
+		})
+		p = p.Parent()
+	}
+}
+
+func timeValue(s *goquery.Selection) string {
+	v, ok := s.Attr("datetime")
+	if ok && strings.TrimSpace(v) != "" {
+		return v
+	}
+	return s.Text()
+}
+
+var urlDatePatterns = []*regexp.Regexp{
+	// /2021/08/15/
+	regexp.MustCompile(`/((?:19|20)\d{2})/(\d{1,2})/(\d{1,2})(?:/|$)`),
+	// /2021-08-15-title
+	regexp.MustCompile(`/((?:19|20)\d{2})-(\d{2})-(\d{2})(?:[/\-_.]|$)`),
+	// /20210815/
+	regexp.MustCompile(`/((?:19|20)\d{2})(\d{2})(\d{2})(?:/|$)`),
+}
+
+// findURLDate looks for a date in the path of the page URL,
+// e.g. https://example.com/2021/08/15/title.html.
+func findURLDate(m *metadata, pageURL string) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return
+	}
+
+	for _, re := range urlDatePatterns {
+		match := re.FindStringSubmatch(u.Path)
+		if match == nil {
+			continue
+		}
+		y, _ := strconv.Atoi(match[1])
+		mo, _ := strconv.Atoi(match[2])
+		d, _ := strconv.Atoi(match[3])
+		if mo < 1 || mo > 12 || d < 1 || d > 31 {
+			continue
+		}
+		t := time.Date(y, time.Month(mo), d, 0, 0, 0, 0, time.UTC)
+		setValue(m.pubDate, "url/date", t.Format("2006-01-02"))
+		return
+	}
+}
+
+var layouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05Z",
+	// ISO 8601 variants, e.g. from JSON-LD
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"20060102",
+	// HTTP and RSS dates
+	time.RFC1123,
+	time.RFC1123Z,
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	time.RFC850,
+	time.RFC822,
+	time.RFC822Z,
+	time.ANSIC,
+	time.UnixDate,
+	// european
+	"02.01.2006 15:04",
+	"02.01.2006",
+	"2.1.2006",
+}
+
+// incomplete dates, common in citation metadata
+var partialLayouts = []struct {
+	layout    string
+	precision string
+}{
+	{"2006-01", pipeline.PrecisionMonth},
+	{"2006/01", pipeline.PrecisionMonth},
+	{"2006", pipeline.PrecisionYear},
+}
+
+// datePrecision tells if the value is an incomplete date like "2021" or
+// "2021-08". Returns PrecisionYear or PrecisionMonth for incomplete dates,
+// an empty string otherwise.
+func datePrecision(v string) string {
+	v = strings.TrimSpace(v)
+	for _, p := range partialLayouts {
+		_, err := time.Parse(p.layout, v)
+		if err == nil {
+			return p.precision
+		}
+	}
+	return ""
+}
+
+// parseTime parses a date in one of many formats.
+//
+// Supports ISO 8601 variants, HTTP dates, unix timestamps and dates with
+// month names in english, german, french or spanish. Dates without a timezone
+// are assumed to be UTC.
+func parseTime(v string) *time.Time {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+
+	if t := parseTimestamp(v); t != nil {
+		return t
+	}
+
+	for _, l := range layouts {
+		t, err := time.Parse(l, v)
+		if err == nil {
+			if !t.IsZero() {
+				t = fixZone(t)
+				return &t
+			}
+		}
+	}
+
+	// January 1st or the 1st of the month, see datePrecision
+	for _, p := range partialLayouts {
+		t, err := time.Parse(p.layout, v)
+		if err == nil {
+			return &t
+		}
+	}
+
+	return parseText(v)
+}
+
+// parseTimestamp parses unix timestamps in seconds or milliseconds.
+func parseTimestamp(v string) *time.Time {
+	if len(v) != 10 && len(v) != 13 {
+		return nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	var t time.Time
+	if len(v) == 13 {
+		t = time.Unix(0, n*int64(time.Millisecond)).UTC()
+	} else {
+		t = time.Unix(n, 0).UTC()
+	}
+
+	// avoid mistaking other numbers for a timestamp
+	if t.Year() < 1995 || t.After(time.Now().AddDate(1, 0, 0)) {
+		return nil
+	}
+	return &t
+}
+
+// offsets for timezone abbreviations, `time.Parse` does only know the local
+// zone and UTC.
+var zones = map[string]int{
+	"UTC":  0,
+	"GMT":  0,
+	"Z":    0,
+	"WET":  0,
+	"WEST": 1,
+	"BST":  1,
+	"CET":  1,
+	"MEZ":  1,
+	"CEST": 2,
+	"MESZ": 2,
+	"EET":  2,
+	"EEST": 3,
+	"EST":  -5,
+	"EDT":  -4,
+	"CST":  -6,
+	"CDT":  -5,
+	"MST":  -7,
+	"MDT":  -6,
+	"PST":  -8,
+	"PDT":  -7,
+	"JST":  9,
+	"AEST": 10,
+	"AEDT": 11,
+}
+
+// fixZone applies the offset for a known timezone abbreviation.
+func fixZone(t time.Time) time.Time {
+	name, offset := t.Zone()
+	if offset != 0 {
+		return t
+	}
+	hours, ok := zones[strings.ToUpper(name)]
+	if !ok || hours == 0 {
+		return t
+	}
+	loc := time.FixedZone(name, hours*60*60)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+var months = map[string]time.Month{
+	// english
+	"january": 1, "jan": 1, "february": 2, "feb": 2, "march": 3, "mar": 3,
+	"april": 4, "apr": 4, "may": 5, "june": 6, "jun": 6, "july": 7, "jul": 7,
+	"august": 8, "aug": 8, "september": 9, "sep": 9, "sept": 9,
+	"october": 10, "oct": 10, "november": 11, "nov": 11, "december": 12, "dec": 12,
+	// german
+	"januar": 1, "jänner": 1, "jän": 1, "februar": 2, "märz": 3, "maerz": 3, "mär": 3,
+	"mai": 5, "juni": 6, "juli": 7, "oktober": 10, "okt": 10, "dezember": 12, "dez": 12,
+	// french
+	"janvier": 1, "janv": 1, "février": 2, "fevrier": 2, "févr": 2, "fevr": 2,
+	"mars": 3, "avril": 4, "avr": 4, "juin": 6, "juillet": 7, "juil": 7,
+	"août": 8, "aout": 8, "septembre": 9, "octobre": 10, "novembre": 11,
+	"décembre": 12, "decembre": 12, "déc": 12,
+	// spanish
+	"enero": 1, "ene": 1, "febrero": 2, "marzo": 3, "abril": 4, "abr": 4,
+	"mayo": 5, "junio": 6, "julio": 7, "agosto": 8, "ago": 8,
+	"septiembre": 9, "setiembre": 9, "octubre": 10, "noviembre": 11,
+	"diciembre": 12, "dic": 12,
+}
+
+var (
+	// 15. August 2021, 1er août 2021, 15 de agosto de 2021
+	dayMonthYear = regexp.MustCompile(`(\d{1,2})(?:\.|er|st|nd|rd|th)?\s+(?:de\s+)?(\pL+)\.?,?\s+(?:de\s+|del\s+)?(\d{4})`)
+	// August 15, 2021
+	monthDayYear = regexp.MustCompile(`(\pL+)\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})`)
+	// 10:30, 10h30, 10:30:15 pm, followed by an optional zone
+	timeOfDay = regexp.MustCompile(`(\d{1,2})(?::|h)(\d{2})(?::(\d{2}))?\s*(am|pm)?(?:\s*(?:uhr|h))?\s*([+-]\d{2}:?\d{2}|[a-z]{1,4}\b)?`)
+)
+
+// parseText parses dates with month names, like "15. März 2021, 10:30 Uhr".
+func parseText(v string) *time.Time {
+	s := strings.ToLower(v)
+
+	var day, year int
+	var month time.Month
+	var end int
+
+	if match := dayMonthYear.FindStringSubmatchIndex(s); match != nil {
+		day, _ = strconv.Atoi(s[match[2]:match[3]])
+		month = months[s[match[4]:match[5]]]
+		year, _ = strconv.Atoi(s[match[6]:match[7]])
+		end = match[1]
+	}
+	if month == 0 {
+		match := monthDayYear.FindStringSubmatchIndex(s)
+		if match == nil {
+			return nil
+		}
+		month = months[s[match[2]:match[3]]]
+		day, _ = strconv.Atoi(s[match[4]:match[5]])
+		year, _ = strconv.Atoi(s[match[6]:match[7]])
+		end = match[1]
+	}
+	if month == 0 || day < 1 || day > 31 {
+		return nil
+	}
+
+	var hour, minute, second int
+	loc := time.UTC
+	if match := timeOfDay.FindStringSubmatch(s[end:]); match != nil {
+		hour, _ = strconv.Atoi(match[1])
+		minute, _ = strconv.Atoi(match[2])
+		second, _ = strconv.Atoi(match[3])
+		if match[4] == "pm" && hour < 12 {
+			hour += 12
+		} else if match[4] == "am" && hour == 12 {
+			hour = 0
+		}
+		loc = parseZone(match[5])
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return nil
+	}
+
+	t := time.Date(year, month, day, hour, minute, second, 0, loc)
+	return &t
+}
+
+// parseZone parses a numeric offset like "+02:00" or a zone abbreviation.
+// Returns UTC for unknown zones.
+func parseZone(s string) *time.Location {
+	if s == "" {
+		return time.UTC
+	}
+
+	if s[0] == '+' || s[0] == '-' {
+		digits := strings.Replace(s[1:], ":", "", 1)
+		h, _ := strconv.Atoi(digits[:2])
+		m, _ := strconv.Atoi(digits[2:])
+		offset := h*60*60 + m*60
+		if s[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(s, offset)
+	}
+
+	hours, ok := zones[strings.ToUpper(s)]
+	if !ok {
+		return time.UTC
+	}
+	return time.FixedZone(strings.ToUpper(s), hours*60*60)
+}
@@ -0,0 +1,120 @@
+package metadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeFormats(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]string{
+		// ISO 8601
+		"2021-08-15T10:30:00+02:00": "2021-08-15T08:30:00Z",
+		"2021-08-15T10:30:00.123Z":  "2021-08-15T10:30:00Z",
+		"2021-08-15T10:30:00+0200":  "2021-08-15T08:30:00Z",
+		"2021-08-15T10:30":          "2021-08-15T10:30:00Z",
+		"2021-08-15 10:30:00":       "2021-08-15T10:30:00Z",
+		"2021-08-15":                "2021-08-15T00:00:00Z",
+		"2021/08/15":                "2021-08-15T00:00:00Z",
+		"15.08.2021":                "2021-08-15T00:00:00Z",
+		// HTTP dates
+		"Sun, 15 Aug 2021 10:30:00 GMT":  "2021-08-15T10:30:00Z",
+		"Sun, 15 Aug 2021 10:30:00 EDT":  "2021-08-15T14:30:00Z",
+		"Sun, 5 Aug 2021 10:30:00 +0200": "2021-08-05T08:30:00Z",
+		// unix timestamps
+		"1629023400":    "2021-08-15T10:30:00Z",
+		"1629023400000": "2021-08-15T10:30:00Z",
+		// month names
+		"August 15, 2021":                      "2021-08-15T00:00:00Z",
+		"Sunday, Aug. 15th, 2021 10:30 pm EST": "2021-08-16T03:30:00Z",
+		"15. März 2021, 10:30 Uhr":             "2021-03-15T10:30:00Z",
+		"15. Dez. 2021":                        "2021-12-15T00:00:00Z",
+		"1er août 2021 à 10h30":                "2021-08-01T10:30:00Z",
+		"lundi 15 février 2021":                "2021-02-15T00:00:00Z",
+		"15 de agosto de 2021, 10:30 +02:00":   "2021-08-15T08:30:00Z",
+	}
+
+	for s, want := range cases {
+		ts := parseTime(s)
+		if assert.NotNil(ts, s) {
+			assert.Equal(want, ts.UTC().Format(time.RFC3339), s)
+		}
+	}
+
+	for _, s := range []string{"", "yesterday", "12345", "9999999999", "Page 2 of 10"} {
+		assert.Nil(parseTime(s), s)
+	}
+}
+
+func TestDatePrecision(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("year", datePrecision("2021"))
+	assert.Equal("month", datePrecision("2021-08"))
+	assert.Equal("month", datePrecision("2021/08"))
+	assert.Equal("", datePrecision("2021-08-15"))
+	assert.Equal("", datePrecision("August 2021"))
+
+	ts, precision := selectTime(map[string]string{
+		"citation_date": "2021",
+	}, pubDatePref)
+	if assert.NotNil(ts) {
+		assert.Equal("2021-01-01T00:00:00Z", ts.Format(time.RFC3339))
+	}
+	assert.Equal("year", precision)
+
+	ts, precision = selectTime(map[string]string{
+		"citation_date": "2021-08-15",
+	}, pubDatePref)
+	assert.NotNil(ts)
+	assert.Equal("", precision)
+}
+
+func TestDateSources(t *testing.T) {
+	assert := assert.New(t)
+
+	// published and modified are kept apart
+	html := `<html><head>
+		<meta property="article:modified_time" content="2021-08-16T10:00:00Z" />
+	</head><body>foo</body></html>`
+
+	i, err := readMeta(html)
+	assert.Nil(err)
+	assert.Nil(i.PubDate)
+	assert.Equal("2021-08-16T10:00:00Z", i.ModifiedDate.Format(time.RFC3339))
+
+	// <time> near the headline
+	html = `<html><body>
+		<time datetime="2019-01-01">Unrelated</time>
+		<article>
+			<header>
+				<h1>Title</h1>
+				<p class="byline">
+					<time datetime="2021-08-15T10:00:00+02:00">15. August</time>
+					<time class="updated">16. August 2021</time>
+				</p>
+			</header>
+		</article>
+	</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("2021-08-15T08:00:00Z", i.PubDate.Format(time.RFC3339))
+	assert.Equal("2021-08-16T00:00:00Z", i.ModifiedDate.Format(time.RFC3339))
+
+	// date from the URL, as a last resort
+	m := newMetadata()
+	findURLDate(m, "https://example.com/2021/08/15/title.html")
+	assert.Equal("2021-08-15", m.pubDate["url/date"])
+
+	m = newMetadata()
+	findURLDate(m, "https://example.com/blog/2021-08-15-title")
+	assert.Equal("2021-08-15", m.pubDate["url/date"])
+
+	m = newMetadata()
+	findURLDate(m, "https://example.com/2021/99/99/title")
+	assert.Equal(0, len(m.pubDate))
+}
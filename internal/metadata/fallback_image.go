@@ -2,9 +2,6 @@ package metadata
 
 import (
 	"context"
-	"sort"
-	"strconv"
-	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	log "github.com/sirupsen/logrus"
@@ -12,8 +9,10 @@ import (
 	"github.com/akeil/scrapen/internal/pipeline"
 )
 
-// FallbackImage sets the main image for an article from a fallback source
+// FallbackImage sets the main image for an article from the content
 // if no other image has been set.
+//
+// Site icons are not used as a fallback, see FindIcons.
 func FallbackImage(ctx context.Context, t *pipeline.Task) error {
 	log.WithFields(log.Fields{
 		"task":   t.ID,
@@ -27,7 +26,6 @@ func FallbackImage(ctx context.Context, t *pipeline.Task) error {
 	}
 
 	fallbackImagefromContent(t)
-	fallbackImageFromIcon(t)
 
 	return nil
 }
@@ -40,111 +38,3 @@ func fallbackImagefromContent(t *pipeline.Task) {
 		t.ImageURL = src
 	})
 }
-
-var iconRels = []string{
-	"icon",
-	"apple-touch-icon",
-	"apple-touch-icon-precomposed",
-	"mask-icon",
-	"shortcut icon",
-}
-
-func fallbackImageFromIcon(t *pipeline.Task) {
-	doc := t.Document()
-	var icons iconList
-	icons = make(iconList, 0)
-	doc.Selection.Find("link").Each(func(i int, s *goquery.Selection) {
-		rel, _ := s.Attr("rel")
-		if !contains(iconRels, rel) {
-			return
-		}
-
-		href, _ := s.Attr("href")
-		if href == "" {
-			return
-		}
-
-		sizes, _ := s.Attr("sizes")
-		icons = append(icons, icon{rel, href, sizes})
-	})
-
-	// meta name="msapplication-TileImage content=""
-	// link rel=manifest
-	// ... and icons = [...]
-
-	sort.Sort(icons)
-	if len(icons) > 0 {
-		t.ImageURL = icons[0].href
-	}
-}
-
-type icon struct {
-	name string
-	href string
-	size string
-}
-
-func (i icon) area() int {
-	if i.size == "" {
-		return 0
-	}
-	parts := strings.Split(strings.ToLower(i.size), "x")
-	if len(parts) != 2 {
-		return 0
-	}
-
-	w, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return 0
-	}
-
-	h, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return 0
-	}
-
-	return w * h
-}
-
-// sort interface
-
-type iconList []icon
-
-func (l iconList) Len() int {
-	return len(l)
-}
-
-func (l iconList) Less(i, j int) bool {
-	// Tell if "a" is before "b"
-	a := l[i]
-	b := l[j]
-
-	// compare sizes
-	aArea := a.area()
-	bArea := b.area()
-	if aArea != 0 && bArea != 0 {
-		return aArea > bArea
-	}
-
-	// by preference
-	aPref := len(iconRels)
-	bPref := len(iconRels)
-	for idx, val := range iconRels {
-		if val == a.name {
-			aPref = idx
-		}
-		if val == b.name {
-			bPref = idx
-		}
-	}
-	if aPref != bPref {
-		return aPref < bPref
-	}
-
-	// if no other sort criteria is found, leave unchanged
-	return i < j
-}
-
-func (l iconList) Swap(i, j int) {
-	l[i], l[j] = l[j], l[i]
-}
@@ -36,9 +36,8 @@ func TestFallbackImage(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal("image.jpg", task.ImageURL)
 
-	// from Favicon, by size
+	// site icons are not used
 	html = `<html><head>
-        <link rel="icon" type="image/png" sizes="32x32" href="small.png">
         <link rel="icon" type="image/png" sizes="100x100" href="icon.png">
     </head><body><p>Content</p></body></html>
     `
@@ -46,17 +45,5 @@ func TestFallbackImage(t *testing.T) {
 	task.ImageURL = ""
 	err = FallbackImage(context.TODO(), task)
 	assert.Nil(err)
-	assert.Equal("icon.png", task.ImageURL)
-
-	// from Favicon, by preference
-	html = `<html><head>
-        <link rel="apple-touch-icon" type="image/png" href="icon.png">
-        <link rel="icon" type="image/png" href="pref.png">
-    </head><body><p>Content</p></body></html>
-    `
-	task.SetHTML(html)
-	task.ImageURL = ""
-	err = FallbackImage(context.TODO(), task)
-	assert.Nil(err)
-	assert.Equal("pref.png", task.ImageURL)
+	assert.Equal("", task.ImageURL)
 }
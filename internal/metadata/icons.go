@@ -0,0 +1,254 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// FindIcons sets the site icon from `<link>` elements and the web app
+// manifest.
+//
+// The manifest is fetched if the page links to one. It may also provide the
+// theme color, if none is set in the document.
+func FindIcons(ctx context.Context, t *pipeline.Task) error {
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "metadata",
+		"url":    t.ContentURL(),
+	}).Info("Find site icons")
+
+	doc := t.Document()
+	if doc == nil {
+		return nil
+	}
+
+	icons := findIconLinks(t, doc)
+
+	href, _ := doc.Find("link[rel~=manifest]").First().Attr("href")
+	if href != "" {
+		m, err := fetchManifest(ctx, t, href)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"task":   t.ID,
+				"module": "metadata",
+				"url":    href,
+				"error":  err,
+			}).Warning("Failed to read web app manifest")
+		} else {
+			icons = append(icons, m.iconList()...)
+			if t.ThemeColor == "" {
+				t.ThemeColor = strings.TrimSpace(m.ThemeColor)
+			}
+		}
+	}
+
+	sort.Stable(icons)
+	if len(icons) > 0 {
+		t.SiteIcon = icons[0].href
+	}
+
+	return nil
+}
+
+var iconRels = []string{
+	"icon",
+	"apple-touch-icon",
+	"apple-touch-icon-precomposed",
+	"manifest",
+	"msapplication-TileImage",
+	"mask-icon",
+	"shortcut icon",
+}
+
+func findIconLinks(t *pipeline.Task, doc *goquery.Document) iconList {
+	icons := make(iconList, 0)
+	add := func(name, href, sizes string) {
+		href = strings.TrimSpace(href)
+		if href == "" {
+			return
+		}
+		resolved, err := t.ResolveURL(href)
+		if err == nil {
+			href = resolved
+		}
+		icons = append(icons, icon{name, href, sizes})
+	}
+
+	doc.Selection.Find("link").Each(func(i int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		rel = strings.ToLower(strings.TrimSpace(rel))
+		if !contains(iconRels, rel) || rel == "manifest" {
+			return
+		}
+
+		href, _ := s.Attr("href")
+		sizes, _ := s.Attr("sizes")
+		add(rel, href, sizes)
+	})
+
+	doc.Selection.Find("meta[name=msapplication-TileImage]").Each(func(i int, s *goquery.Selection) {
+		content, _ := s.Attr("content")
+		add("msapplication-TileImage", content, "144x144")
+	})
+
+	return icons
+}
+
+// the manifest should be small, anything larger is probably an error page
+const maxManifestSize = 1 << 20
+
+// manifest is the relevant part of a web app manifest.
+//
+// see: https://developer.mozilla.org/en-US/docs/Web/Manifest
+type manifest struct {
+	Icons []struct {
+		Src     string `json:"src"`
+		Sizes   string `json:"sizes"`
+		Purpose string `json:"purpose"`
+	} `json:"icons"`
+	ThemeColor string `json:"theme_color"`
+	// icons are relative to the manifest
+	url *url.URL
+}
+
+func fetchManifest(ctx context.Context, t *pipeline.Task, href string) (*manifest, error) {
+	href, err := t.ResolveURL(href)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", href, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.HTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got HTTP status %v", res.StatusCode)
+	}
+
+	var m manifest
+	err = json.NewDecoder(io.LimitReader(res.Body, maxManifestSize)).Decode(&m)
+	if err != nil {
+		return nil, err
+	}
+
+	m.url = res.Request.URL
+	return &m, nil
+}
+
+func (m *manifest) iconList() iconList {
+	icons := make(iconList, 0)
+	for _, i := range m.Icons {
+		if i.Src == "" {
+			continue
+		}
+		// monochrome icons are for system UI, not for display
+		if i.Purpose != "" && !contains(strings.Fields(i.Purpose), "any") {
+			continue
+		}
+
+		src := i.Src
+		u, err := url.Parse(src)
+		if err == nil && m.url != nil {
+			src = m.url.ResolveReference(u).String()
+		}
+		icons = append(icons, icon{"manifest", src, i.Sizes})
+	}
+	return icons
+}
+
+type icon struct {
+	name string
+	href string
+	size string
+}
+
+// vector icons with sizes="any" are preferred over all others
+const anySize = 1 << 20
+
+// area returns the size of the largest variant of the icon, sizes may
+// contain several values like "16x16 32x32".
+func (i icon) area() int {
+	max := 0
+	for _, size := range strings.Fields(strings.ToLower(i.size)) {
+		if size == "any" {
+			return anySize
+		}
+
+		parts := strings.Split(size, "x")
+		if len(parts) != 2 {
+			continue
+		}
+
+		w, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+
+		h, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+
+		if w*h > max {
+			max = w * h
+		}
+	}
+	return max
+}
+
+// pref is the index of the icon's rel in iconRels.
+func (i icon) pref() int {
+	for idx, val := range iconRels {
+		if val == i.name {
+			return idx
+		}
+	}
+	return len(iconRels)
+}
+
+// sort interface
+
+type iconList []icon
+
+func (l iconList) Len() int {
+	return len(l)
+}
+
+func (l iconList) Less(i, j int) bool {
+	// Tell if "a" is before "b"
+	a := l[i]
+	b := l[j]
+
+	// compare sizes, icons without a size come last
+	aArea := a.area()
+	bArea := b.area()
+	if aArea != bArea {
+		return aArea > bArea
+	}
+
+	// by preference
+	return a.pref() < b.pref()
+}
+
+func (l iconList) Swap(i, j int) {
+	l[i], l[j] = l[j], l[i]
+}
@@ -0,0 +1,162 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestFindIcons(t *testing.T) {
+	assert := assert.New(t)
+
+	task := &pipeline.Task{URL: "https://example.com/page"}
+
+	// by size
+	task.SetHTML(`<html><head>
+        <link rel="icon" type="image/png" sizes="32x32" href="small.png">
+        <link rel="icon" type="image/png" sizes="16x16 100x100" href="icon.png">
+    </head><body><p>Content</p></body></html>`)
+	err := FindIcons(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal("https://example.com/icon.png", task.SiteIcon)
+
+	// by preference
+	task.SetHTML(`<html><head>
+        <link rel="apple-touch-icon" type="image/png" href="icon.png">
+        <link rel="icon" type="image/png" href="pref.png">
+    </head><body><p>Content</p></body></html>`)
+	err = FindIcons(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal("https://example.com/pref.png", task.SiteIcon)
+
+	// the article image is not affected
+	assert.Equal("", task.ImageURL)
+}
+
+func TestIconOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	icons := []icon{
+		{"apple-touch-icon", "touch.png", ""},
+		{"icon", "small.png", "16x16"},
+		{"shortcut icon", "large.png", "32x32"},
+		{"icon", "pref.png", ""},
+	}
+
+	// same order regardless of the input order
+	perms := [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {1, 3, 0, 2}, {2, 0, 3, 1}}
+	for _, perm := range perms {
+		l := make(iconList, 0)
+		for _, idx := range perm {
+			l = append(l, icons[idx])
+		}
+		sort.Stable(l)
+		hrefs := make([]string, 0)
+		for _, i := range l {
+			hrefs = append(hrefs, i.href)
+		}
+		assert.Equal([]string{"large.png", "small.png", "pref.png", "touch.png"}, hrefs)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app/manifest.json":
+			w.Header().Set("Content-Type", "application/manifest+json")
+			fmt.Fprint(w, `{
+				"name": "Example",
+				"theme_color": "#336699",
+				"icons": [
+					{"src": "icons/192.png", "sizes": "192x192"},
+					{"src": "icons/512.png", "sizes": "512x512"},
+					{"src": "icons/mono.png", "sizes": "1024x1024", "purpose": "monochrome"}
+				]
+			}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	task := &pipeline.Task{URL: srv.URL + "/page", Client: srv.Client()}
+	task.SetHTML(`<html><head>
+        <link rel="icon" sizes="32x32" href="/favicon.png">
+        <link rel="manifest" href="/app/manifest.json">
+    </head><body><p>Content</p></body></html>`)
+
+	err := FindIcons(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal(srv.URL+"/app/icons/512.png", task.SiteIcon)
+	assert.Equal("#336699", task.ThemeColor)
+
+	// theme color from the document is preferred, missing manifest is ignored
+	task = &pipeline.Task{URL: srv.URL + "/page", Client: srv.Client(), ThemeColor: "#000000"}
+	task.SetHTML(`<html><head>
+        <link rel="icon" sizes="32x32" href="/favicon.png">
+        <link rel="manifest" href="/missing.json">
+    </head><body><p>Content</p></body></html>`)
+
+	err = FindIcons(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal(srv.URL+"/favicon.png", task.SiteIcon)
+	assert.Equal("#000000", task.ThemeColor)
+}
+
+func TestSiteLogo(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><head>
+		<meta name="theme-color" content="#ffffff" media="(prefers-color-scheme: light)" />
+		<meta name="theme-color" content="#000000" media="(prefers-color-scheme: dark)" />
+		<meta name="msapplication-TileColor" content="#ff0000" />
+		<meta property="og:logo" content="https://example.com/og-logo.png" />
+		<script type="application/ld+json">
+		{
+			"@type": "NewsArticle",
+			"headline": "Title",
+			"publisher": {
+				"@type": "Organization",
+				"name": "Example",
+				"logo": {"@type": "ImageObject", "url": "https://example.com/logo.png"}
+			}
+		}
+		</script>
+	</head><body>foo</body></html>`
+
+	i, err := readMeta(html)
+	assert.Nil(err)
+	assert.Equal("https://example.com/logo.png", i.SiteLogo)
+	assert.Equal("#ffffff", i.ThemeColor)
+	assert.Equal("", i.SiteIcon)
+	assert.Equal("", i.ImageURL)
+
+	// not the logo of some other organization
+	html = `<html><head>
+		<script type="application/ld+json">
+		{
+			"@graph": [
+				{"@type": "NewsArticle", "headline": "Title"},
+				{
+					"@type": "Organization",
+					"name": "Sponsor",
+					"logo": "https://sponsor.example.com/logo.png"
+				}
+			]
+		}
+		</script>
+	</head><body>foo</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("", i.SiteLogo)
+}
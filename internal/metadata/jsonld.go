@@ -0,0 +1,236 @@
+package metadata
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/akeil/scrapen/internal/jsonld"
+	"github.com/akeil/scrapen/internal/microdata"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// schema.org types which describe the main content of a page, by preference.
+var articleTypes = []string{
+	"NewsArticle",
+	"ReportageNewsArticle",
+	"AnalysisNewsArticle",
+	"OpinionNewsArticle",
+	"BackgroundNewsArticle",
+	"ReviewNewsArticle",
+	"BlogPosting",
+	"LiveBlogPosting",
+	"ScholarlyArticle",
+	"TechArticle",
+	"Report",
+	"SocialMediaPosting",
+	"Article",
+}
+
+var pageTypes = []string{
+	"WebPage",
+	"ItemPage",
+	"AboutPage",
+	"CollectionPage",
+}
+
+// findJSONLD reads metadata from JSON-LD markup.
+//
+// The values are stored with an "ld/" prefix and take part in the same
+// preference lists as the values from <meta> tags.
+func findJSONLD(m *metadata, doc *goquery.Document) {
+	readGraph(m, jsonld.Parse(doc), "ld/")
+}
+
+// findMicrodata reads metadata from microdata and RDFa markup.
+//
+// The values are stored with an "md/" prefix, JSON-LD is preferred over
+// microdata.
+func findMicrodata(m *metadata, doc *goquery.Document) {
+	readGraph(m, microdata.Parse(doc), "md/")
+}
+
+// readGraph reads the metadata from schema.org items.
+func readGraph(m *metadata, g *jsonld.Graph, prefix string) {
+	n := mainNode(g)
+	if n == nil {
+		return
+	}
+
+	setValue(m.title, prefix+"headline", n.String("headline"))
+	setValue(m.title, prefix+"name", n.String("name"))
+	setValue(m.description, prefix+"description", n.String("description"))
+	setValue(m.pubDate, prefix+"datePublished", n.String("datePublished"))
+	setValue(m.modified, prefix+"dateModified", n.String("dateModified"))
+	setValue(m.url, prefix+"url", n.String("url"))
+
+	image := n.URL("image")
+	if image == "" {
+		image = n.URL("thumbnailUrl")
+	}
+	setValue(m.image, prefix+"image", image)
+
+	m.addAuthors(prefix+"author", ldPeople(n, "author", roleAuthor)...)
+	m.addAuthors(prefix+"author", ldPeople(n, "editor", roleEditor)...)
+	m.addAuthors(prefix+"author", ldPeople(n, "contributor", roleContributor)...)
+
+	publisher := ldNames(n.Nodes("publisher"), n.Strings("publisher"))
+	if len(publisher) == 0 {
+		// the publisher might be found on the WebSite
+		site := g.First("WebSite")
+		if site != nil {
+			publisher = ldNames(site.Nodes("publisher"), nil)
+			if len(publisher) == 0 && site.String("name") != "" {
+				publisher = []string{site.String("name")}
+			}
+		}
+	}
+	if len(publisher) > 0 {
+		setValue(m.siteName, prefix+"publisher", publisher[0])
+	}
+
+	setValue(m.logo, prefix+"logo", ldLogo(g, n))
+
+	for _, kw := range n.Strings("keywords") {
+		m.keywords = append(m.keywords, splitList(kw)...)
+	}
+	setValue(m.section, prefix+"articleSection", n.String("articleSection"))
+
+	// the language can also be a Language node
+	language := n.String("inLanguage")
+	if l := n.Node("inLanguage"); l != nil && l.String("alternateName") != "" {
+		language = l.String("alternateName")
+	}
+	setValue(m.language, prefix+"inLanguage", language)
+
+	// the license is a URL or a CreativeWork
+	license := n.URL("license")
+	if l := n.Node("license"); l != nil && license == "" {
+		license = l.String("name")
+	}
+	setValue(m.license, prefix+"license", license)
+	holder := ldNames(n.Nodes("copyrightHolder"), n.Strings("copyrightHolder"))
+	if len(holder) > 0 {
+		setValue(m.rightsHolder, prefix+"copyrightHolder", holder[0])
+	}
+	setValue(m.copyrightYear, prefix+"copyrightYear", n.String("copyrightYear"))
+}
+
+// ldLogo finds the logo of the publisher.
+// Other organizations, e.g. a sponsor or the subject of the article, are not
+// considered.
+func ldLogo(g *jsonld.Graph, n *jsonld.Node) string {
+	orgs := n.Nodes("publisher")
+	site := g.First("WebSite")
+	if site != nil {
+		orgs = append(orgs, site.Nodes("publisher")...)
+	}
+
+	for _, org := range orgs {
+		logo := org.URL("logo")
+		if logo != "" {
+			return logo
+		}
+	}
+	return ""
+}
+
+// mainNode selects the node that describes the main content of the page.
+// Article types come first, web pages are used as a fallback.
+func mainNode(g *jsonld.Graph) *jsonld.Node {
+	for _, t := range articleTypes {
+		n := g.First(t)
+		if n != nil {
+			return n
+		}
+	}
+
+	return g.First(pageTypes...)
+}
+
+// ldPeople reads Person or Organization nodes for the given key.
+// Plain strings are used as names, unless they are URLs.
+func ldPeople(n *jsonld.Node, key, role string) []pipeline.Author {
+	people := make([]pipeline.Author, 0)
+	for _, p := range n.Nodes(key) {
+		a := pipeline.Author{
+			Name:  p.String("name"),
+			URL:   p.String("url"),
+			Image: p.URL("image"),
+			Role:  role,
+		}
+		if a.Name == "" {
+			a.Name = strings.TrimSpace(p.String("givenName") + " " + p.String("familyName"))
+		}
+		people = append(people, a)
+	}
+
+	for _, s := range n.Strings(key) {
+		if isURL(s) {
+			continue
+		}
+		people = append(people, pipeline.Author{Name: s, Role: role})
+	}
+
+	return people
+}
+
+// ldNames collects the names of Person or Organization nodes.
+// Plain strings are used as-is, unless they are URLs.
+func ldNames(nodes []*jsonld.Node, plain []string) []string {
+	names := make([]string, 0)
+	for _, n := range nodes {
+		name := n.String("name")
+		if name == "" {
+			name = strings.TrimSpace(n.String("givenName") + " " + n.String("familyName"))
+		}
+		if name != "" && !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, s := range plain {
+		if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+			continue
+		}
+		if !contains(names, s) {
+			names = append(names, s)
+		}
+	}
+
+	return names
+}
+
+func setValue(m map[string]string, k, v string) {
+	v = strings.TrimSpace(v)
+	if v != "" {
+		m[k] = v
+	}
+}
+
+var listSep = regexp.MustCompile(`[,;]`)
+
+// splitList splits a comma- or semicolon-separated list and normalizes the
+// items.
+func splitList(s string) []string {
+	result := make([]string, 0)
+	for _, item := range listSep.Split(s, -1) {
+		item = normalizeKeyword(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// normalizeKeyword collapses whitespace and removes quotes and hash signs.
+func normalizeKeyword(s string) string {
+	s = spaces.ReplaceAllString(s, " ")
+	s = strings.Trim(s, " \"'#")
+	// not a keyword, but a sentence
+	if len(s) > 64 {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,157 @@
+package metadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLD(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><head>
+		<title>Page Title | Example</title>
+		<meta name="description" content="Meta description" />
+		<meta property="twitter:creator" content="@handle" />
+		<meta property="article:published_time" content="2020-01-01T10:00:00Z" />
+		<script type="application/ld+json">
+		{
+			"@context": "https://schema.org",
+			"@graph": [
+				{
+					"@type": "WebSite",
+					"@id": "https://example.com/#website",
+					"name": "Example",
+					"publisher": {"@id": "https://example.com/#org"}
+				},
+				{
+					"@type": "Organization",
+					"@id": "https://example.com/#org",
+					"name": "Example Publishing"
+				},
+				{
+					"@type": "BlogPosting",
+					"headline": "The Headline",
+					"description": "JSON-LD description",
+					"datePublished": "2021-05-14",
+					"dateModified": "2021-05-15T08:30:00+02:00",
+					"author": [{"@type": "Person", "name": "Jane Doe"}, {"@type": "Person", "name": "John Doe"}],
+					"image": {"@type": "ImageObject", "url": "https://example.com/image.jpg"},
+					"keywords": "foo, bar, Foo"
+				}
+			]
+		}
+		</script>
+	</head><body>foo</body></html>`
+
+	i, err := readMeta(html)
+	assert.Nil(err)
+
+	// preferred from JSON-LD
+	assert.Equal("The Headline", i.Title)
+	assert.Equal("Jane Doe, John Doe", i.Author)
+	assert.Equal("2021-05-14T00:00:00Z", i.PubDate.Format(time.RFC3339))
+	assert.Equal("2021-05-15T06:30:00Z", i.ModifiedDate.Format(time.RFC3339))
+	assert.Equal([]string{"foo", "bar"}, i.Keywords)
+	assert.Equal("Example Publishing", i.SiteName)
+
+	// preferred from <meta>
+	assert.Equal("Meta description", i.Description)
+
+	// fallback to JSON-LD
+	assert.Equal("https://example.com/image.jpg", i.ImageURL)
+}
+
+func TestJSONLDArray(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><head>
+		<script type="application/ld+json">
+		[
+			{"@type": "BreadcrumbList", "name": "Not the title"},
+			{
+				"@type": ["ReportageNewsArticle"],
+				"headline": "The Headline",
+				"author": "Jane Doe",
+				"publisher": {"@type": "Organization", "name": "The Publisher"}
+			}
+		]
+		</script>
+	</head><body>foo</body></html>`
+
+	i, err := readMeta(html)
+	assert.Nil(err)
+	assert.Equal("The Headline", i.Title)
+	assert.Equal("Jane Doe", i.Author)
+	assert.Equal("The Publisher", i.SiteName)
+}
+
+func TestMicrodata(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><head>
+		<title>Page Title</title>
+		<meta name="author" content="Staff" />
+	</head><body>
+		<article itemscope itemtype="http://schema.org/Article">
+			<h1 itemprop="headline">The Headline</h1>
+			<span itemprop="author" itemscope itemtype="http://schema.org/Person">
+				<span itemprop="name">Jane Doe</span>
+			</span>
+			<time itemprop="datePublished" datetime="2021-08-15T10:00:00+02:00">15.08.2021</time>
+		</article>
+	</body></html>`
+
+	i, err := readMeta(html)
+	assert.Nil(err)
+	assert.Equal("The Headline", i.Title)
+	assert.Equal("Jane Doe", i.Author)
+	assert.Equal("2021-08-15T08:00:00Z", i.PubDate.Format(time.RFC3339))
+}
+
+func TestMicroformats(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><head>
+		<title>The Headline | Jane's Blog</title>
+	</head><body>
+		<article class="h-entry">
+			<h1 class="p-name">The Headline</h1>
+			<a class="p-author h-card" href="https://jane.example.com/"><img class="u-photo" src="https://jane.example.com/photo.jpg" alt="">Jane Doe</a>
+			<time class="dt-published" datetime="2021-08-15T10:00:00+02:00">15.08.2021</time>
+			<a class="u-in-reply-to" href="https://other.example.com/post">Re</a>
+			<a class="u-like-of" href="https://third.example.com/note">Like</a>
+			<a class="p-category" href="/tags/go">Go</a>
+			<div class="e-content">Content</div>
+		</article>
+	</body></html>`
+
+	i, err := readMeta(html)
+	assert.Nil(err)
+	assert.Equal("The Headline", i.Title)
+	assert.Equal("Jane Doe", i.Author)
+	assert.Equal("2021-08-15T08:00:00Z", i.PubDate.Format(time.RFC3339))
+	assert.Equal([]string{"Go"}, i.Keywords)
+	assert.Equal([]string{"https://other.example.com/post"}, i.InReplyTo)
+	assert.Equal([]string{"https://third.example.com/note"}, i.LikeOf)
+
+	assert.Equal(1, len(i.Authors))
+	assert.Equal("Jane Doe", i.Authors[0].Name)
+	assert.Equal("https://jane.example.com/", i.Authors[0].URL)
+	assert.Equal("https://jane.example.com/photo.jpg", i.Authors[0].Image)
+
+	// the implied name of a note is its text
+	html = `<html><head>
+		<title>A Note</title>
+	</head><body>
+		<article class="h-entry">
+			Just a short note about something.
+			<a class="u-url" href="https://jane.example.com/notes/1"><time class="dt-published" datetime="2021-08-15T10:00:00+02:00">15.08.2021</time></a>
+		</article>
+	</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("A Note", i.Title)
+}
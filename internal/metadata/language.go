@@ -0,0 +1,83 @@
+package metadata
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/lang"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// findLanguage reads the language and text direction from the document.
+//
+// The `Content-Language` header has already been set on the task by the
+// fetch step and is used as one of the sources.
+func findLanguage(m *metadata, doc *goquery.Document, t *pipeline.Task) {
+	setValue(m.language, "http/content-language", t.Language)
+
+	for _, sel := range []string{"html", "body"} {
+		s := doc.Find(sel).First()
+		v, _ := s.Attr("lang")
+		setValue(m.language, sel+"/lang", v)
+
+		dir, _ := s.Attr("dir")
+		dir = strings.ToLower(strings.TrimSpace(dir))
+		if m.direction == "" && (dir == "ltr" || dir == "rtl") {
+			m.direction = dir
+		}
+	}
+
+	doc.Find("meta[http-equiv]").Each(func(i int, s *goquery.Selection) {
+		equiv, _ := s.Attr("http-equiv")
+		if strings.EqualFold(equiv, "content-language") {
+			content, _ := s.Attr("content")
+			setValue(m.language, "http-equiv/content-language", content)
+		}
+	})
+}
+
+// selectLanguage returns the first valid language tag, by preference.
+func selectLanguage(m *metadata) string {
+	for _, k := range languagePref {
+		v, ok := m.language[k]
+		if !ok {
+			continue
+		}
+		tag := lang.Normalize(v)
+		if tag != "" {
+			return tag
+		}
+	}
+	return ""
+}
+
+// DetectLanguage guesses the language from the content text if it was not
+// found in the metadata.
+//
+// This should be called on the final content, after readability.
+func DetectLanguage(ctx context.Context, t *pipeline.Task) error {
+	if t.Language != "" {
+		return nil
+	}
+
+	doc := t.Document()
+	if doc == nil {
+		return nil
+	}
+
+	t.Language = lang.Detect(doc.Selection.Find("body").First().Text())
+	if t.Direction == "" {
+		t.Direction = lang.Direction(t.Language)
+	}
+
+	log.WithFields(log.Fields{
+		"task":     t.ID,
+		"module":   "metadata",
+		"language": t.Language,
+	}).Info("Detect language")
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package metadata
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// findLicense looks for the license in `rel=license` links, Creative Commons
+// markup and badges and in Dublin Core meta tags.
+//
+// Values are URLs or the name of the license, the SPDX identifier is
+// determined when the license is selected.
+func findLicense(m *metadata, doc *goquery.Document) {
+	doc.Find("a[rel~=license], link[rel~=license]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		href, _ := s.Attr("href")
+		setValue(m.license, "rel/license", href)
+		if goquery.NodeName(s) == "a" {
+			setValue(m.license, "rel/license-text", s.Text())
+		}
+		return m.license["rel/license"] == ""
+	})
+
+	// Creative Commons RDFa
+	doc.Find("[property~='cc:license'], [property~='dct:license'], [property~='dcterms:license']").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		for _, attr := range []string{"href", "resource", "content"} {
+			v, _ := s.Attr(attr)
+			setValue(m.license, "cc/license", v)
+		}
+		return m.license["cc/license"] == ""
+	})
+	doc.Find("[property~='cc:attributionName']").First().Each(func(i int, s *goquery.Selection) {
+		setValue(m.rightsHolder, "cc/attributionName", s.AttrOr("content", s.Text()))
+	})
+
+	// badge images link to the license
+	doc.Find("img[src]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		src, _ := s.Attr("src")
+		setValue(m.license, "cc/badge", badgeLicense(src))
+		return m.license["cc/badge"] == ""
+	})
+
+	doc.Find("meta[name]").Each(func(i int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		content, _ := s.Attr("content")
+		key := citationKey(name)
+		switch key {
+		case "dcterms.license", "dc.rights", "dcterms.rights", "license", "rights":
+			setFirst(m.license, key, content)
+		case "dcterms.rightsholder":
+			setFirst(m.rightsHolder, key, content)
+		case "copyright":
+			holder, year := parseCopyright(content)
+			setFirst(m.rightsHolder, key, holder)
+			setFirst(m.copyrightYear, key, year)
+		}
+	})
+}
+
+var (
+	licensePref = []string{
+		"ld/license",
+		"md/license",
+		"rel/license",
+		"rel/license-text",
+		"cc/license",
+		"cc/badge",
+		"dcterms.license",
+		"dc.rights",
+		"dcterms.rights",
+		"license",
+		"rights",
+	}
+	rightsHolderPref = []string{
+		"ld/copyrightHolder",
+		"md/copyrightHolder",
+		"cc/attributionName",
+		"dcterms.rightsholder",
+		"copyright",
+	}
+	copyrightYearPref = []string{
+		"ld/copyrightYear",
+		"md/copyrightYear",
+		"copyright",
+	}
+)
+
+// selectLicense creates the license from the collected values.
+// A copyright notice without a license is kept as well.
+// Returns nil if neither was found.
+func selectLicense(m *metadata) *pipeline.License {
+	l := &pipeline.License{
+		Holder: selectValue(m.rightsHolder, rightsHolderPref),
+		Year:   selectValue(m.copyrightYear, copyrightYearPref),
+	}
+
+	for _, k := range licensePref {
+		v, ok := m.license[k]
+		if !ok {
+			continue
+		}
+
+		if isURL(v) {
+			if l.URL == "" {
+				l.URL = v
+			}
+		} else if l.Name == "" {
+			l.Name = v
+		}
+
+		if l.ID == "" {
+			l.ID = spdxID(v)
+		}
+	}
+
+	if l.URL == "" && l.Name == "" && l.Holder == "" && l.Year == "" {
+		return nil
+	}
+
+	if l.Name == "" {
+		l.Name = licenseName(l.ID)
+	}
+	return l
+}
+
+var (
+	ccURL   = regexp.MustCompile(`(?i)creativecommons\.org/(licenses|publicdomain)/([a-z-]+)/(\d\.\d)`)
+	ccBadge = regexp.MustCompile(`(?i)(i\.creativecommons\.org|mirrors\.creativecommons\.org/presskit/buttons/[^/]+|licensebuttons\.net)/(l|p)/([a-z-]+)/(\d\.\d)/`)
+	ccShort = regexp.MustCompile(`(?i)\bCC[\s-]+(BY(?:[\s-]+(?:NC|SA|ND))*)[\s-]+(\d\.\d)`)
+	ccLong  = regexp.MustCompile(`(?i)creative\s+commons\s+(attribution(?:[\s-]+(?:non-?commercial|share-?alike|no-?deriv(?:ative)?s))*)\s+(?:license\s+)?(\d\.\d)`)
+	ccZero  = regexp.MustCompile(`(?i)\bCC0\b|\bCC[\s-]zero\b|creative\s+commons\s+zero`)
+	gnuURL  = regexp.MustCompile(`(?i)gnu\.org/licenses/(gpl|lgpl|agpl|fdl)-(\d\.\d)`)
+	osiURL  = regexp.MustCompile(`(?i)opensource\.org/licenses/([a-z0-9.-]+)`)
+	apache  = regexp.MustCompile(`(?i)apache\.org/licenses/LICENSE-2\.0|apache\s+license,?\s+(version\s+)?2\.0`)
+	mit     = regexp.MustCompile(`(?i)\bMIT\s+license\b`)
+)
+
+// spdxID returns the SPDX identifier for a license URL or name.
+// Returns an empty string if the license is not recognized.
+//
+// The jurisdiction of ported Creative Commons licenses is ignored.
+func spdxID(s string) string {
+	if match := ccURL.FindStringSubmatch(s); match != nil {
+		kind, version := strings.ToLower(match[2]), match[3]
+		if strings.ToLower(match[1]) == "publicdomain" {
+			switch kind {
+			case "zero":
+				return "CC0-" + version
+			case "mark":
+				return "CC-PDM-" + version
+			}
+			return ""
+		}
+		return "CC-" + strings.ToUpper(kind) + "-" + version
+	}
+
+	if match := ccShort.FindStringSubmatch(s); match != nil {
+		parts := strings.FieldsFunc(strings.ToUpper(match[1]), func(r rune) bool {
+			return r == ' ' || r == '-'
+		})
+		return "CC-" + strings.Join(parts, "-") + "-" + match[2]
+	}
+
+	if match := ccLong.FindStringSubmatch(s); match != nil {
+		return "CC-" + ccElements(match[1]) + "-" + match[2]
+	}
+
+	if ccZero.MatchString(s) {
+		return "CC0-1.0"
+	}
+
+	if match := gnuURL.FindStringSubmatch(s); match != nil {
+		kind := strings.ToUpper(match[1])
+		if kind == "FDL" {
+			kind = "GFDL"
+		}
+		return kind + "-" + match[2]
+	}
+
+	if apache.MatchString(s) {
+		return "Apache-2.0"
+	}
+
+	if match := osiURL.FindStringSubmatch(s); match != nil {
+		id := strings.TrimSuffix(match[1], ".php")
+		for _, known := range osiLicenses {
+			if strings.EqualFold(id, known) {
+				return known
+			}
+		}
+	}
+
+	if mit.MatchString(s) {
+		return "MIT"
+	}
+
+	return ""
+}
+
+// licenses with URLs like https://opensource.org/licenses/MIT
+var osiLicenses = []string{
+	"MIT",
+	"Apache-2.0",
+	"BSD-2-Clause",
+	"BSD-3-Clause",
+	"MPL-2.0",
+	"ISC",
+	"0BSD",
+}
+
+// ccElements turns "Attribution-NonCommercial-ShareAlike" into "BY-NC-SA".
+func ccElements(s string) string {
+	s = strings.ToLower(s)
+	parts := []string{"BY"}
+	if strings.Contains(s, "commercial") {
+		parts = append(parts, "NC")
+	}
+	if strings.Contains(s, "share") {
+		parts = append(parts, "SA")
+	}
+	if strings.Contains(s, "deriv") {
+		parts = append(parts, "ND")
+	}
+	return strings.Join(parts, "-")
+}
+
+// badgeLicense returns the license URL for a Creative Commons badge image,
+// e.g. "https://i.creativecommons.org/l/by-sa/4.0/88x31.png".
+func badgeLicense(src string) string {
+	match := ccBadge.FindStringSubmatch(src)
+	if match == nil {
+		return ""
+	}
+
+	kind, version := strings.ToLower(match[3]), match[4]
+	if strings.ToLower(match[2]) == "p" {
+		return "https://creativecommons.org/publicdomain/" + kind + "/" + version + "/"
+	}
+	return "https://creativecommons.org/licenses/" + kind + "/" + version + "/"
+}
+
+// licenseName returns a short name for a license with the given SPDX
+// identifier, e.g. "CC BY-SA 4.0" for "CC-BY-SA-4.0".
+func licenseName(id string) string {
+	if strings.HasPrefix(id, "CC-") {
+		parts := strings.Split(strings.TrimPrefix(id, "CC-"), "-")
+		version := parts[len(parts)-1]
+		return "CC " + strings.Join(parts[:len(parts)-1], "-") + " " + version
+	}
+	if strings.HasPrefix(id, "CC0-") {
+		return "CC0 " + strings.TrimPrefix(id, "CC0-")
+	}
+	return id
+}
+
+var (
+	copyrightWords = regexp.MustCompile(`(?i)©|\(c\)|copyright|all\s+rights\s+reserved\.?`)
+	years          = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+)
+
+// parseCopyright reads holder and year from a copyright notice like
+// "© 2019-2021 Example Inc. All rights reserved."
+// For a range of years, the last one is used.
+func parseCopyright(s string) (string, string) {
+	found := years.FindAllString(s, -1)
+	year := ""
+	if len(found) > 0 {
+		year = found[len(found)-1]
+	}
+
+	holder := copyrightWords.ReplaceAllString(s, " ")
+	holder = years.ReplaceAllString(holder, " ")
+	holder = strings.Trim(strings.TrimSpace(holder), "-–, ")
+	holder = strings.Join(strings.Fields(holder), " ")
+	return holder, year
+}
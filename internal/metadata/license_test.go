@@ -0,0 +1,140 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestSPDX(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]string{
+		"https://creativecommons.org/licenses/by/4.0/":         "CC-BY-4.0",
+		"http://creativecommons.org/licenses/by-nc-sa/3.0/de/": "CC-BY-NC-SA-3.0",
+		"https://creativecommons.org/publicdomain/zero/1.0/":   "CC0-1.0",
+		"https://creativecommons.org/publicdomain/mark/1.0/":   "CC-PDM-1.0",
+		"CC BY-SA 4.0": "CC-BY-SA-4.0",
+		"cc-by-nd 2.0": "CC-BY-ND-2.0",
+		"Creative Commons Attribution-NonCommercial-NoDerivatives 4.0 International": "CC-BY-NC-ND-4.0",
+		"Creative Commons Attribution 4.0 International License":                     "CC-BY-4.0",
+		"Released under CC0":                          "CC0-1.0",
+		"https://www.gnu.org/licenses/fdl-1.3.html":   "GFDL-1.3",
+		"https://www.apache.org/licenses/LICENSE-2.0": "Apache-2.0",
+		"https://opensource.org/licenses/MIT":         "MIT",
+		"The MIT License":                             "MIT",
+		"All rights reserved":                         "",
+		"Creative Commons":                            "",
+	}
+	for s, expected := range cases {
+		assert.Equal(expected, spdxID(s), s)
+	}
+}
+
+func TestCopyright(t *testing.T) {
+	assert := assert.New(t)
+
+	holder, year := parseCopyright("© 2019-2021 Example Inc. All rights reserved.")
+	assert.Equal("Example Inc.", holder)
+	assert.Equal("2021", year)
+
+	holder, year = parseCopyright("Copyright (c) Jane Doe")
+	assert.Equal("Jane Doe", holder)
+	assert.Equal("", year)
+}
+
+func TestLicense(t *testing.T) {
+	assert := assert.New(t)
+
+	// rel=license with a badge
+	task := &pipeline.Task{URL: "https://example.com/post"}
+	task.SetHTML(`<html><head></head><body>
+        <article><p>Content</p></article>
+        <footer>
+            <a rel="license" href="http://creativecommons.org/licenses/by-sa/4.0/">
+                <img alt="Creative Commons License" src="https://i.creativecommons.org/l/by-sa/4.0/88x31.png"/>
+            </a>
+            <span property="cc:attributionName">Jane Doe</span>
+        </footer>
+    </body></html>`)
+
+	err := ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	if assert.NotNil(task.License) {
+		assert.Equal("CC-BY-SA-4.0", task.License.ID)
+		assert.Equal("CC BY-SA 4.0", task.License.Name)
+		assert.Equal("http://creativecommons.org/licenses/by-sa/4.0/", task.License.URL)
+		assert.Equal("Jane Doe", task.License.Holder)
+	}
+
+	// badge only
+	task = &pipeline.Task{URL: "https://example.com/post"}
+	task.SetHTML(`<html><head></head><body>
+        <img src="https://licensebuttons.net/l/by-nc/3.0/88x31.png"/>
+    </body></html>`)
+
+	err = ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	if assert.NotNil(task.License) {
+		assert.Equal("CC-BY-NC-3.0", task.License.ID)
+		assert.Equal("https://creativecommons.org/licenses/by-nc/3.0/", task.License.URL)
+	}
+
+	// JSON-LD
+	task = &pipeline.Task{URL: "https://example.com/post"}
+	task.SetHTML(`<html><head>
+        <script type="application/ld+json">{
+            "@context": "https://schema.org",
+            "@type": "BlogPosting",
+            "headline": "A Post",
+            "license": "https://creativecommons.org/publicdomain/zero/1.0/",
+            "copyrightHolder": {"@type": "Organization", "name": "Example"},
+            "copyrightYear": 2020
+        }</script>
+        <meta name="copyright" content="© 2019 Someone Else">
+    </head><body></body></html>`)
+
+	err = ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	if assert.NotNil(task.License) {
+		assert.Equal("CC0-1.0", task.License.ID)
+		assert.Equal("CC0 1.0", task.License.Name)
+		assert.Equal("Example", task.License.Holder)
+		assert.Equal("2020", task.License.Year)
+	}
+
+	// Dublin Core text
+	task = &pipeline.Task{URL: "https://example.com/post"}
+	task.SetHTML(`<html><head>
+        <meta name="DC.rights" content="All rights reserved">
+    </head><body></body></html>`)
+
+	err = ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	if assert.NotNil(task.License) {
+		assert.Equal("", task.License.ID)
+		assert.Equal("All rights reserved", task.License.Name)
+	}
+
+	// copyright only
+	task = &pipeline.Task{URL: "https://example.com/post"}
+	task.SetHTML(`<html><head><meta name="copyright" content="© 2019 Someone"></head><body></body></html>`)
+	err = ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	if assert.NotNil(task.License) {
+		assert.Equal("", task.License.Name)
+		assert.Equal("", task.License.URL)
+		assert.Equal("Someone", task.License.Holder)
+		assert.Equal("2019", task.License.Year)
+	}
+
+	// none
+	task = &pipeline.Task{URL: "https://example.com/post"}
+	task.SetHTML(`<html><head></head><body><p>Content</p></body></html>`)
+	err = ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	assert.Nil(task.License)
+}
@@ -9,11 +9,22 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/akeil/scrapen/internal/lang"
 	"github.com/akeil/scrapen/internal/pipeline"
 )
 
 // ReadMetadata reads general metadata from the documents <head>.
 func ReadMetadata(ctx context.Context, t *pipeline.Task) error {
+	return readMetadata(ctx, t, false)
+}
+
+// ReadMetadataOEmbed is like ReadMetadata and also reads the oEmbed
+// representation of the page, which takes an additional request.
+func ReadMetadataOEmbed(ctx context.Context, t *pipeline.Task) error {
+	return readMetadata(ctx, t, true)
+}
+
+func readMetadata(ctx context.Context, t *pipeline.Task, withOEmbed bool) error {
 	log.WithFields(log.Fields{
 		"task":   t.ID,
 		"module": "metadata",
@@ -26,9 +37,24 @@ func ReadMetadata(ctx context.Context, t *pipeline.Task) error {
 	findMeta(m, doc)
 	findLink(m, doc)
 	findTitle(m, doc)
+	findCitation(m, doc)
+	findLicense(m, doc)
+	findJSONLD(m, doc)
+	findMicrodata(m, doc)
+	findMicroformats(m, doc, t.ContentURL())
+	findAuthorLinks(m, doc)
+	findBylines(m, doc)
+	findTimes(m, doc)
+	findURLDate(m, t.ContentURL())
+	findLanguage(m, doc, t)
+	if withOEmbed {
+		findOEmbed(ctx, m, doc, t)
+	}
 
 	setMetadata(m, t)
 	setSite(t)
+	// requires the site name
+	setTitle(t)
 
 	return nil
 }
@@ -50,6 +76,15 @@ func findMeta(m *metadata, doc *goquery.Document) {
 			return
 		}
 
+		if isCitationMeta(name) {
+			return
+		}
+
+		if contains(titlePref, name) && name != "title" {
+			m.title[name] = content
+			return
+		}
+
 		if contains(descriptionPref, name) {
 			m.description[name] = content
 			return
@@ -66,7 +101,12 @@ func findMeta(m *metadata, doc *goquery.Document) {
 		}
 
 		if contains(authorPref, name) {
-			m.author[name] = content
+			m.addAuthors(name, metaAuthors(content)...)
+			return
+		}
+
+		if contains(modifiedPref, name) {
+			m.modified[name] = content
 			return
 		}
 
@@ -79,6 +119,36 @@ func findMeta(m *metadata, doc *goquery.Document) {
 			m.siteName[name] = content
 			return
 		}
+
+		if contains(keywordKeys, name) {
+			m.keywords = append(m.keywords, splitList(content)...)
+			return
+		}
+
+		if contains(themeColorPref, name) {
+			// the first value is the default, others are for dark mode
+			media, _ := s.Attr("media")
+			_, exists := m.themeColor[name]
+			if !exists && !strings.Contains(media, "dark") {
+				setValue(m.themeColor, name, content)
+			}
+			return
+		}
+
+		if contains(logoPref, name) {
+			setValue(m.logo, name, content)
+			return
+		}
+
+		if contains(languagePref, name) {
+			setValue(m.language, name, content)
+			return
+		}
+
+		if contains(sectionPref, name) {
+			setValue(m.section, name, content)
+			return
+		}
 	})
 }
 
@@ -100,13 +170,37 @@ func findLink(m *metadata, doc *goquery.Document) {
 	})
 }
 
-func findTitle(m *metadata, doc *goquery.Document) {
-	doc.Selection.Find("title").First().Each(func(i int, s *goquery.Selection) {
-		m.title = strings.TrimSpace(s.Text())
-	})
-}
-
+// Preference lists for metadata values.
+//
+// Keys with the "ld/" prefix are read from JSON-LD, keys with the "md/" prefix
+// from microdata or RDFa and keys with the "mf/" prefix from microformats2.
+// Keys with the "oembed/" prefix come from the page's oEmbed representation.
+// As these are structured data, they are preferred
+// for title, author and dates. The values from <meta> tags are preferred for
+// description, image and site name, because these are typically curated for
+// sharing.
 var (
+	// Titles from the content are added by readability and ResolveTitle.
+	titlePref = []string{
+		"ld/headline",
+		"md/headline",
+		"citation_title",
+		"prism.title",
+		"dc.title",
+		"og:title",
+		"twitter:title",
+		"parsely-title",
+		"sailthru.title",
+		"krux:title",
+		"readability",
+		"oembed/title",
+		"title",
+		"h1",
+		"ld/name",
+		"md/name",
+		// the implied name of a note is its whole text
+		"mf/name",
+	}
 	descriptionPref = []string{
 		"description",
 		"og:description",
@@ -114,6 +208,11 @@ var (
 		"sailthru.description",
 		"preview",
 		"krux:description",
+		"ld/description",
+		"md/description",
+		"mf/summary",
+		"citation_abstract",
+		"dc.description",
 	}
 	imagePref = []string{
 		"og:image:secure_url",
@@ -123,44 +222,136 @@ var (
 		"link/image_src",
 		"twitter:image",
 		"twitter:image:src",
+		"ld/image",
+		"md/image",
+		"mf/featured",
+		"mf/photo",
+		"oembed/photo",
+		"oembed/thumbnail",
 	}
 	urlPref = []string{
 		"link/canonical",
 		"canonicalURL",
 		"og:url",
 		"twitter:url",
+		"ld/url",
+		"md/url",
+		"mf/url",
 	}
 	authorPref = []string{
+		"ld/author",
+		"md/author",
+		"mf/author",
+		"citation_author",
+		"citation_authors",
+		"prism.creator",
+		"oembed/author",
+		"rel/author",
+		"byline",
 		"author",
 		"article:author",
 		"book:author",
-		"twitter:creator",
 		"parsely-author",
 		"sailthru.author",
+		"dc.creator",
 	}
 	pubDatePref = []string{
+		"ld/datePublished",
+		"md/datePublished",
+		"mf/published",
+		"citation_publication_date",
+		"citation_online_date",
+		"citation_date",
+		"prism.publicationdate",
+		"prism.coverdate",
 		"article:published_time",
-		"article:modified_time",
-		"og:updated_time",
+		"og:published_time",
+		"article:published",
+		"datePublished",
+		"pubdate",
+		"publishdate",
+		"publish-date",
 		"date",
-		"last-modified",
+		"dc.date.issued",
+		"dcterms.issued",
+		"dcterms.created",
+		"dc.date",
 		"iso-8601-publish-date",
 		"parsely-pub-date",
 		"sailthru.date",
+		"time/published",
+		"url/date",
+	}
+	modifiedPref = []string{
+		"ld/dateModified",
+		"md/dateModified",
+		"mf/updated",
+		"article:modified_time",
+		"og:updated_time",
+		"article:modified",
+		"dateModified",
+		"last-modified",
+		"dc.date.modified",
+		"dcterms.modified",
+		"prism.modificationdate",
+		"time/modified",
 	}
 	siteNamePref = []string{
 		"og:site_name",
+		"ld/publisher",
+		"md/publisher",
 		"publisher",
 		"twitter:creator",
 		"twitter:publisher",
-		"DC.publisher",
+		"dc.publisher",
+		"dcterms.publisher",
+		"oembed/provider",
+	}
+	logoPref = []string{
+		"ld/logo",
+		"md/logo",
+		"og:logo",
+	}
+	themeColorPref = []string{
+		"theme-color",
+		"msapplication-TileColor",
+		"msapplication-navbutton-color",
+	}
+	languagePref = []string{
+		"ld/inLanguage",
+		"md/inLanguage",
+		"html/lang",
+		"http-equiv/content-language",
+		"http/content-language",
+		"body/lang",
+		"og:locale",
+		"language",
+		"dc.language",
+		"dcterms.language",
+		"citation_language",
+	}
+	sectionPref = []string{
+		"ld/articleSection",
+		"md/articleSection",
+		"article:section",
+		"parsely-section",
+		"section",
+	}
+	// all of these are used
+	keywordKeys = []string{
+		"keywords",
+		"news_keywords",
+		"article:tag",
+		"book:tag",
+		"video:tag",
+		"parsely-tags",
+		"sailthru.tags",
 	}
-	// title: og:title, twitter:title, parsely-title, sailthru.title, krux:title
 )
 
 func setMetadata(m *metadata, t *pipeline.Task) {
-	if m.title != "" {
-		t.Title = m.title
+	for k, v := range m.title {
+		t.AddTitle(k, v)
 	}
 
 	for _, k := range descriptionPref {
@@ -188,26 +379,63 @@ func setMetadata(m *metadata, t *pipeline.Task) {
 		}
 	}
 
-	for _, k := range authorPref {
-		v, ok := m.author[k]
+	authors := selectAuthors(m)
+	if len(authors) > 0 {
+		t.Authors = authors
+		t.Author = authorNames(authors)
+	}
+
+	t.PubDate, t.PubDatePrecision = selectTime(m.pubDate, pubDatePref)
+	t.ModifiedDate, _ = selectTime(m.modified, modifiedPref)
+	// probably a mixup
+	if t.PubDate != nil && t.ModifiedDate != nil && t.ModifiedDate.Before(*t.PubDate) {
+		t.ModifiedDate = nil
+	}
+
+	if len(m.keywords) > 0 {
+		t.Keywords = dedupe(m.keywords)
+	}
+
+	for _, k := range logoPref {
+		v, ok := m.logo[k]
 		if ok {
-			t.Author = v
+			t.SiteLogo = v
 			break
 		}
 	}
 
-	for _, k := range pubDatePref {
-		v, ok := m.pubDate[k]
+	for _, k := range themeColorPref {
+		v, ok := m.themeColor[k]
 		if ok {
-			ts := parseTime(v)
-			if ts != nil {
-				utc := ts.UTC()
-				t.PubDate = &utc
-			}
+			t.ThemeColor = v
 			break
 		}
 	}
 
+	t.Language = selectLanguage(m)
+	t.Direction = m.direction
+	if t.Direction == "" {
+		t.Direction = lang.Direction(t.Language)
+	}
+
+	for _, k := range sectionPref {
+		v, ok := m.section[k]
+		if ok {
+			t.Section = v
+			break
+		}
+	}
+
+	if len(m.inReplyTo) > 0 {
+		t.InReplyTo = m.inReplyTo
+	}
+	if len(m.likeOf) > 0 {
+		t.LikeOf = m.likeOf
+	}
+
+	t.Citation = selectCitation(m, t)
+	t.License = selectLicense(m)
+
 	for _, k := range siteNamePref {
 		v, ok := m.siteName[k]
 		if ok {
@@ -217,6 +445,23 @@ func setMetadata(m *metadata, t *pipeline.Task) {
 	}
 }
 
+// selectTime returns the first date that can be parsed, in UTC,
+// and its precision if the date is incomplete.
+func selectTime(values map[string]string, pref []string) (*time.Time, string) {
+	for _, k := range pref {
+		v, ok := values[k]
+		if !ok {
+			continue
+		}
+		ts := parseTime(v)
+		if ts != nil {
+			utc := ts.UTC()
+			return &utc, datePrecision(v)
+		}
+	}
+	return nil, ""
+}
+
 var prefixes = []string{
 	"www.",
 	"www1.",
@@ -241,39 +486,49 @@ func setSite(t *pipeline.Task) {
 }
 
 type metadata struct {
-	title       string
+	title       map[string]string
 	description map[string]string
 	image       map[string]string
 	url         map[string]string
-	author      map[string]string
 	pubDate     map[string]string
+	modified    map[string]string
 	siteName    map[string]string
+	section     map[string]string
+	language    map[string]string
+	logo        map[string]string
+	themeColor  map[string]string
+	direction   string
+	keywords    []string
+	authors     map[string][]pipeline.Author
+	inReplyTo   []string
+	likeOf      []string
+	citation    map[string]string
+	// license holds URLs or names of licenses
+	license       map[string]string
+	rightsHolder  map[string]string
+	copyrightYear map[string]string
 }
 
 func newMetadata() *metadata {
 	return &metadata{
-		description: make(map[string]string),
-		image:       make(map[string]string),
-		url:         make(map[string]string),
-		author:      make(map[string]string),
-		pubDate:     make(map[string]string),
-		siteName:    make(map[string]string),
+		title:         make(map[string]string),
+		description:   make(map[string]string),
+		image:         make(map[string]string),
+		url:           make(map[string]string),
+		pubDate:       make(map[string]string),
+		modified:      make(map[string]string),
+		siteName:      make(map[string]string),
+		section:       make(map[string]string),
+		language:      make(map[string]string),
+		logo:          make(map[string]string),
+		themeColor:    make(map[string]string),
+		keywords:      make([]string, 0),
+		authors:       make(map[string][]pipeline.Author),
+		inReplyTo:     make([]string, 0),
+		likeOf:        make([]string, 0),
+		citation:      make(map[string]string),
+		license:       make(map[string]string),
+		rightsHolder:  make(map[string]string),
+		copyrightYear: make(map[string]string),
 	}
 }
-
-var layouts = []string{
-	time.RFC3339,
-	"2006-01-02 15:04:05Z",
-}
-
-func parseTime(v string) *time.Time {
-	for _, l := range layouts {
-		t, err := time.Parse(l, v)
-		if err == nil {
-			if !t.IsZero() {
-				return &t
-			}
-		}
-	}
-	return nil
-}
@@ -252,3 +252,98 @@ func readMeta(html string) (*pipeline.Task, error) {
 
 	return t, ReadMetadata(context.TODO(), t)
 }
+
+func TestKeywords(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><head>
+		<meta name="keywords" content="Politics, Berlin;  Election 2021 " />
+		<meta name="news_keywords" content="politics; Bundestag" />
+		<meta property="article:tag" content="#Berlin" />
+		<meta property="article:tag" content="Climate" />
+		<meta name="parsely-tags" content="climate,energy" />
+		<meta name="parsely-section" content="News" />
+		<meta property="article:section" content="Politik" />
+		<script type="application/ld+json">
+		{
+			"@type": "NewsArticle",
+			"keywords": ["Energy", "Green Party"],
+			"articleSection": ["Politics", "Germany"]
+		}
+		</script>
+	</head><body>foo</body></html>`
+
+	i, err := readMeta(html)
+	assert.Nil(err)
+	assert.Equal([]string{
+		"Politics",
+		"Berlin",
+		"Election 2021",
+		"Bundestag",
+		"Climate",
+		"energy",
+		"Green Party",
+	}, i.Keywords)
+	assert.Equal("Politics", i.Section)
+
+	html = `<html><head>
+		<meta name="parsely-section" content="News" />
+		<meta property="article:section" content="Politik" />
+	</head><body>foo</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("Politik", i.Section)
+	assert.Nil(i.Keywords)
+}
+
+func TestLanguage(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html lang="de_DE"><head>
+		<meta property="og:locale" content="en_US" />
+	</head><body>foo</body></html>`
+
+	i, err := readMeta(html)
+	assert.Nil(err)
+	assert.Equal("de-DE", i.Language)
+	assert.Equal("ltr", i.Direction)
+
+	// Content-Language header, set by fetch
+	html = `<html><head>
+		<meta property="og:locale" content="en_US" />
+	</head><body dir="rtl">foo</body></html>`
+
+	i = &pipeline.Task{Language: "he"}
+	i.SetHTML(html)
+	err = ReadMetadata(context.TODO(), i)
+	assert.Nil(err)
+	assert.Equal("he", i.Language)
+	assert.Equal("rtl", i.Direction)
+
+	// JSON-LD
+	html = `<html lang="invalid value"><head>
+		<script type="application/ld+json">
+		{"@type": "Article", "inLanguage": {"@type": "Language", "name": "Arabic", "alternateName": "ar"}}
+		</script>
+	</head><body>foo</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("ar", i.Language)
+	assert.Equal("rtl", i.Direction)
+
+	// detection from content
+	html = `<html><body><p>Die Bundesregierung will an dem Plan festhalten,
+		doch viele Menschen im Land halten die neuen Regeln nicht für gerecht.</p>
+	</body></html>`
+
+	i, err = readMeta(html)
+	assert.Nil(err)
+	assert.Equal("", i.Language)
+
+	err = DetectLanguage(context.TODO(), i)
+	assert.Nil(err)
+	assert.Equal("de", i.Language)
+	assert.Equal("ltr", i.Direction)
+}
@@ -0,0 +1,88 @@
+package metadata
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/akeil/scrapen/internal/microformats"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// findMicroformats reads metadata from a microformats2 `h-entry`.
+//
+// The values are stored with an "mf/" prefix. Relationships to other posts
+// (replies, likes) are only available from microformats.
+func findMicroformats(m *metadata, doc *goquery.Document, pageURL string) {
+	e := microformats.MainEntry(microformats.Parse(doc, pageURL), pageURL)
+	if e == nil {
+		return
+	}
+
+	setValue(m.title, "mf/name", e.String("name"))
+	setValue(m.description, "mf/summary", e.String("summary"))
+	setValue(m.pubDate, "mf/published", e.String("published"))
+	setValue(m.modified, "mf/updated", e.String("updated"))
+	setValue(m.url, "mf/url", e.String("url"))
+	setValue(m.image, "mf/featured", e.String("featured"))
+	setValue(m.image, "mf/photo", e.String("photo"))
+
+	for _, v := range e.Properties["author"] {
+		m.addAuthors("mf/author", mfAuthor(v))
+	}
+
+	for _, c := range e.Strings("category") {
+		// categories can also be URLs or person tags
+		if strings.Contains(c, "://") {
+			continue
+		}
+		m.keywords = append(m.keywords, splitList(c)...)
+	}
+
+	m.inReplyTo = append(m.inReplyTo, mfURLs(e, "in-reply-to")...)
+	m.likeOf = append(m.likeOf, mfURLs(e, "like-of")...)
+}
+
+// mfAuthor reads an author from a `h-card` or from a plain value.
+func mfAuthor(v interface{}) pipeline.Author {
+	switch val := v.(type) {
+	case *microformats.Item:
+		a := pipeline.Author{
+			Name:  val.String("name"),
+			URL:   val.String("url"),
+			Image: val.String("photo"),
+		}
+		if a.Name == "" && val.Value != a.URL {
+			a.Name = val.Value
+		}
+		return a
+	case string:
+		if isURL(val) {
+			return pipeline.Author{URL: val}
+		}
+		return pipeline.Author{Name: val}
+	}
+	return pipeline.Author{}
+}
+
+// mfURLs returns the URLs for a property that references other posts,
+// e.g. `u-in-reply-to` which may be a plain URL or a nested `h-cite`.
+func mfURLs(e *microformats.Item, name string) []string {
+	urls := make([]string, 0)
+	for _, v := range e.Properties[name] {
+		var u string
+		switch val := v.(type) {
+		case *microformats.Item:
+			u = val.String("url")
+			if u == "" {
+				u = val.Value
+			}
+		case string:
+			u = val
+		}
+		if u != "" && !contains(urls, u) {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
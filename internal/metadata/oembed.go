@@ -0,0 +1,53 @@
+package metadata
+
+import (
+	"context"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/oembed"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// findOEmbed reads metadata from the oEmbed representation of the page.
+//
+// The endpoint is taken from the `<link>` the page advertises or, if there is
+// none, from the registry of known providers.
+// The values are stored with an "oembed/" prefix.
+func findOEmbed(ctx context.Context, m *metadata, doc *goquery.Document, t *pipeline.Task) {
+	endpoint := oembed.Discover(doc)
+	if endpoint != "" {
+		resolved, err := t.ResolveURL(endpoint)
+		if err == nil {
+			endpoint = resolved
+		}
+	} else {
+		endpoint = oembed.Endpoint(t.ContentURL())
+	}
+	if endpoint == "" {
+		return
+	}
+
+	r, err := oembed.Fetch(ctx, t.HTTPClient(), endpoint)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"task":   t.ID,
+			"module": "metadata",
+			"url":    endpoint,
+			"error":  err,
+		}).Warning("Failed to read oEmbed")
+		return
+	}
+
+	setValue(m.title, "oembed/title", r.Title)
+	setValue(m.siteName, "oembed/provider", r.ProviderName)
+	setValue(m.image, "oembed/thumbnail", r.ThumbnailURL)
+	if r.Type == "photo" {
+		setValue(m.image, "oembed/photo", r.URL)
+	}
+	m.addAuthors("oembed/author", pipeline.Author{
+		Name: r.AuthorName,
+		URL:  r.AuthorURL,
+	})
+}
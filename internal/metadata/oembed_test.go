@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestOEmbed(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/oembed":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{
+				"type": "video",
+				"version": "1.0",
+				"title": "The Video",
+				"author_name": "Jane Doe",
+				"author_url": "https://example.com/jane",
+				"provider_name": "Example Videos",
+				"thumbnail_url": "https://example.com/thumb.jpg"
+			}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	task := &pipeline.Task{URL: srv.URL + "/watch/1", Client: srv.Client()}
+	task.SetHTML(`<html><head>
+        <title>The Video - Example Videos</title>
+        <link rel="alternate" type="application/json+oembed" href="/oembed?url=x">
+    </head><body><p>Content</p></body></html>`)
+
+	err := ReadMetadataOEmbed(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal(1, requests)
+	assert.Equal("The Video", task.Title)
+	assert.Equal("Jane Doe", task.Author)
+	if assert.Len(task.Authors, 1) {
+		assert.Equal("https://example.com/jane", task.Authors[0].URL)
+	}
+	assert.Equal("https://example.com/thumb.jpg", task.ImageURL)
+	assert.Equal("Example Videos", task.SiteName)
+
+	// values from the document are preferred, a failed request is ignored
+	task = &pipeline.Task{URL: srv.URL + "/watch/2", Client: srv.Client()}
+	task.SetHTML(`<html><head>
+        <meta property="og:image" content="https://example.com/og.jpg">
+        <meta name="author" content="John Doe">
+        <link rel="alternate" type="application/json+oembed" href="/missing">
+    </head><body><p>Content</p></body></html>`)
+
+	err = ReadMetadataOEmbed(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal("John Doe", task.Author)
+	assert.Equal("https://example.com/og.jpg", task.ImageURL)
+
+	// no request without oEmbed
+	requests = 0
+	task = &pipeline.Task{URL: srv.URL + "/watch/1", Client: srv.Client()}
+	task.SetHTML(`<html><head>
+        <title>The Video - Example Videos</title>
+        <link rel="alternate" type="application/json+oembed" href="/oembed?url=x">
+    </head><body><p>Content</p></body></html>`)
+
+	err = ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal(0, requests)
+	assert.Equal("", task.Author)
+}
@@ -0,0 +1,287 @@
+package metadata
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/fetch"
+	"github.com/akeil/scrapen/internal/jsonld"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// FindPageType collects evidence for the type of the page from the original
+// document: `og:type`, JSON-LD types, the URL, link density and the number of
+// teaser headlines.
+//
+// This must run before the document is modified. ClassifyPage adds evidence
+// from the extracted content and decides.
+func FindPageType(ctx context.Context, t *pipeline.Task) error {
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "metadata",
+		"url":    t.ContentURL(),
+	}).Info("Collect page type evidence")
+
+	doc := t.Document()
+	if doc == nil {
+		return nil
+	}
+
+	p := &t.PageType
+	pageTypeFromMeta(p, doc)
+	pageTypeFromLD(p, jsonld.Parse(doc))
+	pageTypeFromURL(p, t.ContentURL())
+	pageTypeFromLayout(p, doc, indexType(t.ContentURL()))
+
+	return nil
+}
+
+func pageTypeFromMeta(p *pipeline.PageType, doc *goquery.Document) {
+	og := strings.ToLower(doc.Find("meta[property='og:type']").AttrOr("content", ""))
+	switch {
+	case strings.HasPrefix(og, "article"):
+		p.Add(pipeline.PageArticle, 2)
+	case strings.HasPrefix(og, "video"):
+		p.Add(pipeline.PageVideo, 2)
+	case strings.HasPrefix(og, "product"), og == "og:product":
+		p.Add(pipeline.PageProduct, 2)
+	}
+
+	if doc.Find("meta[property^='product:price'], meta[property^='og:price']").Length() > 0 {
+		p.Add(pipeline.PageProduct, 1)
+	}
+
+	if doc.Find("meta[name='twitter:card'][content=player]").Length() > 0 {
+		p.Add(pipeline.PageVideo, 1)
+	}
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	heading := strings.TrimSpace(doc.Find("h1").First().Text())
+	if fetch.IsNotFoundTitle(title) || fetch.IsNotFoundTitle(heading) {
+		p.Add(pipeline.PageError, 3)
+	}
+}
+
+func pageTypeFromLD(p *pipeline.PageType, g *jsonld.Graph) {
+	if g.First(articleTypes...) != nil {
+		p.Add(pipeline.PageArticle, 2)
+		return
+	}
+
+	if g.First("VideoObject") != nil {
+		p.Add(pipeline.PageVideo, 2)
+	}
+	if g.First("Product") != nil {
+		p.Add(pipeline.PageProduct, 2)
+	}
+	if g.First("CollectionPage", "ItemList", "SearchResultsPage") != nil {
+		p.Add(pipeline.PageListing, 2)
+	}
+}
+
+var (
+	listingPath = regexp.MustCompile(`(?i)/(tags?|category|categories|topics?|themen|rubrik|section|archives?|authors?|search|suche)(/|$)|/page/\d+/?$`)
+	articleSlug = regexp.MustCompile(`[a-z0-9]+(-[a-z0-9]+){3,}|\d{5,}|\.s?html?$`)
+	videoPath   = regexp.MustCompile(`(?i)/(videos?|watch|mediathek)(/|$)`)
+)
+
+func pageTypeFromURL(p *pipeline.PageType, s string) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if isRootPath(path) {
+		p.Add(pipeline.PageHomepage, 4)
+		return
+	}
+
+	if listingPath.MatchString(u.Path) || u.Query().Get("page") != "" {
+		p.Add(pipeline.PageListing, 1.5)
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	last := segments[len(segments)-1]
+	if articleSlug.MatchString(strings.ToLower(last)) || datePath.MatchString(u.Path) {
+		p.Add(pipeline.PageArticle, 1)
+	} else if len(segments) == 1 {
+		// e.g. "/sport/"
+		p.Add(pipeline.PageListing, 1)
+	}
+
+	if videoPath.MatchString(u.Path) {
+		p.Add(pipeline.PageVideo, 1)
+	}
+}
+
+func isRootPath(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, index := range []string{"/index.html", "/index.php", "/home"} {
+		path = strings.TrimSuffix(path, index)
+	}
+	return path == ""
+}
+
+// indexType returns the page type for pages with many links, which is the
+// homepage for the root URL and a listing otherwise.
+func indexType(s string) string {
+	u, err := url.Parse(s)
+	if err == nil && isRootPath(u.Path) {
+		return pipeline.PageHomepage
+	}
+	return pipeline.PageListing
+}
+
+// datePath matches dates in URLs like /2021/08/15/
+var datePath = regexp.MustCompile(`/(19|20)\d{2}/\d{1,2}/`)
+
+func pageTypeFromLayout(p *pipeline.PageType, doc *goquery.Document, index string) {
+	body := doc.Find("body").First().Clone()
+	body.Find("script, style, noscript").Remove()
+	p.PageWords = countWords(body.Text())
+
+	density := linkDensity(body)
+	switch {
+	case density > 0.5:
+		p.Add(index, 1.5)
+	case density < 0.2:
+		p.Add(pipeline.PageArticle, 0.5)
+	}
+
+	teasers := countTeasers(body)
+	switch {
+	case teasers >= 10:
+		p.Add(index, 2)
+	case teasers >= 5:
+		p.Add(index, 1)
+	}
+}
+
+// linkDensity is the share of text in links.
+func linkDensity(s *goquery.Selection) float64 {
+	total := textLength(s.Text())
+	if total == 0 {
+		return 0
+	}
+
+	links := 0
+	s.Find("a").Each(func(i int, a *goquery.Selection) {
+		links += textLength(a.Text())
+	})
+	return float64(links) / float64(total)
+}
+
+func textLength(s string) int {
+	return utf8.RuneCountInString(strings.Join(strings.Fields(s), " "))
+}
+
+// countTeasers counts headlines which link to another page.
+func countTeasers(s *goquery.Selection) int {
+	count := 0
+	s.Find("h2, h3, h4").Each(func(i int, h *goquery.Selection) {
+		if h.Find("a[href]").Length() > 0 || h.ParentsFiltered("a[href]").Length() > 0 {
+			count++
+		}
+	})
+	return count
+}
+
+// pages of other types are not rejected below this confidence
+const notArticleConfidence = 0.6
+
+// RejectNotArticle fails with a NotArticleError if the evidence from
+// FindPageType already shows that the page is not an article.
+//
+// This lets a scrape for articles only fail fast, before other pages or
+// embeds are fetched. Pages which are not clearly classified are left to
+// ClassifyPage, which also has the evidence from the content.
+func RejectNotArticle(ctx context.Context, t *pipeline.Task) error {
+	p := t.PageType
+	p.Decide()
+
+	if p.Type != pipeline.PageArticle && p.Confidence >= earlyNotArticleConfidence {
+		log.WithFields(log.Fields{
+			"task":       t.ID,
+			"module":     "metadata",
+			"url":        t.ContentURL(),
+			"type":       p.Type,
+			"confidence": p.Confidence,
+		}).Info("Rejected page")
+
+		t.PageType = p
+		return &pipeline.NotArticleError{
+			PageType:   p.Type,
+			Confidence: p.Confidence,
+		}
+	}
+
+	return nil
+}
+
+// without the evidence from the content, pages are rejected only above
+// this confidence
+const earlyNotArticleConfidence = 0.8
+
+// readability finds little content on pages with many words, but less than
+// this fraction, e.g. on a listing with teasers
+const (
+	minPageWords      = 200
+	maxExtractedWords = 150
+	extractedRatio    = 0.2
+)
+
+// ClassifyPage decides on the page type.
+//
+// Evidence from the extracted content is added to the evidence from
+// FindPageType: the link density of the content and how many of the words
+// on the page readability kept. If articlesOnly is set, the pipeline fails with a
+// NotArticleError for pages which are clearly not an article.
+func ClassifyPage(articlesOnly bool) pipeline.Pipeline {
+	return func(ctx context.Context, t *pipeline.Task) error {
+		p := &t.PageType
+
+		doc := t.Document()
+		if doc != nil {
+			content := doc.Find("body").First()
+			density := linkDensity(content)
+			switch {
+			case density > 0.4:
+				p.Add(indexType(t.ContentURL()), 1.5)
+			case density < 0.15 && t.WordCount >= 150:
+				p.Add(pipeline.PageArticle, 1)
+			}
+		}
+
+		// the outcome of readability, compared to the original page
+		if p.PageWords >= minPageWords && t.WordCount < maxExtractedWords &&
+			float64(t.WordCount) < float64(p.PageWords)*extractedRatio {
+			p.Add(indexType(t.ContentURL()), 1.5)
+		}
+
+		p.Decide()
+
+		log.WithFields(log.Fields{
+			"task":       t.ID,
+			"module":     "metadata",
+			"url":        t.ContentURL(),
+			"type":       p.Type,
+			"confidence": p.Confidence,
+		}).Info("Classified page")
+
+		if articlesOnly && p.Type != pipeline.PageArticle && p.Confidence >= notArticleConfidence {
+			return &pipeline.NotArticleError{
+				PageType:   p.Type,
+				Confidence: p.Confidence,
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,173 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func classify(url, html string, articlesOnly bool) (*pipeline.Task, error) {
+	task := &pipeline.Task{URL: url}
+	task.SetHTML(html)
+	err := FindPageType(context.TODO(), task)
+	if err != nil {
+		return task, err
+	}
+	task.WordCount = countWords(task.Document().Find("body").Text())
+	return task, ClassifyPage(articlesOnly)(context.TODO(), task)
+}
+
+func teasers(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteString(fmt.Sprintf(`<h2><a href="/story-%d">Headline number %d</a></h2>`, i, i))
+	}
+	return b.String()
+}
+
+func TestPageType(t *testing.T) {
+	assert := assert.New(t)
+
+	text := "<p>" + strings.Repeat("Some words in a paragraph. ", 60) + "</p>"
+
+	// article
+	task, err := classify("https://example.com/2021/08/15/a-long-article-slug", `<html><head>
+        <meta property="og:type" content="article">
+        <script type="application/ld+json">{
+            "@context": "https://schema.org",
+            "@type": "NewsArticle",
+            "headline": "Article"
+        }</script>
+    </head><body><h1>Article</h1>`+text+`</body></html>`, true)
+	assert.Nil(err)
+	assert.Equal(pipeline.PageArticle, task.PageType.Type)
+	assert.Greater(task.PageType.Confidence, 0.9)
+
+	// homepage with many teasers
+	task, err = classify("https://example.com/", `<html><head></head><body>`+teasers(12)+`</body></html>`, false)
+	assert.Nil(err)
+	assert.Equal(pipeline.PageHomepage, task.PageType.Type)
+	assert.Greater(task.PageType.Confidence, 0.6)
+
+	// listing
+	task, err = classify("https://example.com/category/sports", `<html><head></head><body>`+teasers(6)+`</body></html>`, false)
+	assert.Nil(err)
+	assert.Equal(pipeline.PageListing, task.PageType.Type)
+
+	// video
+	task, err = classify("https://example.com/videos/12345678", `<html><head>
+        <meta property="og:type" content="video.other">
+        <meta name="twitter:card" content="player">
+    </head><body><h1>Video</h1><p>A short description.</p></body></html>`, false)
+	assert.Nil(err)
+	assert.Equal(pipeline.PageVideo, task.PageType.Type)
+
+	// product
+	task, err = classify("https://shop.example.com/products/shoe", `<html><head>
+        <meta property="og:type" content="product">
+        <meta property="product:price:amount" content="49.99">
+        <script type="application/ld+json">{
+            "@context": "https://schema.org",
+            "@type": "Product",
+            "name": "Shoe"
+        }</script>
+    </head><body><h1>Shoe</h1><p>A shoe.</p></body></html>`, false)
+	assert.Nil(err)
+	assert.Equal(pipeline.PageProduct, task.PageType.Type)
+
+	// error page
+	task, err = classify("https://example.com/missing", `<html><head>
+        <title>Page not found</title>
+    </head><body><h1>404</h1><p>Sorry.</p></body></html>`, false)
+	assert.Nil(err)
+	assert.Equal(pipeline.PageError, task.PageType.Type)
+
+	// headlines which mention "not found" or 404
+	for _, headline := range []string{
+		"Missing hiker still not found",
+		"Route 404 closed for repairs",
+		"How to fix a 404 not found error in nginx",
+	} {
+		task, err = classify("https://example.com/news/"+strings.ReplaceAll(strings.ToLower(headline), " ", "-"), `<html><head>
+            <title>`+headline+`</title>
+        </head><body><h1>`+headline+`</h1>`+text+`</body></html>`, false)
+		assert.Nil(err)
+		assert.Equal(pipeline.PageArticle, task.PageType.Type, headline)
+	}
+
+	// no evidence
+	task, err = classify("https://example.com/some/page", `<html><head></head><body></body></html>`, true)
+	assert.Nil(err)
+	assert.Equal(pipeline.PageArticle, task.PageType.Type)
+}
+
+func TestClassifyExtracted(t *testing.T) {
+	assert := assert.New(t)
+
+	var b strings.Builder
+	for i := 0; i < 4; i++ {
+		b.WriteString(fmt.Sprintf(`<div><a href="/story-%d">Story %d</a><p>%s</p></div>`,
+			i, i, strings.Repeat("A summary of the story with some words. ", 10)))
+	}
+	html := `<html><head></head><body>` + b.String() + `</body></html>`
+
+	extracted := func(words int) *pipeline.Task {
+		task := &pipeline.Task{URL: "https://example.com/science/climate"}
+		task.SetHTML(html)
+		err := FindPageType(context.TODO(), task)
+		assert.Nil(err)
+
+		// the content from readability
+		task.SetHTML(`<p>` + strings.Repeat("word ", words) + `</p>`)
+		task.WordCount = words
+		err = ClassifyPage(false)(context.TODO(), task)
+		assert.Nil(err)
+		return task
+	}
+
+	// readability kept a few words of the page
+	task := extracted(8)
+	assert.Greater(task.PageType.PageWords, 300)
+	assert.Equal(pipeline.PageListing, task.PageType.Type)
+
+	// readability kept most of the page
+	task = extracted(300)
+	assert.Equal(pipeline.PageArticle, task.PageType.Type)
+}
+
+func TestNotArticle(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := classify("https://example.com/", `<html><head></head><body>`+teasers(12)+`</body></html>`, true)
+	var notArticle *pipeline.NotArticleError
+	assert.True(errors.As(err, &notArticle))
+	assert.Equal(pipeline.PageHomepage, notArticle.PageType)
+
+	// not rejected if the type is uncertain
+	_, err = classify("https://example.com/sports", `<html><head></head><body><p>Sports</p></body></html>`, true)
+	assert.Nil(err)
+}
+
+func TestRejectNotArticle(t *testing.T) {
+	assert := assert.New(t)
+
+	task := &pipeline.Task{URL: "https://example.com/"}
+	task.SetHTML(`<html><head></head><body>` + teasers(12) + `</body></html>`)
+	assert.Nil(FindPageType(context.TODO(), task))
+	err := RejectNotArticle(context.TODO(), task)
+	var notArticle *pipeline.NotArticleError
+	assert.True(errors.As(err, &notArticle))
+	assert.Equal(pipeline.PageHomepage, notArticle.PageType)
+
+	// ambiguous pages are left to ClassifyPage
+	task = &pipeline.Task{URL: "https://example.com/sports"}
+	task.SetHTML(`<html><head></head><body><p>Sports</p></body></html>`)
+	assert.Nil(FindPageType(context.TODO(), task))
+	assert.Nil(RejectNotArticle(context.TODO(), task))
+}
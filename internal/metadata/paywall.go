@@ -0,0 +1,179 @@
+package metadata
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/jsonld"
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// Weights for paywall signals.
+//
+// Publishers mark paywalled content in structured data for search engines,
+// which is the most reliable signal. Paywall containers and phrases also
+// appear on free articles (e.g. for metered access), on their own they are
+// not sufficient.
+const (
+	weightNotFree   = 0.7
+	weightHasPart   = 0.5
+	weightClass     = 0.4
+	weightPhrase    = 0.4
+	weightTruncated = 0.6
+)
+
+// FindPaywall looks for signs of a paywall in the original document.
+//
+// This must run before the document is modified. CheckTruncated completes the
+// detection once the content is extracted.
+func FindPaywall(ctx context.Context, t *pipeline.Task) error {
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "metadata",
+		"url":    t.ContentURL(),
+	}).Info("Look for paywall")
+
+	doc := t.Document()
+	if doc == nil {
+		return nil
+	}
+
+	findPaywallLD(t, jsonld.Parse(doc))
+	findPaywallClass(t, doc)
+	findPaywallPhrase(t, doc)
+
+	return nil
+}
+
+// findPaywallLD reads the markup for paywalled content,
+// see: https://developers.google.com/search/docs/appearance/structured-data/paywalled-content
+func findPaywallLD(t *pipeline.Task, g *jsonld.Graph) {
+	// the article may be nested in a WebPage
+	nodes := append(g.Nodes(), g.Find(articleTypes...)...)
+	for _, n := range nodes {
+		if isFalse(n.Value("isAccessibleForFree")) {
+			t.Paywall.AddSignal("jsonld/isAccessibleForFree", weightNotFree)
+		}
+
+		for _, part := range n.Nodes("hasPart") {
+			if isFalse(part.Value("isAccessibleForFree")) && part.String("cssSelector") != "" {
+				t.Paywall.AddSignal("jsonld/hasPart", weightHasPart)
+			}
+		}
+	}
+
+	n := mainNode(g)
+	if n != nil {
+		t.Paywall.BodyWords = countWords(n.String("articleBody"))
+	}
+}
+
+// isFalse tells if a JSON-LD value is false, either as a boolean or as text.
+func isFalse(v interface{}) bool {
+	switch val := v.(type) {
+	case bool:
+		return !val
+	case string:
+		return strings.EqualFold(strings.TrimSpace(val), "false")
+	}
+	return false
+}
+
+var paywallClass = regexp.MustCompile(`(?i)(^|[-_])(pay-?wall|reg-?wall|paid-?content|premium-?(barrier|wall|teaser)|subscriber-?only|meter-?wall|piano-?(offer|paywall)|tp-(modal|container))($|[-_])`)
+
+// findPaywallClass looks for elements which are typically used for the
+// paywall overlay or the subscription offer.
+func findPaywallClass(t *pipeline.Task, doc *goquery.Document) {
+	doc.Find("body [class], body [id]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		class, _ := s.Attr("class")
+		id, _ := s.Attr("id")
+		for _, name := range append(strings.Fields(class), id) {
+			if paywallClass.MatchString(name) {
+				t.Paywall.AddSignal("class/"+strings.ToLower(name), weightClass)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// phrases which ask the reader to subscribe to continue reading
+var paywallPhrases = []string{
+	// en
+	"subscribe to continue reading",
+	"subscribe to read the full",
+	"to continue reading, subscribe",
+	"this article is for subscribers",
+	"this content is for subscribers",
+	"for subscribers only",
+	"exclusive to subscribers",
+	"sign in to continue reading",
+	"create a free account to continue reading",
+	"you have reached your limit of free articles",
+	// de
+	"jetzt abonnieren und weiterlesen",
+	"dieser artikel ist nur für abonnenten",
+	"exklusiv für abonnenten",
+	"nur für abonnenten",
+	"weiterlesen mit plus",
+	"um den artikel weiterzulesen",
+	// fr
+	"réservé aux abonnés",
+	"abonnez-vous pour lire la suite",
+	"la suite est réservée aux abonnés",
+	// es
+	"exclusivo para suscriptores",
+	"suscríbete para seguir leyendo",
+	"suscríbete para continuar leyendo",
+	// it
+	"riservato agli abbonati",
+	"abbonati per continuare a leggere",
+	// nl
+	"alleen voor abonnees",
+	"word abonnee om verder te lezen",
+}
+
+// findPaywallPhrase looks for phrases which ask the reader to subscribe.
+func findPaywallPhrase(t *pipeline.Task, doc *goquery.Document) {
+	body := doc.Find("body").First().Clone()
+	body.Find("script, style").Remove()
+	text := strings.ToLower(strings.Join(strings.Fields(body.Text()), " "))
+	for _, p := range paywallPhrases {
+		if strings.Contains(text, p) {
+			t.Paywall.AddSignal("phrase/"+p, weightPhrase)
+			return
+		}
+	}
+}
+
+// the content is considered truncated if it has less words than this
+// fraction of the full text
+const truncatedRatio = 0.5
+
+// structured data with less words than this is not a reliable full text
+const minBodyWords = 150
+
+// CheckTruncated compares the word count of the content with the full text
+// from structured data. Requires the word count.
+func CheckTruncated(ctx context.Context, t *pipeline.Task) error {
+	body := float64(t.Paywall.BodyWords)
+	if body >= minBodyWords && float64(t.WordCount) < body*truncatedRatio {
+		t.Paywall.AddSignal("truncated", weightTruncated)
+	}
+
+	if t.Paywall.Paywalled {
+		log.WithFields(log.Fields{
+			"task":       t.ID,
+			"module":     "metadata",
+			"url":        t.ContentURL(),
+			"confidence": t.Paywall.Confidence,
+			"signals":    t.Paywall.Signals,
+		}).Warning("Content is probably paywalled")
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package metadata
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestPaywall(t *testing.T) {
+	assert := assert.New(t)
+
+	// structured data for paywalled content
+	task := &pipeline.Task{URL: "https://example.com/article"}
+	task.SetHTML(`<html><head>
+        <script type="application/ld+json">{
+            "@context": "https://schema.org",
+            "@type": "NewsArticle",
+            "headline": "Article",
+            "isAccessibleForFree": "False",
+            "hasPart": {
+                "@type": "WebPageElement",
+                "isAccessibleForFree": false,
+                "cssSelector": ".paywalled-section"
+            }
+        }</script>
+    </head><body><p>Teaser</p><div class="paywalled-section"></div></body></html>`)
+
+	err := FindPaywall(context.TODO(), task)
+	assert.Nil(err)
+	assert.True(task.Paywall.Paywalled)
+	assert.Contains(task.Paywall.Signals, "jsonld/isAccessibleForFree")
+	assert.Contains(task.Paywall.Signals, "jsonld/hasPart")
+	assert.InDelta(0.85, task.Paywall.Confidence, 0.001)
+
+	// class and phrase
+	task = &pipeline.Task{URL: "https://example.com/artikel"}
+	task.SetHTML(`<html><head></head><body>
+        <p>Teaser</p>
+        <div id="paywall-overlay">
+            <p>Dieser Artikel ist nur für
+            Abonnenten verfügbar.</p>
+        </div>
+    </body></html>`)
+
+	err = FindPaywall(context.TODO(), task)
+	assert.Nil(err)
+	assert.True(task.Paywall.Paywalled)
+	assert.Equal([]string{"class/paywall-overlay", "phrase/dieser artikel ist nur für abonnenten"}, task.Paywall.Signals)
+
+	// a single weak signal is not enough
+	task = &pipeline.Task{URL: "https://example.com/article"}
+	task.SetHTML(`<html><head></head><body>
+        <p>Free content</p>
+        <div class="piano-offer" style="display:none"></div>
+        <script>var msg = "subscribe to continue reading";</script>
+    </body></html>`)
+
+	err = FindPaywall(context.TODO(), task)
+	assert.Nil(err)
+	assert.False(task.Paywall.Paywalled)
+	assert.Equal([]string{"class/piano-offer"}, task.Paywall.Signals)
+
+	// free
+	task = &pipeline.Task{URL: "https://example.com/article"}
+	task.SetHTML(`<html><head></head><body><p class="wallpaper">Content</p></body></html>`)
+
+	err = FindPaywall(context.TODO(), task)
+	assert.Nil(err)
+	assert.False(task.Paywall.Paywalled)
+	assert.Empty(task.Paywall.Signals)
+}
+
+func TestTruncated(t *testing.T) {
+	assert := assert.New(t)
+
+	body := strings.Repeat("word ", 400)
+	task := &pipeline.Task{URL: "https://example.com/article"}
+	task.SetHTML(`<html><head>
+        <script type="application/ld+json">{
+            "@context": "https://schema.org",
+            "@type": "NewsArticle",
+            "articleBody": "` + body + `"
+        }</script>
+    </head><body><p>Teaser</p></body></html>`)
+
+	err := FindPaywall(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal(400, task.Paywall.BodyWords)
+	assert.False(task.Paywall.Paywalled)
+
+	task.WordCount = 50
+	err = CheckTruncated(context.TODO(), task)
+	assert.Nil(err)
+	assert.True(task.Paywall.Paywalled)
+	assert.Equal([]string{"truncated"}, task.Paywall.Signals)
+
+	// complete content
+	task.Paywall = pipeline.Paywall{BodyWords: 400}
+	task.WordCount = 390
+	err = CheckTruncated(context.TODO(), task)
+	assert.Nil(err)
+	assert.False(task.Paywall.Paywalled)
+}
@@ -0,0 +1,237 @@
+package metadata
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// findTitle reads the title from the <title> element.
+// Titles from meta tags are collected in findMeta.
+func findTitle(m *metadata, doc *goquery.Document) {
+	doc.Selection.Find("title").First().Each(func(i int, s *goquery.Selection) {
+		setValue(m.title, "title", s.Text())
+	})
+}
+
+// ResolveTitle selects the title from the candidates that were collected
+// from metadata and readability. The first <h1> from the content is added
+// as another candidate.
+//
+// Must run after the content is extracted.
+func ResolveTitle(ctx context.Context, t *pipeline.Task) error {
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "metadata",
+		"url":    t.ContentURL(),
+	}).Info("Resolve title")
+
+	doc := t.Document()
+	if doc != nil {
+		t.AddTitle("h1", doc.Find("h1").First().Text())
+	}
+
+	setTitle(t)
+
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "metadata",
+		"title":  t.Title,
+		"source": t.TitleSource,
+	}).Debug("Selected title")
+
+	return nil
+}
+
+// setTitle sets the title from the candidates of the task.
+// The title is unchanged if there are no candidates.
+func setTitle(t *pipeline.Task) {
+	title, source := resolveTitle(t.Titles, siteNames(t))
+	if title != "" {
+		t.Title = title
+		t.TitleSource = source
+	}
+}
+
+// siteNames returns the names which are stripped from titles:
+// the site name, the host and the domain without public suffix,
+// e.g. "Example News", "news.example.com" and "example".
+func siteNames(t *pipeline.Task) []string {
+	names := []string{t.SiteName, t.Site}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(t.Site)
+	if err == nil {
+		names = append(names, domain)
+		suffix, _ := publicsuffix.PublicSuffix(domain)
+		names = append(names, strings.TrimSuffix(domain, "."+suffix))
+	}
+	return names
+}
+
+type titleCandidate struct {
+	source string
+	title  string
+	key    string
+}
+
+// resolveTitle selects the title from the given candidates.
+//
+// Site names are stripped from the candidates. If a candidate consists of
+// several parts, e.g. "Headline | Section | Site", and one of the parts
+// equals another candidate, that part is used.
+// The title is the one that most candidates agree on,
+// ties are decided by the preference list.
+func resolveTitle(values map[string]string, names []string) (string, string) {
+	cs := make([]*titleCandidate, 0)
+	var siteTitle *titleCandidate
+	for _, source := range titlePref {
+		v := strings.Join(strings.Fields(values[source]), " ")
+		v = stripSiteName(v, names)
+		key := titleKey(v)
+		if key == "" {
+			continue
+		}
+		c := &titleCandidate{source: source, title: v, key: key}
+		// e.g. a logo in <h1>, or the homepage
+		if isSiteName(key, names) {
+			if siteTitle == nil {
+				siteTitle = c
+			}
+			continue
+		}
+		cs = append(cs, c)
+	}
+
+	for _, c := range cs {
+		for _, other := range cs {
+			if c.key == other.key {
+				continue
+			}
+			part := longestPart(c.title)
+			if part != c.title && titleKey(part) == other.key {
+				c.title = part
+				c.key = other.key
+				break
+			}
+		}
+	}
+
+	votes := make(map[string]int)
+	for _, c := range cs {
+		votes[c.key]++
+	}
+
+	var best *titleCandidate
+	for _, c := range cs {
+		if best == nil || votes[c.key] > votes[best.key] {
+			best = c
+		}
+	}
+
+	if best == nil {
+		if siteTitle != nil {
+			return siteTitle.title, siteTitle.source
+		}
+		return "", ""
+	}
+
+	title := best.title
+	if votes[best.key] == 1 {
+		title = guessTitle(title)
+	}
+	return title, best.source
+}
+
+// separators between the parts of a title,
+// colons only if they are followed by a space, as in "Headline: Site"
+var titleSeparator = regexp.MustCompile(`\s*[|·»]\s*|\s+[-–—/]\s+|:\s+`)
+
+func titleParts(s string) []string {
+	parts := make([]string, 0)
+	for _, p := range titleSeparator.Split(s, -1) {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// longestPart returns the longest part of a title.
+// The headline is usually longer than the name of the section or the site.
+func longestPart(s string) string {
+	longest := ""
+	for _, p := range titleParts(s) {
+		if len(p) > len(longest) {
+			longest = p
+		}
+	}
+	if longest == "" {
+		return s
+	}
+	return longest
+}
+
+// stripSiteName removes one of the names from the beginning or the end of
+// the title, if it is separated from the rest of the title.
+func stripSiteName(title string, names []string) string {
+	for _, name := range names {
+		key := titleKey(name)
+		if key == "" {
+			continue
+		}
+
+		loc := titleSeparator.FindAllStringIndex(title, -1)
+		if len(loc) == 0 {
+			return title
+		}
+
+		first, last := loc[0], loc[len(loc)-1]
+		if titleKey(title[:first[0]]) == key {
+			return strings.TrimSpace(title[first[1]:])
+		}
+		if titleKey(title[last[1]:]) == key {
+			return strings.TrimSpace(title[:last[0]])
+		}
+	}
+	return title
+}
+
+func isSiteName(key string, names []string) bool {
+	for _, name := range names {
+		if titleKey(name) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// guessTitle strips a prefix or suffix, separated by a single "|", from a
+// title that no other source confirms. The longer part is assumed to be the
+// actual title.
+func guessTitle(s string) string {
+	if strings.Count(s, "|") != 1 {
+		return s
+	}
+
+	parts := strings.Split(s, "|")
+	if len(parts[0]) > len(parts[1]) {
+		return strings.TrimSpace(parts[0])
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// titleKey normalizes a title for comparison, only lower case letters and
+// digits are kept.
+func titleKey(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
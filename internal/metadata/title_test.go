@@ -0,0 +1,119 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestStripSiteName(t *testing.T) {
+	assert := assert.New(t)
+
+	names := []string{"The Site Name", "example.com"}
+
+	tests := map[string]string{
+		"No Prefix":                                  "No Prefix",
+		"The Actual Title - The Site Name":           "The Actual Title",
+		"The Actual Title: The Site Name":            "The Actual Title",
+		"The Site Name | The Actual Title":           "The Actual Title",
+		"The Site Name: The Actual Title":            "The Actual Title",
+		"The Actual Title — Example.com":             "The Actual Title",
+		"The Actual Title | Section | The Site Name": "The Actual Title | Section",
+		// not to be stripped
+		"The Actual Title can contain The Site Name": "The Actual Title can contain The Site Name",
+		"The Site Name-Actual Title":                 "The Site Name-Actual Title",
+	}
+
+	for title, expected := range tests {
+		assert.Equal(expected, stripSiteName(title, names), title)
+	}
+}
+
+func TestResolveTitle(t *testing.T) {
+	assert := assert.New(t)
+
+	names := []string{"Example News"}
+
+	// agreement beats preference
+	title, source := resolveTitle(map[string]string{
+		"ld/headline": "Headline for Search Engines",
+		"og:title":    "The Actual Headline",
+		"title":       "The Actual Headline | Politics | Example News",
+		"readability": "The Actual Headline",
+	}, names)
+	assert.Equal("The Actual Headline", title)
+	assert.Equal("og:title", source)
+
+	// the part of the title which matches another candidate
+	title, source = resolveTitle(map[string]string{
+		"title": "Politics » The Actual Headline » News",
+		"h1":    "The actual headline",
+	}, names)
+	assert.Equal("The Actual Headline", title)
+	assert.Equal("title", source)
+
+	// preference for ties
+	title, source = resolveTitle(map[string]string{
+		"twitter:title": "Short Title",
+		"ld/headline":   "The Long Headline",
+	}, names)
+	assert.Equal("The Long Headline", title)
+	assert.Equal("ld/headline", source)
+
+	// unconfirmed title with a single separator
+	title, _ = resolveTitle(map[string]string{
+		"title": "The Actual Headline | Suffix",
+	}, names)
+	assert.Equal("The Actual Headline", title)
+
+	// the site name is not a title, except for the homepage
+	title, source = resolveTitle(map[string]string{
+		"title": "The Actual Headline",
+		"h1":    "Example News",
+	}, names)
+	assert.Equal("The Actual Headline", title)
+	assert.Equal("title", source)
+
+	title, source = resolveTitle(map[string]string{
+		"og:title": "Example News",
+	}, names)
+	assert.Equal("Example News", title)
+	assert.Equal("og:title", source)
+
+	title, _ = resolveTitle(map[string]string{}, names)
+	assert.Equal("", title)
+}
+
+func TestReadTitle(t *testing.T) {
+	assert := assert.New(t)
+
+	task := &pipeline.Task{URL: "https://www.example.com/2021/08/headline"}
+	task.SetHTML(`<html><head>
+        <title>The Headline - Example</title>
+        <meta property="og:site_name" content="Example Site">
+        <meta property="og:title" content="The Headline">
+        <meta name="twitter:title" content="The headline">
+        <meta name="parsely-title" content="Something Else">
+    </head><body>
+        <h1><img src="logo.png" alt="Example"></h1>
+        <article><h1>The Headline</h1><p>Content</p></article>
+    </body></html>`)
+
+	err := ReadMetadata(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal("The Headline", task.Title)
+	assert.Equal("og:title", task.TitleSource)
+
+	// readability and the content are added later
+	task.Titles["og:title"] = "Something Else"
+	task.AddTitle("readability", "The Headline")
+	task.SetHTML(`<html><body><h1>The Headline</h1></body></html>`)
+
+	err = ResolveTitle(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal("The headline", task.Title)
+	assert.Equal("twitter:title", task.TitleSource)
+}
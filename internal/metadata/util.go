@@ -1,5 +1,7 @@
 package metadata
 
+import "strings"
+
 func contains(haystack []string, needle string) bool {
 	for _, s := range haystack {
 		if s == needle {
@@ -8,3 +10,19 @@ func contains(haystack []string, needle string) bool {
 	}
 	return false
 }
+
+// dedupe removes duplicate entries, comparing case-insensitive.
+// The first occurence is kept.
+func dedupe(s []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(s))
+	for _, v := range s {
+		k := strings.ToLower(v)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		result = append(result, v)
+	}
+	return result
+}
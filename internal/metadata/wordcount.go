@@ -2,15 +2,16 @@ package metadata
 
 import (
 	"context"
-	"regexp"
+	"math"
+	"time"
+	"unicode"
 
+	"github.com/PuerkitoBio/goquery"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/akeil/scrapen/internal/pipeline"
 )
 
-var pattern = regexp.MustCompile(`\w+`)
-
 // CountWords adds the `WordCount` property to the the Task.
 // It counts the number of all words in the content.
 func CountWords(ctx context.Context, t *pipeline.Task) error {
@@ -26,8 +27,160 @@ func CountWords(ctx context.Context, t *pipeline.Task) error {
 		text = doc.Selection.Find("body").First().Text()
 	}
 
-	words := pattern.FindAllString(text, -1)
-	t.WordCount = len(words)
+	t.WordCount = countWords(text)
 
 	return nil
 }
+
+// countWords counts the words in a text.
+//
+// This is a simplified version of the word boundaries from Unicode TR29:
+// words are sequences of letters, digits and marks in any script, which may
+// contain apostrophes ("don't") and separators between digits ("3.14").
+// Chinese and Japanese are written without spaces, each ideograph and kana
+// counts as a word.
+//
+// See: https://unicode.org/reports/tr29/#Word_Boundaries
+func countWords(s string) int {
+	runes := []rune(s)
+	count := 0
+	inWord := false
+	for i, r := range runes {
+		switch {
+		case r == 'ー':
+			// prolonged sound mark, part of the preceding kana
+			inWord = false
+		case isIdeograph(r):
+			count++
+			inWord = false
+		case isWordRune(r):
+			if !inWord {
+				count++
+			}
+			inWord = true
+		case inWord && i+1 < len(runes) && isMidWord(runes[i-1], r, runes[i+1]):
+			// still in the word
+		default:
+			inWord = false
+		}
+	}
+	return count
+}
+
+func isIdeograph(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+// isMidWord tells if r joins the runes before and after into one word.
+func isMidWord(before, r, after rune) bool {
+	if !isWordRune(after) || isIdeograph(after) {
+		return false
+	}
+	switch r {
+	case '\'', '’', '.':
+		return true
+	case ',', ';':
+		return unicode.IsDigit(before) && unicode.IsDigit(after)
+	}
+	return false
+}
+
+// reading speed for adults, if not configured otherwise
+const defaultWordsPerMinute = 200
+
+// leaf elements with running text, used to count sentences
+const textBlocks = "p, li, blockquote, figcaption, dd, td"
+
+// ComputeStats sets the text statistics for the content.
+// Requires the word count, the reading time is estimated with the given
+// number of words per minute.
+func ComputeStats(wordsPerMinute int) pipeline.Pipeline {
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = defaultWordsPerMinute
+	}
+
+	return func(ctx context.Context, t *pipeline.Task) error {
+		log.WithFields(log.Fields{
+			"task":   t.ID,
+			"module": "metadata",
+			"url":    t.ContentURL(),
+		}).Info("Compute text statistics")
+
+		doc := t.Document()
+		if doc == nil {
+			return nil
+		}
+		body := doc.Find("body").First()
+
+		s := &t.Stats
+		*s = pipeline.Stats{}
+		for _, r := range body.Text() {
+			if !unicode.IsSpace(r) {
+				s.Characters++
+			}
+		}
+
+		body.Find(textBlocks).Each(func(i int, block *goquery.Selection) {
+			if block.Find(textBlocks).Length() == 0 {
+				s.Sentences += countSentences(block.Text())
+			}
+		})
+
+		s.Paragraphs = countNonEmpty(body.Find("p"))
+		s.Headings = countNonEmpty(body.Find("h1, h2, h3, h4, h5, h6"))
+		s.Images = body.Find("img").Length()
+		s.Links = body.Find("a[href]").Length()
+
+		minutes := float64(t.WordCount) / float64(wordsPerMinute)
+		s.ReadingTime = time.Duration(math.Round(minutes*60)) * time.Second
+
+		return nil
+	}
+}
+
+func countNonEmpty(s *goquery.Selection) int {
+	count := 0
+	s.Each(func(i int, e *goquery.Selection) {
+		if countWords(e.Text()) > 0 {
+			count++
+		}
+	})
+	return count
+}
+
+// countSentences counts the sentences in a block of text. Sentences end with
+// a terminal punctuation mark, the last one may have none.
+func countSentences(s string) int {
+	runes := []rune(s)
+	count := 0
+	words := false
+	for i, r := range runes {
+		switch {
+		case r == '.' && i+1 < len(runes) && isWordRune(runes[i+1]):
+			// within "3.14" or "example.com"
+		case isSentenceEnd(r):
+			if words {
+				count++
+			}
+			words = false
+		case isWordRune(r):
+			words = true
+		}
+	}
+	if words {
+		count++
+	}
+	return count
+}
+
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…', '。', '！', '？', '।', '؟':
+		return true
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package metadata
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -13,7 +14,7 @@ func TestCountWords(t *testing.T) {
 	html := `<html><head></head><body>
     foo
     </body></html>`
-	countWords(t, html, 1)
+	checkWordCount(t, html, 1)
 
 	html = `<html><head></head><body>
     <h1>Headline</h1>
@@ -22,12 +23,12 @@ func TestCountWords(t *testing.T) {
         <hr />
         <div>Foo Bar</div>
     </body></html>`
-	countWords(t, html, 5)
+	checkWordCount(t, html, 5)
 
 	html = `<html><head></head><body>
         <!-- Empty -->
     </body></html>`
-	countWords(t, html, 0)
+	checkWordCount(t, html, 0)
 
 	// Punctuation, stopwords and quotes
 	html = `<html><head></head><body>
@@ -35,10 +36,10 @@ func TestCountWords(t *testing.T) {
         <p>Foo a Bar.</p>
         <div>Foo "Bar" Baz!</div>
     </body></html>`
-	countWords(t, html, 9)
+	checkWordCount(t, html, 9)
 }
 
-func countWords(t *testing.T, html string, expected int) {
+func checkWordCount(t *testing.T, html string, expected int) {
 	assert := assert.New(t)
 	task := &pipeline.Task{}
 	task.SetHTML(html)
@@ -47,3 +48,60 @@ func countWords(t *testing.T, html string, expected int) {
 	assert.Nil(err)
 	assert.Equal(expected, task.WordCount)
 }
+
+func TestCountWordsUnicode(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := map[string]int{
+		"Größenänderung der Straße":     3,
+		"Don't split it’s contractions": 4,
+		"Pi is 3.14, or 3,14 in German": 7,
+		"e-mail splits at the hyphen":   6,
+		"Ελληνικά και русский текст":    4,
+		"日本語の文章です。":                     8,
+		"コンピューター":                       5,
+		"中文分词很难":                        6,
+		"한국어 문장입니다":                     2,
+		"snake_case":                    1,
+		"":                              0,
+		"...":                           0,
+	}
+
+	for text, expected := range tests {
+		assert.Equal(expected, countWords(text), text)
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	assert := assert.New(t)
+
+	task := &pipeline.Task{}
+	task.SetHTML(`<html><head></head><body>
+        <h1>Headline</h1>
+        <p>First sentence. Second sentence with 3.14 in it! A third one?</p>
+        <h2>Section</h2>
+        <p>A <a href="https://example.com">link</a> to example.com</p>
+        <p></p>
+        <ul>
+            <li><p>Nested paragraph.</p></li>
+            <li>Item</li>
+        </ul>
+        <p>中文句子。第二句。</p>
+        <img src="image.jpg">
+    </body></html>`)
+
+	err := CountWords(context.TODO(), task)
+	assert.Nil(err)
+	err = ComputeStats(100)(context.TODO(), task)
+	assert.Nil(err)
+
+	s := task.Stats
+	assert.Equal(8, s.Sentences)
+	assert.Equal(4, s.Paragraphs)
+	assert.Equal(2, s.Headings)
+	assert.Equal(1, s.Images)
+	assert.Equal(1, s.Links)
+	assert.Greater(s.Characters, 100)
+	expected := time.Duration(task.WordCount) * 600 * time.Millisecond
+	assert.Equal(expected.Round(time.Second), s.ReadingTime)
+}
@@ -0,0 +1,325 @@
+package microdata
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+
+	"github.com/akeil/scrapen/internal/jsonld"
+)
+
+// Parse reads microdata and RDFa items from the document.
+//
+// The items are converted to the same structure as JSON-LD, so that they can
+// be evaluated the same way. This needs to be called on the original document,
+// before attributes are removed from the content.
+//
+// see:
+// - https://html.spec.whatwg.org/multipage/microdata.html
+// - https://www.w3.org/TR/rdfa-lite/
+func Parse(doc *goquery.Document) *jsonld.Graph {
+	g := jsonld.New()
+	if doc == nil {
+		return g
+	}
+
+	for _, item := range parseMicrodata(doc) {
+		g.Add(item)
+	}
+	for _, item := range parseRDFa(doc) {
+		g.Add(item)
+	}
+
+	return g
+}
+
+// parseMicrodata finds all top-level items, i.e. elements with `itemscope`
+// which are not a property of another item.
+func parseMicrodata(doc *goquery.Document) []interface{} {
+	items := make([]interface{}, 0)
+	doc.Find("[itemscope]").Each(func(i int, s *goquery.Selection) {
+		if _, isProp := s.Attr("itemprop"); isProp {
+			return
+		}
+		items = append(items, microdataItem(doc, s.Nodes[0], 0))
+	})
+	return items
+}
+
+// limits the depth of nested items, in case of a circular itemref
+const maxDepth = 10
+
+func microdataItem(doc *goquery.Document, n *html.Node, depth int) map[string]interface{} {
+	item := make(map[string]interface{})
+
+	types := strings.Fields(attr(n, "itemtype"))
+	if len(types) == 1 {
+		item["@type"] = types[0]
+	} else if len(types) > 1 {
+		list := make([]interface{}, len(types))
+		for i, t := range types {
+			list[i] = t
+		}
+		item["@type"] = list
+	}
+
+	id := attr(n, "itemid")
+	if id != "" {
+		item["@id"] = id
+	}
+
+	if depth >= maxDepth {
+		return item
+	}
+
+	roots := []*html.Node{n}
+	for _, ref := range strings.Fields(attr(n, "itemref")) {
+		doc.Find("[id]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+			if attr(s.Nodes[0], "id") == ref {
+				roots = append(roots, s.Nodes[0])
+				return false
+			}
+			return true
+		})
+	}
+
+	for i, root := range roots {
+		// referenced elements can be properties themselves
+		if i > 0 {
+			addMicrodataProperty(doc, item, root, depth)
+			if hasAttr(root, "itemscope") {
+				continue
+			}
+		}
+		walkScope(root, func(c *html.Node) {
+			addMicrodataProperty(doc, item, c, depth)
+		}, "itemscope")
+	}
+
+	return item
+}
+
+func addMicrodataProperty(doc *goquery.Document, item map[string]interface{}, n *html.Node, depth int) {
+	names := strings.Fields(attr(n, "itemprop"))
+	if len(names) == 0 {
+		return
+	}
+
+	var value interface{}
+	if hasAttr(n, "itemscope") {
+		value = microdataItem(doc, n, depth+1)
+	} else {
+		value = microdataValue(n)
+	}
+
+	for _, name := range names {
+		addProperty(item, propertyName(name), value)
+	}
+}
+
+// see: https://html.spec.whatwg.org/multipage/microdata.html#values
+func microdataValue(n *html.Node) string {
+	switch n.Data {
+	case "meta":
+		return attr(n, "content")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return attr(n, "src")
+	case "a", "area", "link":
+		return attr(n, "href")
+	case "object":
+		return attr(n, "data")
+	case "data", "meter":
+		return attr(n, "value")
+	case "time":
+		if hasAttr(n, "datetime") {
+			return attr(n, "datetime")
+		}
+	}
+
+	if hasAttr(n, "content") {
+		return attr(n, "content")
+	}
+	return text(n)
+}
+
+// parseRDFa finds all top-level RDFa items, i.e. elements with `typeof`
+// which are not a property of another item.
+func parseRDFa(doc *goquery.Document) []interface{} {
+	items := make([]interface{}, 0)
+	doc.Find("[typeof]").Each(func(i int, s *goquery.Selection) {
+		if hasScopedAncestor(s.Nodes[0], "typeof") {
+			return
+		}
+		items = append(items, rdfaItem(s.Nodes[0]))
+	})
+	return items
+}
+
+func rdfaItem(n *html.Node) map[string]interface{} {
+	item := make(map[string]interface{})
+
+	types := strings.Fields(attr(n, "typeof"))
+	if len(types) == 1 {
+		item["@type"] = types[0]
+	} else if len(types) > 1 {
+		list := make([]interface{}, len(types))
+		for i, t := range types {
+			list[i] = t
+		}
+		item["@type"] = list
+	}
+
+	id := attr(n, "resource")
+	if id == "" {
+		id = attr(n, "about")
+	}
+	if id != "" {
+		item["@id"] = id
+	}
+
+	walkScope(n, func(c *html.Node) {
+		names := strings.Fields(attr(c, "property"))
+		if len(names) == 0 {
+			return
+		}
+
+		var value interface{}
+		if hasAttr(c, "typeof") {
+			value = rdfaItem(c)
+		} else {
+			value = rdfaValue(c)
+		}
+
+		for _, name := range names {
+			addProperty(item, propertyName(name), value)
+		}
+	}, "typeof")
+
+	return item
+}
+
+// see: https://www.w3.org/TR/rdfa-lite/#property
+func rdfaValue(n *html.Node) string {
+	if hasAttr(n, "content") {
+		return attr(n, "content")
+	}
+
+	switch n.Data {
+	case "a", "area", "link":
+		if hasAttr(n, "href") {
+			return attr(n, "href")
+		}
+	case "img", "audio", "video", "source", "iframe":
+		if hasAttr(n, "src") {
+			return attr(n, "src")
+		}
+	case "time":
+		if hasAttr(n, "datetime") {
+			return attr(n, "datetime")
+		}
+	}
+
+	if hasAttr(n, "resource") {
+		return attr(n, "resource")
+	}
+	return text(n)
+}
+
+// walkScope calls f for each descendant of n.
+// Does not descend into nested elements that define their own scope,
+// but f is called for the scoping element itself.
+func walkScope(n *html.Node, f func(*html.Node), scopeAttr string) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		f(c)
+		if !hasAttr(c, scopeAttr) {
+			walkScope(c, f, scopeAttr)
+		}
+	}
+}
+
+func hasScopedAncestor(n *html.Node, scopeAttr string) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if hasAttr(p, scopeAttr) {
+			return true
+		}
+	}
+	return false
+}
+
+// addProperty adds a value to an item.
+// If the property has multiple values, these are collected in a list.
+func addProperty(item map[string]interface{}, name string, value interface{}) {
+	if name == "" {
+		return
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return
+	}
+
+	existing, ok := item[name]
+	if !ok {
+		item[name] = value
+		return
+	}
+
+	if list, ok := existing.([]interface{}); ok {
+		item[name] = append(list, value)
+	} else {
+		item[name] = []interface{}{existing, value}
+	}
+}
+
+var vocabPrefixes = []string{
+	"http://schema.org/",
+	"https://schema.org/",
+	"schema:",
+}
+
+// propertyName strips the vocabulary from a property name.
+func propertyName(s string) string {
+	for _, prefix := range vocabPrefixes {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	return s
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+func text(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+
+	return strings.TrimSpace(whitespace.ReplaceAllString(b.String(), " "))
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, name string) bool {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return true
+		}
+	}
+	return false
+}
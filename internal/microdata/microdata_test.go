@@ -0,0 +1,90 @@
+package microdata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMicrodata(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><body>
+	<article itemscope itemtype="https://schema.org/NewsArticle" itemref="byline">
+		<h1 itemprop="headline">The   Headline</h1>
+		<meta itemprop="datePublished" content="2021-08-15T10:00:00Z" />
+		<time itemprop="dateModified" datetime="2021-08-16">Yesterday</time>
+		<img itemprop="image" src="https://example.com/image.jpg" />
+		<div itemprop="publisher" itemscope itemtype="https://schema.org/Organization">
+			<span itemprop="name">The Publisher</span>
+		</div>
+		<p itemprop="keywords">foo, bar</p>
+		<p itemprop="keywords">baz</p>
+	</article>
+	<p id="byline" itemprop="author" itemscope itemtype="https://schema.org/Person">
+		By <a itemprop="url" href="https://example.com/jane"><span itemprop="name">Jane Doe</span></a>
+	</p>
+	</body></html>`
+
+	g := Parse(doc(html))
+
+	a := g.First("NewsArticle")
+	assert.NotNil(a)
+	assert.Equal("The Headline", a.String("headline"))
+	assert.Equal("2021-08-15T10:00:00Z", a.String("datePublished"))
+	assert.Equal("2021-08-16", a.String("dateModified"))
+	assert.Equal("https://example.com/image.jpg", a.URL("image"))
+	assert.Equal([]string{"foo, bar", "baz"}, a.Strings("keywords"))
+
+	// nested items
+	assert.Equal("The Publisher", a.Node("publisher").String("name"))
+	// publisher name is not a property of the article
+	assert.False(a.Has("name"))
+
+	// itemref
+	author := a.Node("author")
+	assert.NotNil(author)
+	assert.Equal("Jane Doe", author.String("name"))
+	assert.Equal("https://example.com/jane", author.String("url"))
+
+	// only top-level items
+	assert.Equal(1, len(g.Nodes()))
+}
+
+func TestRDFa(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><head>
+		<meta property="og:title" content="Not part of an item" />
+	</head><body>
+	<div vocab="https://schema.org/" typeof="BlogPosting">
+		<h1 property="headline">The Headline</h1>
+		<span property="schema:datePublished" content="2021-08-15">August 15</span>
+		<div property="author" typeof="Person">
+			<span property="name">John Doe</span>
+		</div>
+		<a property="url" href="https://example.com/post">Permalink</a>
+	</div>
+	</body></html>`
+
+	g := Parse(doc(html))
+	assert.Equal(1, len(g.Nodes()))
+
+	p := g.First("BlogPosting")
+	assert.NotNil(p)
+	assert.Equal("The Headline", p.String("headline"))
+	assert.Equal("2021-08-15", p.String("datePublished"))
+	assert.Equal("https://example.com/post", p.String("url"))
+	assert.Equal("John Doe", p.Node("author").String("name"))
+	assert.False(p.Has("name"))
+}
+
+func doc(s string) *goquery.Document {
+	d, err := goquery.NewDocumentFromReader(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
@@ -0,0 +1,494 @@
+package microformats
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Item is a microformats2 object, e.g. an `h-entry` or an `h-card`.
+//
+// Property values are either a string, a nested *Item or an Embedded value
+// (for `e-*` properties).
+type Item struct {
+	Type       []string
+	Properties map[string][]interface{}
+	Children   []*Item
+	// Value is the plain value of an item that is used as a property,
+	// e.g. the name of the `h-card` in `p-author h-card`.
+	Value string
+}
+
+// Embedded is the value of an `e-*` property.
+type Embedded struct {
+	HTML  string
+	Value string
+}
+
+// Parse reads all microformats2 items from the document.
+//
+// Returns the top-level items in document order. URLs from `u-*` properties
+// are resolved against the given base URL. This needs to be called on the
+// original document, before class attributes are removed.
+//
+// see: http://microformats.org/wiki/microformats2-parsing
+func Parse(doc *goquery.Document, base string) []*Item {
+	items := make([]*Item, 0)
+	if doc == nil {
+		return items
+	}
+
+	b, err := url.Parse(base)
+	if err != nil {
+		b = nil
+	}
+	p := &parser{base: b}
+
+	for _, n := range doc.Nodes {
+		p.findRoots(n, &items)
+	}
+	return items
+}
+
+type parser struct {
+	base *url.URL
+}
+
+// findRoots collects all items below n which are not nested in another item.
+func (p *parser) findRoots(n *html.Node, items *[]*Item) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if len(rootClasses(c)) > 0 {
+			*items = append(*items, p.parseItem(c))
+		} else {
+			p.findRoots(c, items)
+		}
+	}
+}
+
+func (p *parser) parseItem(n *html.Node) *Item {
+	item := &Item{
+		Type:       rootClasses(n),
+		Properties: make(map[string][]interface{}),
+		Children:   make([]*Item, 0),
+	}
+
+	// remember what was found explicitly, for the implied properties
+	var hasP, hasE, hasNested bool
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+
+			props := propertyClasses(c)
+			for _, prop := range props {
+				switch prop.prefix {
+				case "p":
+					hasP = true
+				case "e":
+					hasE = true
+				}
+			}
+
+			if len(rootClasses(c)) > 0 {
+				hasNested = true
+				nested := p.parseItem(c)
+				if len(props) == 0 {
+					item.Children = append(item.Children, nested)
+					continue
+				}
+				for _, prop := range props {
+					v := *nested
+					v.Value = p.nestedValue(c, prop.prefix, nested)
+					item.add(prop.name, &v)
+				}
+				continue
+			}
+
+			for _, prop := range props {
+				item.add(prop.name, p.propertyValue(c, prop.prefix))
+			}
+			walk(c)
+		}
+	}
+	walk(n)
+
+	if !hasP && !hasE && !hasNested {
+		item.add("name", impliedName(n))
+	}
+	if !hasNested {
+		if _, ok := item.Properties["photo"]; !ok {
+			item.add("photo", p.impliedPhoto(n))
+		}
+		if _, ok := item.Properties["url"]; !ok {
+			item.add("url", p.impliedURL(n))
+		}
+	}
+
+	return item
+}
+
+func (i *Item) add(name string, v interface{}) {
+	if s, ok := v.(string); ok && s == "" {
+		return
+	}
+	i.Properties[name] = append(i.Properties[name], v)
+}
+
+func (p *parser) propertyValue(n *html.Node, prefix string) interface{} {
+	switch prefix {
+	case "u":
+		return p.urlValue(n)
+	case "dt":
+		return dateValue(n)
+	case "e":
+		inner, _ := goquery.NewDocumentFromNode(n).Html()
+		return Embedded{
+			HTML:  strings.TrimSpace(inner),
+			Value: text(n),
+		}
+	default:
+		return plainValue(n)
+	}
+}
+
+// nestedValue determines the value for a nested item that is a property.
+func (p *parser) nestedValue(n *html.Node, prefix string, nested *Item) string {
+	switch prefix {
+	case "p":
+		if s := nested.String("name"); s != "" {
+			return s
+		}
+	case "u":
+		if s := nested.String("url"); s != "" {
+			return s
+		}
+		return p.urlValue(n)
+	}
+	return text(n)
+}
+
+func plainValue(n *html.Node) string {
+	switch n.Data {
+	case "abbr", "link":
+		if hasAttr(n, "title") {
+			return attr(n, "title")
+		}
+	case "data", "input":
+		if hasAttr(n, "value") {
+			return attr(n, "value")
+		}
+	case "img", "area":
+		if hasAttr(n, "alt") {
+			return attr(n, "alt")
+		}
+	}
+	return text(n)
+}
+
+func (p *parser) urlValue(n *html.Node) string {
+	var v string
+	switch n.Data {
+	case "a", "area", "link":
+		v = attr(n, "href")
+	case "img", "audio", "video", "source", "iframe":
+		v = attr(n, "src")
+		if v == "" && n.Data == "video" {
+			v = attr(n, "poster")
+		}
+	case "object":
+		v = attr(n, "data")
+	}
+	if v != "" {
+		return p.resolve(v)
+	}
+	return plainValue(n)
+}
+
+func dateValue(n *html.Node) string {
+	switch n.Data {
+	case "time", "ins", "del":
+		if hasAttr(n, "datetime") {
+			return attr(n, "datetime")
+		}
+	}
+	return plainValue(n)
+}
+
+func impliedName(n *html.Node) string {
+	for _, c := range []*html.Node{n, onlyChild(n), onlyChild(onlyChild(n))} {
+		if c == nil || (c != n && len(rootClasses(c)) > 0) {
+			break
+		}
+		switch c.Data {
+		case "img", "area":
+			if attr(c, "alt") != "" {
+				return attr(c, "alt")
+			}
+		case "abbr":
+			if attr(c, "title") != "" {
+				return attr(c, "title")
+			}
+		}
+	}
+
+	return text(n)
+}
+
+func (p *parser) impliedPhoto(n *html.Node) string {
+	for _, c := range []*html.Node{n, onlyChild(n), onlyChild(onlyChild(n))} {
+		if c == nil || (c != n && len(rootClasses(c)) > 0) {
+			break
+		}
+		switch c.Data {
+		case "img":
+			if hasAttr(c, "src") {
+				return p.resolve(attr(c, "src"))
+			}
+		case "object":
+			if hasAttr(c, "data") {
+				return p.resolve(attr(c, "data"))
+			}
+		}
+	}
+	return ""
+}
+
+func (p *parser) impliedURL(n *html.Node) string {
+	for _, c := range []*html.Node{n, onlyChild(n), onlyChild(onlyChild(n))} {
+		if c == nil || (c != n && len(rootClasses(c)) > 0) {
+			break
+		}
+		switch c.Data {
+		case "a", "area":
+			if hasAttr(c, "href") {
+				return p.resolve(attr(c, "href"))
+			}
+		}
+	}
+	return ""
+}
+
+func (p *parser) resolve(href string) string {
+	if p.base == nil {
+		return href
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return p.base.ResolveReference(u).String()
+}
+
+// onlyChild returns the single child element of n, or nil.
+func onlyChild(n *html.Node) *html.Node {
+	if n == nil {
+		return nil
+	}
+	var found *html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = c
+	}
+	return found
+}
+
+var (
+	rootPattern     = regexp.MustCompile(`^h(-[a-z0-9]+)?(-[a-z]+)+$`)
+	propertyPattern = regexp.MustCompile(`^(p|u|dt|e)-((?:[a-z0-9]+-)?[a-z]+(?:-[a-z]+)*)$`)
+)
+
+func rootClasses(n *html.Node) []string {
+	result := make([]string, 0)
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if rootPattern.MatchString(c) && !contains(result, c) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+type property struct {
+	prefix string
+	name   string
+}
+
+func propertyClasses(n *html.Node) []property {
+	result := make([]property, 0)
+	for _, c := range strings.Fields(attr(n, "class")) {
+		m := propertyPattern.FindStringSubmatch(c)
+		if m == nil {
+			continue
+		}
+		result = append(result, property{prefix: m[1], name: m[2]})
+	}
+	return result
+}
+
+// Is tells if the item has the given type, e.g. "h-entry".
+func (i *Item) Is(t string) bool {
+	return contains(i.Type, t)
+}
+
+// String returns the first plain value for the given property.
+func (i *Item) String(name string) string {
+	s := i.Strings(name)
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0]
+}
+
+// Strings returns the plain values for the given property.
+// For nested items and embedded HTML, the plain value is used.
+func (i *Item) Strings(name string) []string {
+	result := make([]string, 0)
+	for _, v := range i.Properties[name] {
+		var s string
+		switch val := v.(type) {
+		case string:
+			s = val
+		case *Item:
+			s = val.Value
+		case Embedded:
+			s = val.Value
+		}
+		if s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Items returns the nested items for the given property.
+func (i *Item) Items(name string) []*Item {
+	result := make([]*Item, 0)
+	for _, v := range i.Properties[name] {
+		if item, ok := v.(*Item); ok {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// HTML returns the first embedded HTML for the given property.
+func (i *Item) HTML(name string) string {
+	for _, v := range i.Properties[name] {
+		if e, ok := v.(Embedded); ok {
+			return e.HTML
+		}
+	}
+	return ""
+}
+
+// MainEntry finds the `h-entry` which describes the page.
+//
+// If the page contains several entries (e.g. a `h-feed`), the entry with the
+// given page URL is used. Returns nil if no such entry is found.
+func MainEntry(items []*Item, pageURL string) *Item {
+	entries := make([]*Item, 0)
+	var collect func([]*Item)
+	collect = func(items []*Item) {
+		for _, item := range items {
+			// entries nested in an entry are comments or quotes
+			if item.Is("h-entry") {
+				entries = append(entries, item)
+			} else {
+				collect(item.Children)
+			}
+		}
+	}
+	collect(items)
+
+	if len(entries) == 1 {
+		return entries[0]
+	}
+
+	for _, e := range entries {
+		for _, u := range e.Strings("url") {
+			if equalURL(u, pageURL) {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+func equalURL(a, b string) bool {
+	norm := func(s string) string {
+		s = strings.TrimPrefix(s, "https://")
+		s = strings.TrimPrefix(s, "http://")
+		return strings.TrimSuffix(s, "/")
+	}
+	return a != "" && norm(a) == norm(b)
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// text returns the text content, without scripts and with images replaced
+// by their alt text.
+func text(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "template":
+				return
+			case "img":
+				b.WriteString(attr(n, "alt"))
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+
+	return strings.TrimSpace(whitespace.ReplaceAllString(b.String(), " "))
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, name string) bool {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package microformats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEntry(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><body>
+	<article class="h-entry">
+		<h1 class="p-name">The   Title</h1>
+		<a class="u-url" href="/2021/08/post">Permalink</a>
+		<time class="dt-published" datetime="2021-08-15T10:00:00+02:00">August 15</time>
+		<time class="dt-updated" datetime="2021-08-16">August 16</time>
+		<a class="p-author h-card" href="https://jane.example.com/">
+			<img class="u-photo" src="/jane.jpg" alt="" />
+			Jane Doe
+		</a>
+		<a class="u-in-reply-to" href="https://other.example.com/post">In reply to</a>
+		<div class="u-like-of h-cite">
+			<a class="u-url" href="https://third.example.com/note">A note</a>
+		</div>
+		<span class="p-category">indieweb</span>
+		<span class="p-category">go</span>
+		<div class="e-content"><p>The <b>content</b>.</p></div>
+	</article>
+	<footer class="h-card"><a href="/">Site Owner</a></footer>
+	</body></html>`
+
+	items := Parse(doc(html), "https://example.com/page")
+	assert.Equal(2, len(items))
+
+	e := MainEntry(items, "https://example.com/page")
+	assert.NotNil(e)
+	assert.True(e.Is("h-entry"))
+	assert.Equal("The Title", e.String("name"))
+	assert.Equal("https://example.com/2021/08/post", e.String("url"))
+	assert.Equal("2021-08-15T10:00:00+02:00", e.String("published"))
+	assert.Equal("2021-08-16", e.String("updated"))
+	assert.Equal([]string{"indieweb", "go"}, e.Strings("category"))
+	assert.Equal("<p>The <b>content</b>.</p>", e.HTML("content"))
+	assert.Equal("The content.", e.String("content"))
+	assert.Equal("https://other.example.com/post", e.String("in-reply-to"))
+
+	authors := e.Items("author")
+	assert.Equal(1, len(authors))
+	// implied name and url
+	assert.Equal("Jane Doe", authors[0].String("name"))
+	assert.Equal("https://jane.example.com/", authors[0].String("url"))
+	assert.Equal("https://example.com/jane.jpg", authors[0].String("photo"))
+	assert.Equal("Jane Doe", authors[0].Value)
+
+	likes := e.Items("like-of")
+	assert.Equal(1, len(likes))
+	assert.Equal("https://third.example.com/note", likes[0].Value)
+
+	// implied properties of the footer h-card
+	assert.Equal("Site Owner", items[1].String("name"))
+	assert.Equal("https://example.com/", items[1].String("url"))
+}
+
+func TestMainEntry(t *testing.T) {
+	assert := assert.New(t)
+
+	html := `<html><body>
+	<div class="h-feed">
+		<div class="h-entry"><a class="u-url p-name" href="https://example.com/one">One</a></div>
+		<div class="h-entry"><a class="u-url p-name" href="https://example.com/two">Two</a></div>
+	</div>
+	</body></html>`
+
+	items := Parse(doc(html), "https://example.com/")
+	assert.Nil(MainEntry(items, "https://example.com/"))
+
+	e := MainEntry(items, "https://example.com/two/")
+	assert.NotNil(e)
+	assert.Equal("Two", e.String("name"))
+
+	// not microformats
+	items = Parse(doc(`<div class="h-100 p-3"><p class="content">Text</p></div>`), "")
+	assert.Equal(0, len(items))
+}
+
+func doc(s string) *goquery.Document {
+	d, err := goquery.NewDocumentFromReader(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
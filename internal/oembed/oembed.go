@@ -0,0 +1,129 @@
+package oembed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+)
+
+// Response is an oEmbed response.
+//
+// see: https://oembed.com/
+type Response struct {
+	Type            string    `json:"type"`
+	Version         string    `json:"version"`
+	Title           string    `json:"title"`
+	AuthorName      string    `json:"author_name"`
+	AuthorURL       string    `json:"author_url"`
+	ProviderName    string    `json:"provider_name"`
+	ProviderURL     string    `json:"provider_url"`
+	ThumbnailURL    string    `json:"thumbnail_url"`
+	ThumbnailWidth  dimension `json:"thumbnail_width"`
+	ThumbnailHeight dimension `json:"thumbnail_height"`
+	// URL is the image URL for the "photo" type
+	URL    string    `json:"url"`
+	HTML   string    `json:"html"`
+	Width  dimension `json:"width"`
+	Height dimension `json:"height"`
+}
+
+// dimension is a width or height in pixels.
+// Some providers send numbers as strings, these are accepted too.
+type dimension int
+
+func (d *dimension) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), "\"")
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		// e.g. "100%", not useful for us
+		*d = 0
+		return nil
+	}
+	*d = dimension(i)
+	return nil
+}
+
+var discoveryTypes = []string{
+	"application/json+oembed",
+	"text/json+oembed",
+}
+
+// Discover returns the oEmbed endpoint that a page advertises with
+// `<link rel="alternate" type="application/json+oembed">`.
+// Returns an empty string if there is none.
+func Discover(doc *goquery.Document) string {
+	var endpoint string
+	doc.Find("link[rel~=alternate]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		tp, _ := s.Attr("type")
+		tp = strings.ToLower(strings.TrimSpace(tp))
+		for _, dt := range discoveryTypes {
+			if tp == dt {
+				endpoint, _ = s.Attr("href")
+				endpoint = strings.TrimSpace(endpoint)
+				return endpoint == ""
+			}
+		}
+		return true
+	})
+	return endpoint
+}
+
+// Endpoint returns the oEmbed request URL for the given content URL from
+// the provider registry. Returns an empty string if no provider is known.
+func Endpoint(contentURL string) string {
+	p := Lookup(contentURL)
+	if p == nil {
+		return ""
+	}
+
+	q := url.Values{}
+	q.Set("url", contentURL)
+	q.Set("format", "json")
+	return p.Endpoint + "?" + q.Encode()
+}
+
+// responses larger than this are not accepted
+const maxSize = 1 << 20
+
+// Fetch requests an oEmbed response from the given endpoint URL.
+func Fetch(ctx context.Context, client *http.Client, endpoint string) (*Response, error) {
+	log.WithFields(log.Fields{
+		"module": "oembed",
+		"url":    endpoint,
+	}).Info("Fetch oEmbed")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got HTTP status %v", res.StatusCode)
+	}
+
+	var r Response
+	err = json.NewDecoder(io.LimitReader(res.Body, maxSize)).Decode(&r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Type == "" {
+		return nil, fmt.Errorf("invalid oEmbed response from %v", endpoint)
+	}
+	return &r, nil
+}
@@ -0,0 +1,132 @@
+package oembed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]string{
+		"https://www.youtube.com/watch?v=abc":          "YouTube",
+		"https://youtu.be/abc":                         "YouTube",
+		"http://vimeo.com/12345":                       "Vimeo",
+		"https://twitter.com/someone/status/123":       "Twitter",
+		"https://open.spotify.com/track/abc":           "Spotify",
+		"https://www.flickr.com/photos/someone/123/":   "Flickr",
+		"https://www.tiktok.com/@someone/video/123456": "TikTok",
+	}
+	for u, name := range cases {
+		p := Lookup(u)
+		if assert.NotNil(p, u) {
+			assert.Equal(name, p.Name)
+		}
+	}
+
+	assert.Nil(Lookup("https://example.com/watch?v=abc"))
+	assert.Nil(Lookup("https://www.youtube.com.example.com/"))
+
+	assert.Equal("https://www.youtube.com/oembed?format=json&url=https%3A%2F%2Fyoutu.be%2Fabc", Endpoint("https://youtu.be/abc"))
+	assert.Equal("", Endpoint("https://example.com/"))
+}
+
+func TestProviderConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &Provider{Name: "Test", Schemes: []string{"example.com/video/*"}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.True(p.Matches("https://example.com/video/1"))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestContentURL(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]string{
+		"https://www.youtube.com/embed/abc?feature=oembed":                      "https://www.youtube.com/watch?v=abc",
+		"https://www.youtube-nocookie.com/embed/abc":                            "https://www.youtube.com/watch?v=abc",
+		"//player.vimeo.com/video/12345?title=0":                                "https://vimeo.com/12345",
+		"https://www.dailymotion.com/embed/video/x7abc":                         "https://www.dailymotion.com/video/x7abc",
+		"https://open.spotify.com/embed/episode/abc":                            "https://open.spotify.com/episode/abc",
+		"https://w.soundcloud.com/player/?url=https%3A%2F%2Fsoundcloud.com%2Fa": "https://soundcloud.com/a",
+		"https://example.com/embed/abc":                                         "https://example.com/embed/abc",
+	}
+	for src, expected := range cases {
+		assert.Equal(expected, ContentURL(src), src)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	assert := assert.New(t)
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head>
+		<link rel="alternate" type="application/rss+xml" href="/feed"/>
+		<link rel="alternate" type="text/xml+oembed" href="/oembed?format=xml"/>
+		<link rel="alternate" type="application/json+oembed" href="/oembed?format=json"/>
+		</head><body></body></html>`))
+	assert.Nil(err)
+	assert.Equal("/oembed?format=json", Discover(doc))
+
+	doc, err = goquery.NewDocumentFromReader(strings.NewReader(`<html><head></head></html>`))
+	assert.Nil(err)
+	assert.Equal("", Discover(doc))
+}
+
+func TestFetch(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/video":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{
+				"type": "video",
+				"version": "1.0",
+				"title": "A Video",
+				"author_name": "Someone",
+				"author_url": "https://example.com/someone",
+				"provider_name": "Example",
+				"thumbnail_url": "https://example.com/thumb.jpg",
+				"thumbnail_width": 480,
+				"thumbnail_height": "360",
+				"width": "100%",
+				"html": "<iframe></iframe>"
+			}`))
+		case "/invalid":
+			w.Write([]byte(`{"title": "no type"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	r, err := Fetch(context.TODO(), srv.Client(), srv.URL+"/video")
+	assert.Nil(err)
+	assert.Equal("video", r.Type)
+	assert.Equal("A Video", r.Title)
+	assert.Equal("Someone", r.AuthorName)
+	assert.Equal("https://example.com/thumb.jpg", r.ThumbnailURL)
+	assert.Equal(dimension(480), r.ThumbnailWidth)
+	assert.Equal(dimension(360), r.ThumbnailHeight)
+	assert.Equal(dimension(0), r.Width)
+
+	_, err = Fetch(context.TODO(), srv.Client(), srv.URL+"/invalid")
+	assert.NotNil(err)
+
+	_, err = Fetch(context.TODO(), srv.Client(), srv.URL+"/missing")
+	assert.NotNil(err)
+}
@@ -0,0 +1,209 @@
+package oembed
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Provider is an oEmbed provider with the URL schemes it supports.
+type Provider struct {
+	Name     string
+	Endpoint string
+	Schemes  []string
+
+	once     sync.Once
+	patterns []*regexp.Regexp
+}
+
+// Matches tells if the given URL matches one of the provider's schemes.
+// The schemes are compiled on first use, Schemes must not be changed after
+// that.
+func (p *Provider) Matches(u string) bool {
+	p.once.Do(p.compile)
+
+	for _, re := range p.patterns {
+		if re.MatchString(u) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Provider) compile() {
+	p.patterns = make([]*regexp.Regexp, len(p.Schemes))
+	for i, s := range p.Schemes {
+		p.patterns[i] = schemePattern(s)
+	}
+}
+
+// schemePattern converts an oEmbed URL scheme like
+// "https://*.example.com/video/*" to a regular expression.
+// Schemes are given without protocol and match both http and https.
+func schemePattern(s string) *regexp.Regexp {
+	parts := strings.Split(s, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^https?://" + strings.Join(parts, ".*") + "$")
+}
+
+// Providers is the offline registry of well-known oEmbed providers.
+//
+// This is a small subset of https://oembed.com/providers.json.
+// Providers which require an access token (e.g. Facebook, Instagram)
+// are not included.
+var Providers = []*Provider{
+	{
+		Name:     "YouTube",
+		Endpoint: "https://www.youtube.com/oembed",
+		Schemes: []string{
+			"*.youtube.com/watch*",
+			"*.youtube.com/v/*",
+			"*.youtube.com/shorts/*",
+			"youtube.com/watch*",
+			"youtu.be/*",
+		},
+	},
+	{
+		Name:     "Vimeo",
+		Endpoint: "https://vimeo.com/api/oembed.json",
+		Schemes: []string{
+			"vimeo.com/*",
+			"vimeo.com/album/*/video/*",
+			"vimeo.com/channels/*/*",
+			"vimeo.com/groups/*/videos/*",
+		},
+	},
+	{
+		Name:     "Dailymotion",
+		Endpoint: "https://www.dailymotion.com/services/oembed",
+		Schemes: []string{
+			"www.dailymotion.com/video/*",
+			"dai.ly/*",
+		},
+	},
+	{
+		Name:     "Twitter",
+		Endpoint: "https://publish.twitter.com/oembed",
+		Schemes: []string{
+			"twitter.com/*/status/*",
+			"*.twitter.com/*/status/*",
+			"x.com/*/status/*",
+		},
+	},
+	{
+		Name:     "SoundCloud",
+		Endpoint: "https://soundcloud.com/oembed",
+		Schemes: []string{
+			"soundcloud.com/*",
+			"api.soundcloud.com/tracks/*",
+		},
+	},
+	{
+		Name:     "Spotify",
+		Endpoint: "https://open.spotify.com/oembed",
+		Schemes: []string{
+			"open.spotify.com/*",
+		},
+	},
+	{
+		Name:     "Mixcloud",
+		Endpoint: "https://app.mixcloud.com/oembed/",
+		Schemes: []string{
+			"www.mixcloud.com/*/*/",
+		},
+	},
+	{
+		Name:     "Flickr",
+		Endpoint: "https://www.flickr.com/services/oembed/",
+		Schemes: []string{
+			"*.flickr.com/photos/*",
+			"flic.kr/p/*",
+		},
+	},
+	{
+		Name:     "TikTok",
+		Endpoint: "https://www.tiktok.com/oembed",
+		Schemes: []string{
+			"www.tiktok.com/*/video/*",
+		},
+	},
+	{
+		Name:     "SlideShare",
+		Endpoint: "https://www.slideshare.net/api/oembed/2",
+		Schemes: []string{
+			"www.slideshare.net/*/*",
+		},
+	},
+	{
+		Name:     "TED",
+		Endpoint: "https://www.ted.com/services/v1/oembed.json",
+		Schemes: []string{
+			"ted.com/talks/*",
+			"www.ted.com/talks/*",
+		},
+	},
+	{
+		Name:     "CodePen",
+		Endpoint: "https://codepen.io/api/oembed",
+		Schemes: []string{
+			"codepen.io/*/pen/*",
+		},
+	},
+}
+
+// Lookup finds the provider for the given URL in the registry.
+// Returns nil if no provider matches.
+func Lookup(u string) *Provider {
+	for _, p := range Providers {
+		if p.Matches(u) {
+			return p
+		}
+	}
+	return nil
+}
+
+// ContentURL returns the URL of the embedded content for the src of an
+// embedded player (iframe), e.g. "https://www.youtube.com/watch?v=ID" for
+// "https://www.youtube.com/embed/ID".
+//
+// Returns the src unchanged if it is not a known player URL.
+func ContentURL(src string) string {
+	u, err := url.Parse(src)
+	if err != nil {
+		return src
+	}
+	if u.Scheme == "" {
+		u.Scheme = "https"
+	}
+
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	path := strings.Trim(u.Path, "/")
+	parts := strings.Split(path, "/")
+
+	switch host {
+	case "youtube.com", "youtube-nocookie.com":
+		if len(parts) == 2 && parts[0] == "embed" && parts[1] != "videoseries" {
+			return "https://www.youtube.com/watch?v=" + parts[1]
+		}
+	case "player.vimeo.com":
+		if len(parts) == 2 && parts[0] == "video" {
+			return "https://vimeo.com/" + parts[1]
+		}
+	case "dailymotion.com":
+		if len(parts) == 3 && parts[0] == "embed" && parts[1] == "video" {
+			return "https://www.dailymotion.com/video/" + parts[2]
+		}
+	case "open.spotify.com":
+		if len(parts) > 1 && parts[0] == "embed" {
+			return "https://open.spotify.com/" + strings.Join(parts[1:], "/")
+		}
+	case "w.soundcloud.com":
+		if target := u.Query().Get("url"); target != "" {
+			return target
+		}
+	}
+	return u.String()
+}
@@ -0,0 +1,199 @@
+// Package opml reads and writes OPML files with feed subscriptions.
+//
+// See: http://opml.org/spec2.opml
+package opml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// Document is an OPML file.
+type Document struct {
+	Title       string
+	DateCreated *time.Time
+	Outlines    []Outline
+}
+
+// Outline is an element of the outline. Subscriptions have the type "rss"
+// and an XMLURL, other outlines usually group subscriptions.
+type Outline struct {
+	Text     string
+	Title    string
+	Type     string
+	XMLURL   string
+	HTMLURL  string
+	Outlines []Outline
+}
+
+// Feed is a subscription from an OPML file.
+type Feed struct {
+	Title   string
+	XMLURL  string
+	HTMLURL string
+	// Category is the path of the enclosing outlines,
+	// e.g. "News/Tech".
+	Category string
+}
+
+// ErrNotOPML is returned if the file is not an OPML document.
+var ErrNotOPML = errors.New("not an OPML document")
+
+type xmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated,omitempty"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []xmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type xmlOutline struct {
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Outlines []xmlOutline `xml:"outline"`
+}
+
+// Parse reads an OPML document. Attribute names are not case sensitive,
+// as many files use "xmlurl" instead of "xmlUrl".
+func Parse(r io.Reader) (*Document, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = charset.NewReaderLabel
+
+	var x xmlDocument
+	err := d.Decode(&x)
+	if err != nil {
+		var se xml.UnmarshalError
+		if errors.As(err, &se) || errors.Is(err, io.EOF) {
+			return nil, ErrNotOPML
+		}
+		return nil, err
+	}
+
+	doc := &Document{
+		Title:    strings.TrimSpace(x.Head.Title),
+		Outlines: readOutlines(x.Body.Outlines),
+	}
+	if t, err := time.Parse(time.RFC1123Z, strings.TrimSpace(x.Head.DateCreated)); err == nil {
+		doc.DateCreated = &t
+	} else if t, err := time.Parse(time.RFC1123, strings.TrimSpace(x.Head.DateCreated)); err == nil {
+		doc.DateCreated = &t
+	}
+	return doc, nil
+}
+
+func readOutlines(xs []xmlOutline) []Outline {
+	var outlines []Outline
+	for _, x := range xs {
+		o := Outline{Outlines: readOutlines(x.Outlines)}
+		for _, a := range x.Attrs {
+			v := strings.TrimSpace(a.Value)
+			switch strings.ToLower(a.Name.Local) {
+			case "text":
+				o.Text = v
+			case "title":
+				o.Title = v
+			case "type":
+				o.Type = strings.ToLower(v)
+			case "xmlurl":
+				o.XMLURL = v
+			case "htmlurl":
+				o.HTMLURL = v
+			}
+		}
+		outlines = append(outlines, o)
+	}
+	return outlines
+}
+
+// Feeds returns all subscriptions of the document, including those in
+// nested outlines. Feeds with the same URL are returned once.
+func (d *Document) Feeds() []Feed {
+	feeds := make([]Feed, 0)
+	seen := make(map[string]bool)
+	var walk func(outlines []Outline, path []string)
+	walk = func(outlines []Outline, path []string) {
+		for _, o := range outlines {
+			if o.XMLURL != "" {
+				if !seen[o.XMLURL] {
+					seen[o.XMLURL] = true
+					feeds = append(feeds, Feed{
+						Title:    o.name(),
+						XMLURL:   o.XMLURL,
+						HTMLURL:  o.HTMLURL,
+						Category: strings.Join(path, "/"),
+					})
+				}
+				continue
+			}
+			walk(o.Outlines, append(path, o.name()))
+		}
+	}
+	walk(d.Outlines, nil)
+	return feeds
+}
+
+func (o Outline) name() string {
+	if o.Text != "" {
+		return o.Text
+	}
+	return o.Title
+}
+
+// Write writes the document as OPML 2.0.
+func Write(w io.Writer, d *Document) error {
+	var x xmlDocument
+	x.Version = "2.0"
+	x.Head.Title = d.Title
+	if d.DateCreated != nil {
+		x.Head.DateCreated = d.DateCreated.Format(time.RFC1123Z)
+	}
+	x.Body.Outlines = writeOutlines(d.Outlines)
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	err = e.Encode(x)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func writeOutlines(outlines []Outline) []xmlOutline {
+	var xs []xmlOutline
+	for _, o := range outlines {
+		x := xmlOutline{Outlines: writeOutlines(o.Outlines)}
+		// text is required
+		x.Attrs = append(x.Attrs, attr("text", o.name()))
+		for _, a := range []xml.Attr{
+			attr("title", o.Title),
+			attr("type", o.Type),
+			attr("xmlUrl", o.XMLURL),
+			attr("htmlUrl", o.HTMLURL),
+		} {
+			if a.Value != "" {
+				x.Attrs = append(x.Attrs, a)
+			}
+		}
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+func attr(name, value string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: name}, Value: value}
+}
@@ -0,0 +1,107 @@
+package opml
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+    <head>
+        <title>Subscriptions</title>
+        <dateCreated>Tue, 10 Aug 2021 14:30:00 +0000</dateCreated>
+    </head>
+    <body>
+        <outline text="News">
+            <outline text="Tech">
+                <outline type="rss" text="Example Tech" xmlUrl="https://example.com/tech.xml" htmlUrl="https://example.com/tech"/>
+            </outline>
+            <outline type="rss" title="Example &amp; Co" xmlurl="https://example.com/feed.xml"/>
+        </outline>
+        <outline type="rss" text="Blog" xmlUrl="https://blog.example.com/atom.xml"/>
+        <outline type="rss" text="Duplicate" xmlUrl="https://example.com/feed.xml"/>
+    </body>
+</opml>`
+
+	doc, err := Parse(strings.NewReader(data))
+	assert.Nil(err)
+	assert.Equal("Subscriptions", doc.Title)
+	assert.Equal(time.Date(2021, 8, 10, 14, 30, 0, 0, time.UTC), doc.DateCreated.UTC())
+	assert.Equal(3, len(doc.Outlines))
+
+	assert.Equal([]Feed{
+		{
+			Title:    "Example Tech",
+			XMLURL:   "https://example.com/tech.xml",
+			HTMLURL:  "https://example.com/tech",
+			Category: "News/Tech",
+		},
+		{
+			Title:    "Example & Co",
+			XMLURL:   "https://example.com/feed.xml",
+			Category: "News",
+		},
+		{
+			Title:  "Blog",
+			XMLURL: "https://blog.example.com/atom.xml",
+		},
+	}, doc.Feeds())
+
+	_, err = Parse(strings.NewReader(`<rss version="2.0"><channel></channel></rss>`))
+	assert.Equal(ErrNotOPML, err)
+
+	_, err = Parse(strings.NewReader(""))
+	assert.Equal(ErrNotOPML, err)
+}
+
+func TestWrite(t *testing.T) {
+	assert := assert.New(t)
+
+	created := time.Date(2021, 8, 10, 14, 30, 0, 0, time.UTC)
+	doc := &Document{
+		Title:       "Feeds",
+		DateCreated: &created,
+		Outlines: []Outline{
+			{
+				Text:    "Example & Co",
+				HTMLURL: "https://example.com",
+				Outlines: []Outline{
+					{
+						Text:    "Main Feed",
+						Type:    "rss",
+						XMLURL:  "https://example.com/feed.xml",
+						HTMLURL: "https://example.com",
+					},
+				},
+			},
+		},
+	}
+
+	var b strings.Builder
+	err := Write(&b, doc)
+	assert.Nil(err)
+	assert.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>Feeds</title>
+    <dateCreated>Tue, 10 Aug 2021 14:30:00 +0000</dateCreated>
+  </head>
+  <body>
+    <outline text="Example &amp; Co" htmlUrl="https://example.com">
+      <outline text="Main Feed" type="rss" xmlUrl="https://example.com/feed.xml" htmlUrl="https://example.com"></outline>
+    </outline>
+  </body>
+</opml>
+`, b.String())
+
+	// round trip
+	parsed, err := Parse(strings.NewReader(b.String()))
+	assert.Nil(err)
+	assert.Equal(doc.Outlines, parsed.Outlines)
+}
@@ -0,0 +1,395 @@
+package paging
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// FindPages looks for a link to the next page of a multi-page article and
+// stores it as the `NextURL` for the task.
+//
+// This must run on the complete document, i.e. before the content is
+// prepared or made readable.
+//
+// The next page is detected from (in that order):
+// - a <link rel="next"> or <a rel="next"> element
+// - numbered page links, e.g. a "pagination" list at the end of the content
+// - links that follow a common URL pattern, e.g. `?page=2` or `/page/2/`
+func FindPages(ctx context.Context, t *pipeline.Task) error {
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "paging",
+		"url":    t.ContentURL(),
+	}).Info("Find pages")
+
+	t.NextURL = ""
+
+	doc := t.Document()
+	if doc == nil {
+		return nil
+	}
+
+	base, err := url.Parse(t.ContentURL())
+	if err != nil {
+		return err
+	}
+
+	next := findRelNext(doc, base)
+	if next == "" {
+		next = findNumbered(doc, base)
+	}
+	if next == "" {
+		next = findByPattern(doc, base)
+	}
+
+	if next != "" && next != base.String() {
+		log.WithFields(log.Fields{
+			"task":   t.ID,
+			"module": "paging",
+			"next":   next,
+		}).Info("Found next page")
+
+		t.NextURL = next
+	}
+
+	return nil
+}
+
+// AssemblePages creates a pipeline step which fetches the pages following the
+// first page of an article and appends their content to the task.
+//
+// The given pipeline is run for each page and is expected to fetch and prepare
+// the page content. It should include FindPages, so that further pages are
+// found. At most maxPages pages (including the first one) are assembled.
+func AssemblePages(maxPages int, p pipeline.Pipeline) pipeline.Pipeline {
+	return func(ctx context.Context, t *pipeline.Task) error {
+		log.WithFields(log.Fields{
+			"task":   t.ID,
+			"module": "paging",
+			"url":    t.ContentURL(),
+			"next":   t.NextURL,
+		}).Info("Assemble pages")
+
+		seen := map[string]bool{
+			t.URL:          true,
+			t.ContentURL(): true,
+		}
+
+		next := t.NextURL
+		for count := 1; count < maxPages && next != "" && !seen[next]; count++ {
+			seen[next] = true
+
+			page := pipeline.NewTask(t.Store, t.ID, next, p)
+			page.Client = t.Client
+			err := page.Run(ctx)
+			if err != nil {
+				// Keep what we have so far
+				log.WithFields(log.Fields{
+					"task":   t.ID,
+					"module": "paging",
+					"url":    next,
+					"error":  err,
+				}).Warning("Failed to fetch page")
+				break
+			}
+
+			appendPage(t.Document(), page.Document(), t.Title)
+			next = page.NextURL
+		}
+
+		t.NextURL = ""
+		return nil
+	}
+}
+
+// appendPage adds the body of the page document to the body of the main
+// document.
+// Headlines and lead paragraphs which are repeated on each page are removed
+// from the page before it is appended.
+func appendPage(doc, page *goquery.Document, title string) {
+	if doc == nil || page == nil {
+		return
+	}
+
+	known := make(map[string]bool)
+	if title != "" {
+		known[normalizeText(title)] = true
+	}
+	doc.Find(blocks).Each(func(i int, s *goquery.Selection) {
+		known[normalizeText(s.Text())] = true
+	})
+
+	page.Find(blocks).Each(func(i int, s *goquery.Selection) {
+		txt := normalizeText(s.Text())
+		if txt != "" && known[txt] {
+			s.Remove()
+		}
+	})
+
+	body := doc.Find("body").First()
+	if body.Length() == 0 {
+		return
+	}
+	body.AppendSelection(page.Find("body").First().Children())
+}
+
+// blocks are the elements we compare when looking for duplicate content.
+const blocks = "h1, h2, h3, h4, h5, h6, p, figcaption, blockquote"
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+func normalizeText(s string) string {
+	s = whitespace.ReplaceAllString(s, " ")
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func findRelNext(doc *goquery.Document, base *url.URL) string {
+	var next string
+	doc.Find("link, a").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		rel, _ := s.Attr("rel")
+		if !hasToken(rel, "next") {
+			return true
+		}
+
+		href, _ := s.Attr("href")
+		next = resolve(base, href)
+		return next == ""
+	})
+
+	return next
+}
+
+// containers with these (partial) class names or ids hold the page navigation.
+var pagerNames = []string{
+	"pagination",
+	"paginator",
+	"pager",
+	"paging",
+	"page-numbers",
+}
+
+// findNumbered looks for a list of numbered links to the pages of an article
+// and returns the link to the page after the current one.
+func findNumbered(doc *goquery.Document, base *url.URL) string {
+	current := pageNumber(base)
+	var next string
+
+	doc.Find("nav, div, ul, ol, p").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if !isPager(s) {
+			return true
+		}
+
+		cur := current
+		if n := currentPage(s); n > 0 {
+			cur = n
+		}
+
+		s.Find("a").EachWithBreak(func(j int, a *goquery.Selection) bool {
+			n, err := strconv.Atoi(strings.TrimSpace(a.Text()))
+			if err != nil || n != cur+1 {
+				return true
+			}
+
+			href, _ := a.Attr("href")
+			next = resolve(base, href)
+			return next == ""
+		})
+
+		return next == ""
+	})
+
+	return next
+}
+
+// currentPage reads the number of the current page from a pager which marks
+// it, e.g. with aria-current or a "current" class. Returns zero if the pager
+// has no such mark.
+func currentPage(s *goquery.Selection) int {
+	n := 0
+	s.Find("[aria-current=page], .current, .active, .is-current").EachWithBreak(func(i int, c *goquery.Selection) bool {
+		v, err := strconv.Atoi(strings.TrimSpace(c.Text()))
+		if err == nil && v > 0 {
+			n = v
+			return false
+		}
+		return true
+	})
+	return n
+}
+
+func isPager(s *goquery.Selection) bool {
+	class, _ := s.Attr("class")
+	id, _ := s.Attr("id")
+	names := strings.ToLower(class + " " + id)
+	for _, name := range pagerNames {
+		if strings.Contains(names, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findByPattern looks for a link in the document which points to the next
+// page, according to a common URL pattern.
+func findByPattern(doc *goquery.Document, base *url.URL) string {
+	candidates := nextCandidates(base)
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	var next string
+	doc.Find("a").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		href, _ := s.Attr("href")
+		u := resolve(base, href)
+		for _, c := range candidates {
+			if equalURL(u, c) {
+				next = u
+				return false
+			}
+		}
+		return true
+	})
+
+	return next
+}
+
+// page numbers in the path need a "page" token, other numbers are dates,
+// IDs or part of the slug, e.g. "/2021/08/15/" or "covid-19.html"
+var (
+	pageParams  = []string{"page", "p", "seite", "pg"}
+	pathPageNum = regexp.MustCompile(`(?i)^(.*/(?:page|seite|p)[/-]?)(\d{1,3})(/?)$`)
+	filePageNum = regexp.MustCompile(`(?i)^(.*[-_](?:page|seite|p)[-_]?)(\d{1,3})(\.\w+)$`)
+)
+
+// pageNumber guesses the number of the current page from the URL.
+// If nothing is found, this is assumed to be the first page.
+func pageNumber(u *url.URL) int {
+	q := u.Query()
+	for _, name := range pageParams {
+		n, err := strconv.Atoi(q.Get(name))
+		if err == nil && n > 0 {
+			return n
+		}
+	}
+
+	for _, re := range []*regexp.Regexp{pathPageNum, filePageNum} {
+		m := re.FindStringSubmatch(u.Path)
+		if m != nil {
+			n, err := strconv.Atoi(m[2])
+			if err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+
+	return 1
+}
+
+// nextCandidates creates URLs that would point to the next page
+// if one of the common URL patterns for paging is used.
+func nextCandidates(u *url.URL) []string {
+	c := make([]string, 0)
+
+	// Query parameter, e.g. ?page=2
+	q := u.Query()
+	found := false
+	for _, name := range pageParams {
+		n, err := strconv.Atoi(q.Get(name))
+		if err == nil && n > 0 {
+			c = append(c, withQuery(u, name, n+1))
+			found = true
+		}
+	}
+	if !found {
+		c = append(c, withQuery(u, "page", 2))
+	}
+
+	// Path, e.g. /page/2/ or article-page-2.html
+	if m := pathPageNum.FindStringSubmatch(u.Path); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		c = append(c, withPath(u, fmt.Sprintf("%v%v%v", m[1], n+1, m[3])))
+	} else if m := filePageNum.FindStringSubmatch(u.Path); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		c = append(c, withPath(u, fmt.Sprintf("%v%v%v", m[1], n+1, m[3])))
+	} else {
+		p := strings.TrimSuffix(u.Path, "/")
+		c = append(c, withPath(u, p+"/page/2/"))
+	}
+
+	return c
+}
+
+func withQuery(u *url.URL, name string, n int) string {
+	v := *u
+	q := v.Query()
+	q.Set(name, strconv.Itoa(n))
+	v.RawQuery = q.Encode()
+	v.Fragment = ""
+	return v.String()
+}
+
+func withPath(u *url.URL, p string) string {
+	v := *u
+	v.Path = p
+	v.RawPath = ""
+	v.Fragment = ""
+	return v.String()
+}
+
+// equalURL compares two URLs, ignoring a trailing slash and the fragment.
+func equalURL(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	ua.Fragment = ""
+	ub.Fragment = ""
+	ua.Path = strings.TrimSuffix(ua.Path, "/")
+	ub.Path = strings.TrimSuffix(ub.Path, "/")
+	ua.RawQuery = ua.Query().Encode()
+	ub.RawQuery = ub.Query().Encode()
+
+	return ua.String() == ub.String()
+}
+
+func resolve(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+		return ""
+	}
+
+	h, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+
+	u := base.ResolveReference(h)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+func hasToken(s, token string) bool {
+	for _, f := range strings.Fields(strings.ToLower(s)) {
+		if f == token {
+			return true
+		}
+	}
+	return false
+}
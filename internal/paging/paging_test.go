@@ -0,0 +1,180 @@
+package paging
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+func TestFindPages(t *testing.T) {
+	assert := assert.New(t)
+
+	// rel=next
+	html := `<html><head>
+		<link rel="next" href="/article?page=2" />
+	</head><body><p>Content</p></body></html>`
+	assert.Equal("https://example.com/article?page=2", findNext(t, "https://example.com/article", html))
+
+	// numbered page links
+	html = `<html><head></head><body>
+		<p>Content</p>
+		<ul class="article-pagination">
+			<li><a href="/article">1</a></li>
+			<li><a href="/article/2">2</a></li>
+			<li><a href="/article/3">3</a></li>
+		</ul>
+	</body></html>`
+	assert.Equal("https://example.com/article/2", findNext(t, "https://example.com/article", html))
+
+	// "/2" is not a page number, the pager does not mark the current page
+	assert.Equal("", findNext(t, "https://example.com/article/2", html))
+
+	// numbered, the pager marks the second page
+	html = `<html><head></head><body>
+		<p>Content</p>
+		<ul class="article-pagination">
+			<li><a href="/article">1</a></li>
+			<li><span aria-current="page">2</span></li>
+			<li><a href="/article/3">3</a></li>
+		</ul>
+	</body></html>`
+	assert.Equal("https://example.com/article/3", findNext(t, "https://example.com/article/2", html))
+
+	// a list of pages is not a pager
+	html = `<html><head></head><body>
+		<p>Content</p>
+		<ul class="pages">
+			<li><a href="/about">1</a></li>
+			<li><a href="/contact">2</a></li>
+		</ul>
+	</body></html>`
+	assert.Equal("", findNext(t, "https://example.com/article", html))
+
+	// date archives are not pages
+	html = `<html><head></head><body>
+		<p>Content</p>
+		<a href="/2021/08/16/">Next day</a>
+	</body></html>`
+	assert.Equal("", findNext(t, "https://example.com/2021/08/15/", html))
+
+	// URL pattern
+	html = `<html><head></head><body>
+		<p>Content</p>
+		<a href="/other">Other</a>
+		<a href="/article/page/3/">Continue</a>
+	</body></html>`
+	assert.Equal("https://example.com/article/page/3/", findNext(t, "https://example.com/article/page/2/", html))
+
+	html = `<html><head></head><body>
+		<p>Content</p>
+		<a href="?page=2">Continue</a>
+	</body></html>`
+	assert.Equal("https://example.com/article?page=2", findNext(t, "https://example.com/article", html))
+
+	// no paging
+	html = `<html><head></head><body>
+		<p>Content</p>
+		<a href="/other">Other</a>
+	</body></html>`
+	assert.Equal("", findNext(t, "https://example.com/article", html))
+}
+
+func TestPageNumber(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(1, pageNumber(parse("https://example.com/article")))
+	assert.Equal(3, pageNumber(parse("https://example.com/article?page=3")))
+	assert.Equal(2, pageNumber(parse("https://example.com/article/page/2/")))
+	assert.Equal(4, pageNumber(parse("https://example.com/article/seite-4")))
+	assert.Equal(5, pageNumber(parse("https://example.com/article-page-5.html")))
+	assert.Equal(1, pageNumber(parse("https://example.com/article/2/")))
+	assert.Equal(1, pageNumber(parse("https://example.com/2021/08/15/")))
+	assert.Equal(1, pageNumber(parse("https://example.com/covid-19.html")))
+}
+
+func TestAppendPage(t *testing.T) {
+	assert := assert.New(t)
+
+	d := doc(`<h1>Title</h1><p>The lead.</p><p>Page one.</p>`)
+	p := doc(`<h1>Title</h1><p>The  lead.</p><p>Page two.</p>`)
+
+	appendPage(d, p, "Title")
+	html, _ := d.Find("body").First().Html()
+	assert.Equal(`<h1>Title</h1><p>The lead.</p><p>Page one.</p><p>Page two.</p>`, html)
+}
+
+func TestAssemblePages(t *testing.T) {
+	assert := assert.New(t)
+
+	pages := map[string]string{
+		"https://example.com/a?page=2": `<p>Two</p>`,
+		"https://example.com/a?page=3": `<p>Three</p>`,
+		"https://example.com/a?page=4": `<p>Four</p>`,
+	}
+
+	count := 0
+	p := func(ctx context.Context, tk *pipeline.Task) error {
+		count++
+		tk.SetHTML(pages[tk.URL])
+		if tk.URL == "https://example.com/a?page=2" {
+			tk.NextURL = "https://example.com/a?page=3"
+		} else if tk.URL == "https://example.com/a?page=3" {
+			// loop
+			tk.NextURL = "https://example.com/a?page=2"
+		}
+		return nil
+	}
+
+	task := &pipeline.Task{
+		URL:     "https://example.com/a",
+		NextURL: "https://example.com/a?page=2",
+	}
+	task.SetHTML(`<p>One</p>`)
+
+	err := AssemblePages(10, p)(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal(2, count)
+	assert.Equal(`<head></head><body><p>One</p><p>Two</p><p>Three</p></body>`, task.HTML())
+
+	// limit
+	count = 0
+	task.NextURL = "https://example.com/a?page=2"
+	task.SetHTML(`<p>One</p>`)
+	err = AssemblePages(2, p)(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal(1, count)
+	assert.Equal(`<head></head><body><p>One</p><p>Two</p></body>`, task.HTML())
+}
+
+func findNext(t *testing.T, u, html string) string {
+	task := &pipeline.Task{
+		ActualURL: u,
+	}
+	task.SetHTML(html)
+
+	err := FindPages(context.TODO(), task)
+	assert.Nil(t, err)
+	return task.NextURL
+}
+
+func doc(s string) *goquery.Document {
+	d, err := goquery.NewDocumentFromReader(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func parse(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
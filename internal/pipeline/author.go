@@ -0,0 +1,8 @@
+package pipeline
+
+type Author struct {
+	Name  string
+	URL   string
+	Image string
+	Role  string
+}
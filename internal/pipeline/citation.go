@@ -0,0 +1,19 @@
+package pipeline
+
+// Citation holds bibliographic data for scholarly articles.
+//
+// Type is a CSL item type, e.g. "article-journal" or "paper-conference".
+type Citation struct {
+	Type        string
+	DOI         string
+	Journal     string
+	Volume      string
+	Issue       string
+	FirstPage   string
+	LastPage    string
+	Publisher   string
+	Institution string
+	ISSN        string
+	ISBN        string
+	PDFURL      string
+}
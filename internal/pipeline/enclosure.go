@@ -1,9 +1,111 @@
 package pipeline
 
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Enclosure types
+const (
+	EnclosureAudio = "Audio"
+	EnclosureVideo = "Video"
+)
+
 type Enclosure struct {
+	// Type is "Audio" or "Video".
 	Type        string
 	Title       string
 	URL         string
 	ContentType string
 	Description string
+	// Duration is zero if it is not known.
+	Duration time.Duration
+	// Size is the size in bytes, zero if it is not known.
+	Size int64
+	// Image is the URL of a cover image or thumbnail.
+	Image string
+	// Key is the key in the Store if the enclosure was downloaded.
+	Key string
+}
+
+// merge sets the fields which are missing from this enclosure from the
+// other enclosure.
+func (e *Enclosure) merge(other Enclosure) {
+	if e.Type == "" {
+		e.Type = other.Type
+	}
+	if e.Title == "" {
+		e.Title = other.Title
+	}
+	if e.ContentType == "" {
+		e.ContentType = other.ContentType
+	}
+	if e.Description == "" {
+		e.Description = other.Description
+	}
+	if e.Duration == 0 {
+		e.Duration = other.Duration
+	}
+	if e.Size == 0 {
+		e.Size = other.Size
+	}
+	if e.Image == "" {
+		e.Image = other.Image
+	}
+	if e.Key == "" {
+		e.Key = other.Key
+	}
+}
+
+// EnclosureType returns "Audio" or "Video" for a MIME type or a schema.org
+// type, or an empty string for other types.
+func EnclosureType(s string) string {
+	s = strings.ToLower(s)
+	switch {
+	case strings.HasPrefix(s, "audio"), s == "podcastepisode", s == "application/ogg":
+		return EnclosureAudio
+	case strings.HasPrefix(s, "video"), s == "application/x-mpegurl", s == "application/vnd.apple.mpegurl":
+		return EnclosureVideo
+	}
+	return ""
+}
+
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseDuration reads a duration in one of the formats that is used for
+// media: ISO 8601 like "PT1H2M3S", a clock like "1:02:03" or "62:03",
+// or the number of seconds.
+// Returns zero if the duration cannot be parsed.
+func ParseDuration(s string) time.Duration {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0
+	}
+
+	if m := isoDuration.FindStringSubmatch(s); m != nil {
+		var d time.Duration
+		units := []time.Duration{24 * time.Hour, time.Hour, time.Minute}
+		for i, unit := range units {
+			n, _ := strconv.Atoi(m[i+1])
+			d += time.Duration(n) * unit
+		}
+		sec, _ := strconv.ParseFloat(m[4], 64)
+		return d + time.Duration(sec*float64(time.Second))
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0
+	}
+	var sec float64
+	for _, p := range parts {
+		n, err := strconv.ParseFloat(p, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		sec = sec*60 + n
+	}
+	return time.Duration(sec * float64(time.Second)).Round(time.Second)
 }
@@ -3,4 +3,11 @@ package pipeline
 type FeedInfo struct {
 	URL   string
 	Title string
+	// Source tells where the feed was found,
+	// "link", "anchor" or "probe".
+	Source string
+	// Format and Entries are set if the feed was read,
+	// Format is one of "rss", "rdf", "atom" or "json".
+	Format  string
+	Entries int
 }
@@ -0,0 +1,11 @@
+package pipeline
+
+// License describes the terms under which the content is published.
+type License struct {
+	// ID is the SPDX identifier, e.g. "CC-BY-SA-4.0", if it is known.
+	ID     string
+	Name   string
+	URL    string
+	Holder string
+	Year   string
+}
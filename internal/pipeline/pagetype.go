@@ -0,0 +1,84 @@
+package pipeline
+
+import "fmt"
+
+// Page types
+const (
+	PageArticle  = "article"
+	PageListing  = "listing"
+	PageHomepage = "homepage"
+	PageVideo    = "video"
+	PageProduct  = "product"
+	PageError    = "error"
+)
+
+var pageTypes = []string{
+	PageArticle,
+	PageListing,
+	PageHomepage,
+	PageVideo,
+	PageProduct,
+	PageError,
+}
+
+// every page type starts with this score, articles with a higher one
+const (
+	basePageScore    = 0.5
+	articlePageScore = 1.0
+)
+
+// PageType holds the classification of the page.
+type PageType struct {
+	Type string
+	// Confidence is between 0 and 1.
+	Confidence float64
+	// Scores holds the evidence for each page type.
+	Scores map[string]float64
+	// PageWords is the number of words on the original page,
+	// before the content was extracted.
+	PageWords int
+}
+
+// Add adds evidence for the given page type.
+func (p *PageType) Add(pageType string, score float64) {
+	if p.Scores == nil {
+		p.Scores = make(map[string]float64)
+		for _, pt := range pageTypes {
+			p.Scores[pt] = basePageScore
+		}
+		p.Scores[PageArticle] = articlePageScore
+	}
+	p.Scores[pageType] += score
+}
+
+// Decide sets the page type with the highest score. The confidence compares
+// the best score with the runner-up.
+func (p *PageType) Decide() {
+	// initialize the scores if there is no evidence
+	p.Add(PageArticle, 0)
+
+	var best, second float64
+	for _, pt := range pageTypes {
+		s := p.Scores[pt]
+		if s > best {
+			second = best
+			best = s
+			p.Type = pt
+		} else if s > second {
+			second = s
+		}
+	}
+
+	p.Confidence = best / (best + second)
+}
+
+// NotArticleError is returned if a page is clearly not an article and the
+// task was configured to accept articles only.
+type NotArticleError struct {
+	PageType   string
+	Confidence float64
+}
+
+func (e *NotArticleError) Error() string {
+	return fmt.Sprintf("page is not an article but %v (confidence %.2f)", e.PageType, e.Confidence)
+}
@@ -0,0 +1,30 @@
+package pipeline
+
+// content is considered paywalled from this confidence on
+const paywallThreshold = 0.5
+
+// Paywall holds the evidence for a paywall or truncated content.
+type Paywall struct {
+	Paywalled bool
+	// Confidence is between 0 and 1.
+	Confidence float64
+	// Signals are the names of the indicators that were found.
+	Signals []string
+	// BodyWords is the number of words in the full text from structured
+	// data, if available. It is compared to the word count of the content.
+	BodyWords int
+}
+
+// AddSignal records an indicator for a paywall with the given weight
+// between 0 and 1. Each signal increases the confidence.
+func (p *Paywall) AddSignal(name string, weight float64) {
+	for _, s := range p.Signals {
+		if s == name {
+			return
+		}
+	}
+
+	p.Signals = append(p.Signals, name)
+	p.Confidence = 1 - (1-p.Confidence)*(1-weight)
+	p.Paywalled = p.Confidence >= paywallThreshold
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
@@ -36,25 +37,54 @@ type Task struct {
 	CanonicalURL string
 	StatusCode   int
 	Title        string
+	TitleSource  string
+	Titles       map[string]string
 	Retrieved    time.Time
 	Description  string
 	PubDate      *time.Time
+	ModifiedDate *time.Time
 	Site         string
 	SiteScheme   string
 	SiteName     string
+	SiteIcon     string
+	SiteLogo     string
+	ThemeColor   string
 	Author       string
+	Authors      []Author
+	Keywords     []string
+	Section      string
+	Language     string
+	Direction    string
+	InReplyTo    []string
+	LikeOf       []string
+	Citation     *Citation
+	License      *License
+	Paywall      Paywall
+	PageType     PageType
 	ImageURL     string
 	Images       []ImageInfo
 	Feeds        []FeedInfo
 	Enclosures   []Enclosure
 	WordCount    int
+	Stats        Stats
 	Store        Store
+	Client       *http.Client
 	document     *goquery.Document
 	altDocument  *goquery.Document
 	AltURL       string
-	mx           sync.Mutex
+	NextURL      string
+	// PubDatePrecision is PrecisionYear or PrecisionMonth if only part of
+	// the PubDate is known, e.g. "2021", and empty for a complete date.
+	PubDatePrecision string
+	mx               sync.Mutex
 }
 
+// Precision of incomplete dates
+const (
+	PrecisionYear  = "year"
+	PrecisionMonth = "month"
+)
+
 func NewTask(s Store, id, url string, p Pipeline) *Task {
 	return &Task{
 		ID:        id,
@@ -100,20 +130,53 @@ func (t *Task) reset() {
 	t.CanonicalURL = ""
 	t.StatusCode = 0
 	t.Title = ""
+	t.TitleSource = ""
+	t.Titles = nil
 	t.Description = ""
 	t.PubDate = nil
+	t.PubDatePrecision = ""
+	t.ModifiedDate = nil
 	t.Site = ""
 	t.SiteScheme = ""
 	t.SiteName = ""
+	t.SiteIcon = ""
+	t.SiteLogo = ""
+	t.ThemeColor = ""
 	t.Author = ""
+	t.Authors = nil
+	t.Keywords = nil
+	t.Section = ""
+	t.Language = ""
+	t.Direction = ""
+	t.InReplyTo = nil
+	t.LikeOf = nil
+	t.Citation = nil
+	t.License = nil
+	t.Paywall = Paywall{}
+	t.PageType = PageType{}
 	t.ImageURL = ""
 	t.Images = make([]ImageInfo, 0)
 	t.Feeds = nil
 	t.Enclosures = nil
 	t.WordCount = 0
+	t.Stats = Stats{}
 	t.document = nil
 	t.altDocument = nil
 	t.AltURL = ""
+	t.NextURL = ""
+}
+
+// AddTitle records a candidate for the title from the given source,
+// e.g. "og:title". Empty titles are ignored.
+func (t *Task) AddTitle(source, title string) {
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		return
+	}
+	if t.Titles == nil {
+		t.Titles = make(map[string]string)
+	}
+	t.Titles[source] = title
 }
 
 // Document returns the HTML content of this task as a DOM document.
@@ -166,6 +229,16 @@ func (t *Task) AltDocument() *goquery.Document {
 	return t.altDocument
 }
 
+// HTTPClient returns the client that should be used for all HTTP requests
+// made for this task.
+// If no client is set, the default client is returned.
+func (t *Task) HTTPClient() *http.Client {
+	if t.Client != nil {
+		return t.Client
+	}
+	return http.DefaultClient
+}
+
 // TODO: still needed?
 func (t *Task) PutAsset(k, contentType string, data []byte) error {
 	return t.Store.Put(k, contentType, data)
@@ -188,12 +261,21 @@ func (t *Task) AddImage(i ImageInfo, data []byte) error {
 	return nil
 }
 
+// AddEnclosure adds a media file to the task. If the task already has an
+// enclosure with the same URL, the missing fields are set from the given
+// enclosure.
 func (t *Task) AddEnclosure(e Enclosure) {
 	t.mx.Lock()
 	defer t.mx.Unlock()
 	if t.Enclosures == nil {
 		t.Enclosures = make([]Enclosure, 0)
 	}
+	for i := range t.Enclosures {
+		if t.Enclosures[i].URL == e.URL {
+			t.Enclosures[i].merge(e)
+			return
+		}
+	}
 	t.Enclosures = append(t.Enclosures, e)
 }
 
@@ -300,3 +382,13 @@ func ParseStoreID(url string) string {
 	}
 	return ""
 }
+
+// FirstOf returns the first of the given values that is not empty.
+func FirstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -43,3 +44,26 @@ func TestRestartTask(t *testing.T) {
 
 	assert.Equal(restartUrl, task.URL)
 }
+
+func TestParseDuration(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := map[string]time.Duration{
+		"PT1H2M3S": time.Hour + 2*time.Minute + 3*time.Second,
+		"PT45M":    45 * time.Minute,
+		"PT90.5S":  90*time.Second + 500*time.Millisecond,
+		"P1DT2H":   26 * time.Hour,
+		"pt5m":     5 * time.Minute,
+		"1:02:03":  time.Hour + 2*time.Minute + 3*time.Second,
+		"62:03":    62*time.Minute + 3*time.Second,
+		"3723":     3723 * time.Second,
+		"":         0,
+		"unknown":  0,
+		"1:2:3:4":  0,
+		"-5":       0,
+	}
+
+	for s, expected := range tests {
+		assert.Equal(expected, ParseDuration(s), s)
+	}
+}
@@ -0,0 +1,16 @@
+package pipeline
+
+import "time"
+
+// Stats holds statistics for the text of the content.
+type Stats struct {
+	// Characters is the number of characters without whitespace.
+	Characters int
+	Sentences  int
+	Paragraphs int
+	Headings   int
+	Images     int
+	Links      int
+	// ReadingTime is estimated from the word count.
+	ReadingTime time.Duration
+}
@@ -9,6 +9,7 @@ import (
 	readability "github.com/go-shiori/go-readability"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/akeil/scrapen/internal/microformats"
 	"github.com/akeil/scrapen/internal/pipeline"
 )
 
@@ -30,7 +31,12 @@ func MakeReadable(ctx context.Context, t *pipeline.Task) error {
 	if err != nil {
 		return err
 	}
-	candidates = append(candidates, candidate{baseURL, a})
+	candidates = append(candidates, candidate{URL: baseURL, Article: a})
+
+	entry := entryCandidate(t, a.Title)
+	if entry != nil {
+		candidates = append(candidates, *entry)
+	}
 
 	altDoc := t.AltDocument()
 	if altDoc != nil {
@@ -43,7 +49,7 @@ func MakeReadable(ctx context.Context, t *pipeline.Task) error {
 				"error":  err,
 			}).Warning("Readability failed for alternate content")
 		} else {
-			candidates = append(candidates, candidate{t.AltURL, altA})
+			candidates = append(candidates, candidate{URL: t.AltURL, Article: altA})
 		}
 	}
 
@@ -51,7 +57,10 @@ func MakeReadable(ctx context.Context, t *pipeline.Task) error {
 	winner := selectArticle(candidates)
 
 	t.SetHTML(winner.Article.Content)
-	t.Title = winner.Article.Title
+	t.AddTitle("readability", winner.Article.Title)
+	if t.Title == "" {
+		t.Title = winner.Article.Title
+	}
 	t.ActualURL = winner.URL
 
 	//log.Debug(t.HTML())
@@ -83,11 +92,46 @@ func doReadability(doc *goquery.Document, baseURL string) (readability.Article,
 	return a, nil
 }
 
+// entryCandidate uses the `e-content` of a microformats2 `h-entry` as a
+// content candidate. Returns nil if there is no such entry.
+func entryCandidate(t *pipeline.Task, title string) *candidate {
+	baseURL := t.ContentURL()
+	items := microformats.Parse(t.Document(), baseURL)
+	e := microformats.MainEntry(items, baseURL)
+	if e == nil {
+		return nil
+	}
+	content := e.HTML("content")
+	if content == "" {
+		return nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader("<article>" + content + "</article>"))
+	if err != nil {
+		return nil
+	}
+
+	a, err := doReadability(doc, baseURL)
+	if err != nil || strings.TrimSpace(a.Content) == "" {
+		// short posts may be discarded by readability
+		a.Content = content
+	}
+	a.Title = title
+
+	return &candidate{URL: baseURL, Article: a, preferred: true}
+}
+
+// A preferred candidate wins as long as it has at least this fraction of
+// the text of the longest candidate.
+const preferredRatio = 0.5
+
 func selectArticle(candidates []candidate) candidate {
 	var result candidate
 	maxlen := -1
+	lengths := make([]int, len(candidates))
 
-	for _, c := range candidates {
+	for i, c := range candidates {
+		lengths[i] = -1
 		r := strings.NewReader(c.Article.Content)
 		doc, err := goquery.NewDocumentFromReader(r)
 		if err != nil {
@@ -100,12 +144,23 @@ func selectArticle(candidates []candidate) candidate {
 		// count words
 		text := doc.Selection.Find("body").First().Text()
 		l := len(text)
+		lengths[i] = l
 		if l > maxlen {
 			maxlen = l
 			result = c
 		}
 	}
 
+	for i, c := range candidates {
+		if c.preferred && lengths[i] > 0 && float64(lengths[i]) >= float64(maxlen)*preferredRatio {
+			log.WithFields(log.Fields{
+				"module": "readable",
+				"url":    c.URL,
+			}).Info("Selected preferred article")
+			return c
+		}
+	}
+
 	log.WithFields(log.Fields{
 		"module":       "readable",
 		"url":          result.URL,
@@ -118,4 +173,6 @@ func selectArticle(candidates []candidate) candidate {
 type candidate struct {
 	URL     string
 	Article readability.Article
+	// preferred candidates come from explicit markup, e.g. microformats
+	preferred bool
 }
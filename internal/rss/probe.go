@@ -0,0 +1,76 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akeil/scrapen/internal/pipeline"
+)
+
+// wellKnown are the paths where many sites serve their feed.
+var wellKnown = []string{"/feed", "/rss.xml", "/index.xml", "/atom.xml"}
+
+// ProbeFeeds sends HEAD requests for well-known feed paths on the site of
+// the task and adds those that respond with a feed content type.
+//
+// Must run after FindFeeds.
+func ProbeFeeds(ctx context.Context, t *pipeline.Task) error {
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "rss",
+	}).Info("Probe feeds")
+
+	base, err := url.Parse(t.ContentURL())
+	if err != nil {
+		return err
+	}
+
+	for _, path := range wellKnown {
+		u := url.URL{Scheme: base.Scheme, Host: base.Host, Path: path}
+		found, err := probe(ctx, t.HTTPClient(), u.String())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"task":   t.ID,
+				"module": "rss",
+				"error":  err,
+				"url":    u.String(),
+			}).Debug("Failed to probe feed")
+			continue
+		}
+		if found != "" {
+			addFeed(t, found, "", SourceProbe)
+		}
+	}
+
+	rankFeeds(t)
+	return nil
+}
+
+// probe returns the URL of the feed after redirects,
+// or an empty string if there is no feed at the given URL.
+func probe(ctx context.Context, client *http.Client, feedURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", feedURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	ct := strings.ToLower(res.Header.Get("Content-Type"))
+	if !isFeedType(ct) && !strings.Contains(ct, "rss") && !strings.Contains(ct, "atom") && !strings.Contains(ct, "application/xml") {
+		return "", nil
+	}
+	return res.Request.URL.String(), nil
+}
@@ -2,31 +2,50 @@ package rss
 
 import (
 	"context"
+	"net/url"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/akeil/scrapen/internal/feed"
 	"github.com/akeil/scrapen/internal/pipeline"
 )
 
 const (
 	ctRSS  = "application/rss+xml"
 	ctAtom = "application/atom+xml"
+	ctRDF  = "application/rdf+xml"
+	ctJSON = "application/feed+json"
 	ctXML  = "text/xml"
 )
 
-// FindFeeds looks for links to RSS feeds and places them in the `Feeds`
-// attribute for the task.
+var feedTypes = []string{ctRSS, ctAtom, ctRDF, ctJSON, ctXML}
+
+// Sources of a feed
+const (
+	SourceLink   = "link"
+	SourceAnchor = "anchor"
+	SourceProbe  = "probe"
+)
+
+// FindFeeds looks for links to RSS, Atom and JSON feeds and places them in
+// the `Feeds` attribute for the task.
+//
+// Feeds are taken from <link rel="alternate"> elements and from <a> elements
+// which point to something that looks like a feed, e.g. "/feed" or "rss.xml".
+// Feeds are de-duplicated by their normalized URL and ranked,
+// with feeds for the main site before feeds for comments.
 //
 // Some deviations from the standard:
 // - accept links in <body>
-// - does (currently) not de-duplicate links
 // - does not require the <base> element to resolve relative URLs
 // Source:
 // - https://www.rssboard.org/rss-autodiscovery
 // - https://developer.mozilla.org/en-US/docs/Web/HTML/Attributes/rel#attr-alternate
-//
+// - https://www.jsonfeed.org/version/1.1/#discovery-a-name-discovery-a
 func FindFeeds(ctx context.Context, t *pipeline.Task) error {
 	log.WithFields(log.Fields{
 		"task":   t.ID,
@@ -42,45 +61,216 @@ func FindFeeds(ctx context.Context, t *pipeline.Task) error {
 		href, _ := s.Attr("href")
 		title, _ := s.Attr("title")
 
-		rel = strings.ToLower(rel)
-		typ = strings.ToLower(typ)
-
-		// exclude non-RSS and incomplete
-		if typ != ctRSS && typ != ctAtom && typ != ctXML {
+		// exclude non-feeds and incomplete
+		if !isFeedType(typ) {
 			return
 		}
-		if rel != "alternate" {
+		if !contains(strings.Fields(strings.ToLower(rel)), "alternate") {
 			return
 		}
-		if href == "" {
+
+		addFeed(t, href, title, SourceLink)
+	})
+
+	doc.Selection.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		typ, _ := s.Attr("type")
+		href, _ := s.Attr("href")
+		if !isFeedType(typ) && !looksLikeFeed(href) {
 			return
 		}
 
-		// Found a valid RSS link - make it absolute
-		url, err := t.ResolveURL(href)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"task":   t.ID,
-				"module": "rss",
-				"error":  err,
-				"href":   href,
-			}).Warning("Failed to resolve feed URL")
-			return
+		title, _ := s.Attr("title")
+		if title == "" {
+			title = strings.Join(strings.Fields(s.Text()), " ")
 		}
+		addFeed(t, href, title, SourceAnchor)
+	})
+
+	rankFeeds(t)
+	return nil
+}
 
-		// Found a RSS feed
+// addFeed adds a feed to the task, unless the task already has a feed with
+// the same URL.
+func addFeed(t *pipeline.Task, href, title, source string) {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return
+	}
+
+	// Found a feed link - make it absolute
+	url, err := t.ResolveURL(href)
+	if err != nil {
 		log.WithFields(log.Fields{
 			"task":   t.ID,
 			"module": "rss",
-			"rss":    url,
-		}).Info("found link")
+			"error":  err,
+			"href":   href,
+		}).Warning("Failed to resolve feed URL")
+		return
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return
+	}
+
+	key := feedKey(url)
+	for i, fi := range t.Feeds {
+		if feedKey(fi.URL) == key {
+			if fi.Title == "" {
+				t.Feeds[i].Title = title
+			}
+			return
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "rss",
+		"rss":    url,
+		"source": source,
+	}).Info("found link")
+
+	t.Feeds = append(t.Feeds, pipeline.FeedInfo{
+		URL:    url,
+		Title:  title,
+		Source: source,
+	})
+}
+
+func isFeedType(typ string) bool {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if i := strings.Index(typ, ";"); i >= 0 {
+		typ = strings.TrimSpace(typ[:i])
+	}
+	return contains(feedTypes, typ)
+}
 
-		t.Feeds = append(t.Feeds, pipeline.FeedInfo{
-			URL:   url,
-			Title: title,
-		})
+// paths of <a> links that usually point to feeds,
+// e.g. "rss.xml", "/feed/", "/atom" or "feed.json"
+var (
+	feedPath    = regexp.MustCompile(`(?i)(\.(rss|atom|rdf|xml)|/(feed|rss|rss2|atom)(\.xml|\.json)?/?)$`)
+	notFeedPath = regexp.MustCompile(`(?i)(sitemap|opml|browserconfig|oembed)[^/]*$`)
+)
+
+func looksLikeFeed(href string) bool {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	return feedPath.MatchString(u.Path) && !notFeedPath.MatchString(u.Path)
+}
+
+// feedKey normalizes a feed URL for comparison. The scheme, a "www." prefix,
+// a trailing slash and the fragment do not make a different feed.
+func feedKey(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	port := u.Port()
+	if port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	key := host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+var comments = regexp.MustCompile(`(?i)comment|kommentar`)
+
+// rankFeeds sorts the feeds of a task, the best feed first.
+func rankFeeds(t *pipeline.Task) {
+	site := ""
+	u, err := url.Parse(t.ContentURL())
+	if err == nil {
+		site = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
 
+	sort.SliceStable(t.Feeds, func(i, j int) bool {
+		return feedScore(t.Feeds[i], site) > feedScore(t.Feeds[j], site)
 	})
+}
+
+// feedScore rates a feed by its source. Feeds for comments and feeds from
+// <a> links to other sites are rated lower.
+func feedScore(fi pipeline.FeedInfo, site string) int {
+	score := 0
+	switch fi.Source {
+	case SourceLink:
+		score = 3
+	case SourceProbe:
+		score = 2
+	case SourceAnchor:
+		score = 1
+	}
+
+	if comments.MatchString(fi.URL) || comments.MatchString(fi.Title) {
+		score -= 4
+	}
+
+	if fi.Source == SourceAnchor {
+		u, err := url.Parse(fi.URL)
+		if err == nil && strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") != site {
+			score--
+		}
+	}
+
+	return score
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// maxFeeds limits the number of feeds that are read for a page.
+const maxFeeds = 5
+
+// ReadFeeds downloads the feeds that were found by FindFeeds and sets the
+// title, format and number of entries from the feed.
+//
+// Feeds that cannot be read are left unchanged.
+func ReadFeeds(ctx context.Context, t *pipeline.Task) error {
+	log.WithFields(log.Fields{
+		"task":   t.ID,
+		"module": "rss",
+	}).Info("Read feeds")
+
+	for i := range t.Feeds {
+		if i >= maxFeeds {
+			break
+		}
+		fi := &t.Feeds[i]
+
+		f, err := feed.Fetch(ctx, t.HTTPClient(), fi.URL)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"task":   t.ID,
+				"module": "rss",
+				"error":  err,
+				"url":    fi.URL,
+			}).Warning("Failed to read feed")
+			continue
+		}
+
+		if f.Title != "" {
+			fi.Title = f.Title
+		}
+		fi.Format = f.Format
+		fi.Entries = len(f.Entries)
+	}
 
 	return nil
 }
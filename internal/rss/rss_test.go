@@ -2,6 +2,8 @@ package rss
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -118,6 +120,117 @@ func TestFindFeeds(t *testing.T) {
 	}
 }
 
+func TestFindFeedsHeuristic(t *testing.T) {
+	assert := assert.New(t)
+
+	base := "https://www.example.com/2021/article"
+
+	// JSON Feed
+	html := `<html><head>
+        <link rel="alternate" type="application/feed+json" title="JSON" href="/feed.json"/>
+    </head><body>foo</body></html>`
+
+	fi, err := findRss(base, html)
+	assert.Nil(err)
+	assert.Equal(1, len(fi))
+
+	// <a> links
+	html = `<html><head></head><body>
+        <a href="/feed/">Subscribe</a>
+        <a href="https://www.example.com/rss.xml">RSS</a>
+        <a href="/atom">Atom</a>
+        <a href="/sitemap.xml">Sitemap</a>
+        <a href="/feedback">Feedback</a>
+        <a href="/2021/other-article">Other</a>
+        <a href="mailto:feed@example.com">Mail</a>
+    </body></html>`
+
+	fi, err = findRss(base, html)
+	assert.Nil(err)
+	assert.Equal(3, len(fi))
+	if len(fi) == 3 {
+		assert.Equal("https://www.example.com/feed/", fi[0].URL)
+		assert.Equal("Subscribe", fi[0].Title)
+		assert.Equal("anchor", fi[0].Source)
+		assert.Equal("https://www.example.com/rss.xml", fi[1].URL)
+		assert.Equal("https://www.example.com/atom", fi[2].URL)
+	}
+
+	// de-duplicated, ranked
+	html = `<html><head>
+        <link rel="alternate" type="application/rss+xml" title="Comments Feed" href="/comments/feed/"/>
+        <link rel="alternate" type="application/rss+xml" title="Feed" href="/feed/"/>
+    </head><body>
+        <a href="https://feeds.example.net/example">Other site</a>
+        <a href="http://example.com/feed">Feed</a>
+        <a href="https://other.example.net/feed.xml">Other site</a>
+        <a href="/feed/#top">Feed</a>
+    </body></html>`
+
+	fi, err = findRss(base, html)
+	assert.Nil(err)
+	assert.Equal(3, len(fi))
+	if len(fi) == 3 {
+		assert.Equal("https://www.example.com/feed/", fi[0].URL)
+		assert.Equal("https://other.example.net/feed.xml", fi[1].URL)
+		assert.Equal("https://www.example.com/comments/feed/", fi[2].URL)
+	}
+}
+
+func TestFeedKey(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := map[string]string{
+		"https://example.com/feed":          "example.com/feed",
+		"http://www.Example.com/feed/":      "example.com/feed",
+		"https://example.com:443/feed#top":  "example.com/feed",
+		"https://example.com:8080/feed":     "example.com:8080/feed",
+		"https://example.com/?feed=rss2":    "example.com?feed=rss2",
+		"https://blog.example.com/feed.xml": "blog.example.com/feed.xml",
+	}
+
+	for s, expected := range tests {
+		assert.Equal(expected, feedKey(s), s)
+	}
+}
+
+func TestProbeFeeds(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed":
+			http.Redirect(w, r, "/feed/", http.StatusMovedPermanently)
+		case "/feed/":
+			w.Header().Set("Content-Type", "application/rss+xml; charset=UTF-8")
+		case "/index.xml":
+			// e.g. a homepage for unknown paths
+			w.Header().Set("Content-Type", "text/html")
+		case "/atom.xml":
+			w.Header().Set("Content-Type", "application/atom+xml")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	task := &pipeline.Task{ActualURL: srv.URL + "/2021/article"}
+	task.Feeds = []pipeline.FeedInfo{
+		{URL: srv.URL + "/comments/feed", Title: "Comments", Source: "link"},
+		{URL: srv.URL + "/atom.xml", Title: "Atom", Source: "link"},
+	}
+
+	err := ProbeFeeds(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal(3, len(task.Feeds))
+	if len(task.Feeds) == 3 {
+		assert.Equal(srv.URL+"/atom.xml", task.Feeds[0].URL)
+		assert.Equal(srv.URL+"/feed/", task.Feeds[1].URL)
+		assert.Equal("probe", task.Feeds[1].Source)
+		assert.Equal(srv.URL+"/comments/feed", task.Feeds[2].URL)
+	}
+}
+
 func findRss(base, html string) ([]pipeline.FeedInfo, error) {
 	task := &pipeline.Task{
 		ActualURL: base,
@@ -127,3 +240,37 @@ func findRss(base, html string) ([]pipeline.FeedInfo, error) {
 	err := FindFeeds(context.TODO(), task)
 	return task.Feeds, err
 }
+
+func TestReadFeeds(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/atom.xml":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom">
+                <title>The Atom Feed</title>
+                <entry><id>1</id><title>One</title></entry>
+                <entry><id>2</id><title>Two</title></entry>
+            </feed>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	task := &pipeline.Task{}
+	task.Feeds = []pipeline.FeedInfo{
+		{URL: srv.URL + "/atom.xml", Title: "Atom"},
+		{URL: srv.URL + "/missing.xml", Title: "Missing"},
+	}
+
+	err := ReadFeeds(context.TODO(), task)
+	assert.Nil(err)
+	assert.Equal("The Atom Feed", task.Feeds[0].Title)
+	assert.Equal("atom", task.Feeds[0].Format)
+	assert.Equal(2, task.Feeds[0].Entries)
+
+	assert.Equal("Missing", task.Feeds[1].Title)
+	assert.Equal("", task.Feeds[1].Format)
+}
@@ -0,0 +1,97 @@
+package scrapen
+
+import (
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/akeil/scrapen/internal/opml"
+)
+
+// Subscription is a feed from an OPML file.
+type Subscription = opml.Feed
+
+// ErrNotOPML is returned by ReadOPML if the file is not an OPML document.
+var ErrNotOPML = opml.ErrNotOPML
+
+// ReadOPML reads the feed subscriptions from an OPML file,
+// e.g. to scrape each of them with ScrapeFeed.
+func ReadOPML(r io.Reader) ([]Subscription, error) {
+	doc, err := opml.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Feeds(), nil
+}
+
+// WriteOPML writes the feeds that were found in the given results as
+// OPML 2.0, grouped by site. Feeds which were found for more than one
+// result are written once.
+func WriteOPML(w io.Writer, title string, results []Result) error {
+	now := time.Now().UTC()
+	doc := &opml.Document{
+		Title:       title,
+		DateCreated: &now,
+	}
+
+	sites := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, r := range results {
+		site, siteURL := resultSite(r)
+		for _, f := range r.Feeds {
+			if seen[f.URL] {
+				continue
+			}
+			seen[f.URL] = true
+
+			i, ok := sites[site]
+			if !ok {
+				name := r.SiteName
+				if name == "" {
+					name = site
+				}
+				doc.Outlines = append(doc.Outlines, opml.Outline{
+					Text:    name,
+					HTMLURL: siteURL,
+				})
+				i = len(doc.Outlines) - 1
+				sites[site] = i
+			}
+
+			text := f.Title
+			if text == "" {
+				text = f.URL
+			}
+			group := &doc.Outlines[i]
+			group.Outlines = append(group.Outlines, opml.Outline{
+				Text:    text,
+				Type:    "rss",
+				XMLURL:  f.URL,
+				HTMLURL: siteURL,
+			})
+		}
+	}
+
+	return opml.Write(w, doc)
+}
+
+// resultSite returns the host and the URL of the site for a result.
+func resultSite(r Result) (string, string) {
+	site, scheme := r.Site, r.SiteScheme
+	if site == "" {
+		u, err := url.Parse(r.ActualURL)
+		if err != nil || u.Host == "" {
+			u, err = url.Parse(r.URL)
+		}
+		if err == nil {
+			site, scheme = u.Host, u.Scheme
+		}
+	}
+	if scheme == "" {
+		scheme = "https"
+	}
+	if site == "" {
+		return "", ""
+	}
+	return site, scheme + "://" + site
+}
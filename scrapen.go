@@ -2,6 +2,7 @@ package scrapen
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,6 +13,7 @@ import (
 	"github.com/akeil/scrapen/internal/fetch"
 	//	"github.com/akeil/scrapen/internal/htm"
 	"github.com/akeil/scrapen/internal/metadata"
+	"github.com/akeil/scrapen/internal/paging"
 	"github.com/akeil/scrapen/internal/pipeline"
 	"github.com/akeil/scrapen/internal/readable"
 	"github.com/akeil/scrapen/internal/rss"
@@ -34,10 +36,36 @@ func doScrape(url string, o *Options) (*pipeline.Task, error) {
 	if o == nil {
 		o = DefaultOptions()
 	}
-	ctx := context.Background()
+
+	c, err := newClient(o)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := runTask(context.Background(), url, o, c.client)
+
+	// keep cookies and recorded traffic even if the scrape failed
+	closeErr := c.close()
+	if closeErr != nil {
+		log.WithFields(log.Fields{
+			"module": "main",
+			"error":  closeErr,
+		}).Warn("Failed to save client state")
+	}
+
+	return t, err
+}
+
+// runTask scrapes a single URL. The given steps run before the pipeline
+// that is configured from the options.
+func runTask(ctx context.Context, url string, o *Options, client *http.Client, before ...pipeline.Pipeline) (*pipeline.Task, error) {
 	id := uuid.New().String()
 	p := configurePipeline(o)
+	if len(before) > 0 {
+		p = pipeline.BuildPipeline(append(before, p)...)
+	}
 	t := pipeline.NewTask(o.Store, id, url, p)
+	t.Client = client
 
 	err := t.Run(ctx)
 	if err != nil {
@@ -74,46 +102,157 @@ type Options struct {
 	Normalize bool
 	// DownloadImages controls whether images from the content should be downloaded.
 	DownloadImages bool
+	// DownloadEnclosures controls whether audio and video files should be
+	// downloaded into the Store.
+	DownloadEnclosures bool
+	// MaxEnclosureSize is the size limit in bytes for enclosures that are
+	// downloaded, a default is used if it is zero.
+	MaxEnclosureSize int64
 	// SiteSpecific controls whether to apply site-specific content-selectors.
 	SiteSpecific bool
 	// Detect RSS feeds
 	FindFeeds bool
-	// A Store is required if DownloadImages is true.
+	// ProbeFeeds controls whether well-known feed URLs like "/feed" are
+	// requested to find feeds that the page does not link to.
+	ProbeFeeds bool
+	// ReadFeeds controls whether detected feeds are downloaded to read
+	// their title, format and number of entries.
+	ReadFeeds bool
+	// Embeds controls whether embedded players from known providers should
+	// be replaced with their title and thumbnail from oEmbed.
+	// Metadata is then also read from the oEmbed representation of the page.
+	Embeds bool
+	// ArticlesOnly controls whether the scrape should fail with a
+	// NotArticleError if the page is clearly not an article,
+	// e.g. a homepage or a listing.
+	ArticlesOnly bool
+	// RetryBlocked controls whether a request is repeated with a different
+	// browser profile if the site responds with a bot challenge.
+	RetryBlocked bool
+	// WordsPerMinute is the reading speed for the estimated reading time,
+	// a default is used if it is zero.
+	WordsPerMinute int
+	// Pages controls whether the following pages of a multi-page article
+	// should be fetched and appended to the content.
+	Pages bool
+	// MaxPages is the maximum number of pages to assemble, including the
+	// first one.
+	MaxPages int
+	// A Store is required if DownloadImages or DownloadEnclosures is true.
 	Store Store
+	// CookieFile is the path to a file which stores cookies across scrapes.
+	// If empty, each scrape starts with an empty cookie jar.
+	CookieFile string
+	// FeedStateFile is the path to a file which stores the IDs of feed
+	// entries that were scraped with ScrapeFeed. Entries in the file are
+	// skipped. If empty, all entries are scraped.
+	FeedStateFile string
+	// Hosts holds cookies and headers which are sent with each request to
+	// a given host. The key is the host name, settings also apply to
+	// subdomains.
+	Hosts map[string]HostOptions
+	// RecordHAR is the path to a HAR file which receives all HTTP requests
+	// and responses made during the scrape. The entries are appended if the
+	// file exists.
+	RecordHAR string
+	// ReplayHAR is the path to a HAR file from which responses are served
+	// instead of making requests over the network.
+	ReplayHAR string
+}
+
+// NotArticleError is returned if Options.ArticlesOnly is set and the page is
+// not an article. Use errors.As to find the page type.
+type NotArticleError = pipeline.NotArticleError
+
+var (
+	// ErrBlocked is returned if the site responds with a bot challenge
+	// instead of the page.
+	ErrBlocked = fetch.ErrBlocked
+	// ErrNotFound is returned if the page does not exist, including pages
+	// which say "not found" with HTTP status 200.
+	ErrNotFound = fetch.ErrNotFound
+)
+
+// DetectionError holds details for ErrBlocked and ErrNotFound,
+// use errors.As to get them.
+type DetectionError = fetch.DetectionError
+
+// HostOptions holds settings for requests to a specific host.
+type HostOptions struct {
+	// Cookies are sent in addition to the cookies from the cookie jar.
+	Cookies map[string]string
+	// Headers replace the default headers with the same name.
+	Headers map[string]string
 }
 
 // DefaultOptions creates default scrape settings.
 func DefaultOptions() *Options {
 	return &Options{
-		Metadata:       true,
-		Readability:    true,
-		Clean:          true,
-		Normalize:      true,
-		DownloadImages: false,
-		SiteSpecific:   false,
-		FindFeeds:      false,
-		Store:          nil,
+		Metadata:           true,
+		Readability:        true,
+		Clean:              true,
+		Normalize:          true,
+		DownloadImages:     false,
+		DownloadEnclosures: false,
+		SiteSpecific:       false,
+		FindFeeds:          false,
+		ProbeFeeds:         false,
+		ReadFeeds:          false,
+		Embeds:             false,
+		RetryBlocked:       false,
+		Pages:              false,
+		MaxPages:           10,
+		WordsPerMinute:     200,
+		Store:              nil,
 	}
 }
 
 func configurePipeline(o *Options) pipeline.Pipeline {
 	p := []pipeline.Pipeline{
-		fetch.Fetch,
+		fetchStep(o),
+		// needs the original document
+		metadata.FindPageType,
+	}
+
+	// fail fast, before more requests are made for other pages or oEmbed
+	if o.ArticlesOnly {
+		p = append(p, metadata.RejectNotArticle)
 	}
 
 	if o.Metadata {
-		p = append(p, metadata.ReadMetadata)
+		if o.Embeds {
+			p = append(p, metadata.ReadMetadataOEmbed)
+		} else {
+			p = append(p, metadata.ReadMetadata)
+		}
 		p = append(p, metadata.FallbackImage)
+		p = append(p, metadata.FindIcons)
+		p = append(p, metadata.FindPaywall)
 	}
 
 	if o.FindFeeds {
 		p = append(p, rss.FindFeeds)
+		if o.ProbeFeeds {
+			p = append(p, rss.ProbeFeeds)
+		}
+		if o.ReadFeeds {
+			p = append(p, rss.ReadFeeds)
+		}
 	}
 
 	if o.SiteSpecific {
 		p = append(p, specific.SiteSpecific)
 	}
 
+	// needs the complete document
+	if o.Pages {
+		p = append(p, paging.FindPages)
+	}
+
+	// before Prepare, which handles iframes without oEmbed
+	if o.Embeds {
+		p = append(p, content.ResolveEmbeds)
+	}
 	p = append(p, content.Prepare)
 
 	// Do this *before* Readability
@@ -122,6 +261,16 @@ func configurePipeline(o *Options) pipeline.Pipeline {
 		p = append(p, readable.MakeReadable)
 	}
 
+	if o.Pages {
+		p = append(p, paging.AssemblePages(o.MaxPages, configurePagePipeline(o)))
+	}
+
+	// needs the content from readability, before it is cleaned
+	p = append(p, content.FindMedia)
+
+	// needs the title from readability and the content
+	p = append(p, metadata.ResolveTitle)
+
 	if o.Clean {
 		p = append(p, content.Clean)
 	}
@@ -134,10 +283,48 @@ func configurePipeline(o *Options) pipeline.Pipeline {
 
 	// working on the final content HTML
 	p = append(p, metadata.CountWords)
+	p = append(p, metadata.ComputeStats(o.WordsPerMinute))
+	if o.Metadata {
+		p = append(p, metadata.DetectLanguage)
+		p = append(p, metadata.CheckTruncated)
+	}
+	p = append(p, metadata.ClassifyPage(o.ArticlesOnly))
 	if o.DownloadImages {
 		p = append(p, assets.DownloadImages)
 	}
 
+	if o.DownloadEnclosures {
+		p = append(p, assets.DownloadEnclosures(o.MaxEnclosureSize))
+	}
+
+	return pipeline.BuildPipeline(p...)
+}
+
+func fetchStep(o *Options) pipeline.Pipeline {
+	if o.RetryBlocked {
+		return fetch.FetchWithFallback
+	}
+	return fetch.Fetch
+}
+
+// configurePagePipeline creates the pipeline that is applied to each
+// following page of a multi-page article.
+func configurePagePipeline(o *Options) pipeline.Pipeline {
+	p := []pipeline.Pipeline{
+		fetchStep(o),
+		paging.FindPages,
+	}
+
+	if o.Embeds {
+		p = append(p, content.ResolveEmbeds)
+	}
+	p = append(p, content.Prepare)
+	p = append(p, content.ResolveURLs)
+
+	if o.Readability {
+		p = append(p, readable.MakeReadable)
+	}
+
 	return pipeline.BuildPipeline(p...)
 }
 
@@ -160,22 +347,116 @@ type Result struct {
 	StatusCode   int
 	HTML         string
 	Title        string
+	// TitleSource tells where the title was found, e.g. "og:title",
+	// "ld/headline", "h1" or "readability".
+	TitleSource  string
 	Retrieved    time.Time
 	Description  string
 	PubDate      *time.Time
-	Site         string
-	SiteScheme   string
-	Author       string
-	WordCount    int
-	Feeds        []Feed
-	Images       []Image
-	Enclosures   []Enclosure
-	ImageURL     string
+	ModifiedDate *time.Time
+	// PubDatePrecision is "year" or "month" if only part of the PubDate is
+	// known, e.g. "2021", and empty for a complete date.
+	PubDatePrecision string
+	Site             string
+	SiteScheme       string
+	SiteName         string
+	// SiteIcon is the URL of the largest icon for the site.
+	SiteIcon string
+	// SiteLogo is the URL of the publisher's logo.
+	SiteLogo string
+	// ThemeColor is the color that the site uses for the browser UI.
+	ThemeColor string
+	Author     string
+	Authors    []Author
+	Keywords   []string
+	Section    string
+	// Language is a BCP 47 language tag, e.g. "en" or "de-DE".
+	Language string
+	// Direction is the text direction, "ltr" or "rtl".
+	Direction string
+	// InReplyTo holds the URLs of posts this post replies to.
+	InReplyTo []string
+	// LikeOf holds the URLs of posts this post likes.
+	LikeOf []string
+	// Citation holds bibliographic data for scholarly articles,
+	// nil if the page has none.
+	Citation *Citation
+	// License is the license of the content, nil if neither a license nor
+	// a copyright notice was found.
+	License *License
+	// Paywalled tells if the content is probably behind a paywall or
+	// truncated.
+	Paywalled bool
+	// PaywallConfidence is between 0 and 1.
+	PaywallConfidence float64
+	// PaywallSignals are the indicators which were found for a paywall.
+	PaywallSignals []string
+	// PageType is one of "article", "listing", "homepage", "video",
+	// "product" or "error".
+	PageType string
+	// PageTypeConfidence is between 0 and 1.
+	PageTypeConfidence float64
+	WordCount          int
+	Stats              Stats
+	Feeds              []Feed
+	Images             []Image
+	Enclosures         []Enclosure
+	ImageURL           string
 }
 
 type Feed struct {
 	URL   string
 	Title string
+	// Source is "link", "anchor" or "probe".
+	Source string
+	// Format is one of "rss", "rdf", "atom" or "json",
+	// empty if the feed was not read.
+	Format  string
+	Entries int
+}
+
+type Author struct {
+	Name  string
+	URL   string
+	Image string
+	Role  string
+}
+
+type Citation struct {
+	Type        string
+	DOI         string
+	Journal     string
+	Volume      string
+	Issue       string
+	FirstPage   string
+	LastPage    string
+	Publisher   string
+	Institution string
+	ISSN        string
+	ISBN        string
+	PDFURL      string
+}
+
+type License struct {
+	// ID is the SPDX identifier, e.g. "CC-BY-SA-4.0", if it is known.
+	ID     string
+	Name   string
+	URL    string
+	Holder string
+	Year   string
+}
+
+// Stats holds statistics for the text of the content.
+type Stats struct {
+	// Characters is the number of characters without whitespace.
+	Characters int
+	Sentences  int
+	Paragraphs int
+	Headings   int
+	Images     int
+	Links      int
+	// ReadingTime is estimated from the word count.
+	ReadingTime time.Duration
 }
 
 type Image struct {
@@ -186,19 +467,31 @@ type Image struct {
 }
 
 type Enclosure struct {
+	// Type is "Audio" or "Video".
 	Type        string
 	Title       string
 	URL         string
 	ContentType string
 	Description string
+	// Duration is zero if it is not known.
+	Duration time.Duration
+	// Size is the size in bytes, zero if it is not known.
+	Size int64
+	// Image is the URL of a cover image or thumbnail.
+	Image string
+	// Key is the key in the Store if the enclosure was downloaded.
+	Key string
 }
 
 func resultFromTask(t *pipeline.Task) Result {
 	fs := make([]Feed, len(t.Feeds))
 	for i, fi := range t.Feeds {
 		fs[i] = Feed{
-			URL:   fi.URL,
-			Title: fi.Title,
+			URL:     fi.URL,
+			Title:   fi.Title,
+			Source:  fi.Source,
+			Format:  fi.Format,
+			Entries: fi.Entries,
 		}
 	}
 
@@ -220,6 +513,49 @@ func resultFromTask(t *pipeline.Task) Result {
 			URL:         e.URL,
 			ContentType: e.ContentType,
 			Description: e.Description,
+			Duration:    e.Duration,
+			Size:        e.Size,
+			Image:       e.Image,
+			Key:         e.Key,
+		}
+	}
+
+	as := make([]Author, len(t.Authors))
+	for i, a := range t.Authors {
+		as[i] = Author{
+			Name:  a.Name,
+			URL:   a.URL,
+			Image: a.Image,
+			Role:  a.Role,
+		}
+	}
+
+	var c *Citation
+	if t.Citation != nil {
+		c = &Citation{
+			Type:        t.Citation.Type,
+			DOI:         t.Citation.DOI,
+			Journal:     t.Citation.Journal,
+			Volume:      t.Citation.Volume,
+			Issue:       t.Citation.Issue,
+			FirstPage:   t.Citation.FirstPage,
+			LastPage:    t.Citation.LastPage,
+			Publisher:   t.Citation.Publisher,
+			Institution: t.Citation.Institution,
+			ISSN:        t.Citation.ISSN,
+			ISBN:        t.Citation.ISBN,
+			PDFURL:      t.Citation.PDFURL,
+		}
+	}
+
+	var l *License
+	if t.License != nil {
+		l = &License{
+			ID:     t.License.ID,
+			Name:   t.License.Name,
+			URL:    t.License.URL,
+			Holder: t.License.Holder,
+			Year:   t.License.Year,
 		}
 	}
 
@@ -230,22 +566,52 @@ func resultFromTask(t *pipeline.Task) Result {
 	}
 
 	return Result{
-		URL:          t.URL,
-		ActualURL:    t.ActualURL,
-		CanonicalURL: t.CanonicalURL,
-		StatusCode:   t.StatusCode,
-		HTML:         html,
-		Title:        t.Title,
-		Retrieved:    t.Retrieved,
-		Description:  t.Description,
-		PubDate:      t.PubDate,
-		Site:         t.Site,
-		SiteScheme:   t.SiteScheme,
-		Author:       t.Author,
-		WordCount:    t.WordCount,
-		Feeds:        fs,
-		Images:       imgs,
-		Enclosures:   encs,
-		ImageURL:     t.ImageURL,
+		URL:                t.URL,
+		ActualURL:          t.ActualURL,
+		CanonicalURL:       t.CanonicalURL,
+		StatusCode:         t.StatusCode,
+		HTML:               html,
+		Title:              t.Title,
+		TitleSource:        t.TitleSource,
+		Retrieved:          t.Retrieved,
+		Description:        t.Description,
+		PubDate:            t.PubDate,
+		PubDatePrecision:   t.PubDatePrecision,
+		ModifiedDate:       t.ModifiedDate,
+		Site:               t.Site,
+		SiteScheme:         t.SiteScheme,
+		SiteName:           t.SiteName,
+		SiteIcon:           t.SiteIcon,
+		SiteLogo:           t.SiteLogo,
+		ThemeColor:         t.ThemeColor,
+		Author:             t.Author,
+		Authors:            as,
+		Keywords:           t.Keywords,
+		Section:            t.Section,
+		Language:           t.Language,
+		Direction:          t.Direction,
+		InReplyTo:          t.InReplyTo,
+		LikeOf:             t.LikeOf,
+		Citation:           c,
+		License:            l,
+		Paywalled:          t.Paywall.Paywalled,
+		PaywallConfidence:  t.Paywall.Confidence,
+		PaywallSignals:     t.Paywall.Signals,
+		PageType:           t.PageType.Type,
+		PageTypeConfidence: t.PageType.Confidence,
+		WordCount:          t.WordCount,
+		Stats: Stats{
+			Characters:  t.Stats.Characters,
+			Sentences:   t.Stats.Sentences,
+			Paragraphs:  t.Stats.Paragraphs,
+			Headings:    t.Stats.Headings,
+			Images:      t.Stats.Images,
+			Links:       t.Stats.Links,
+			ReadingTime: t.Stats.ReadingTime,
+		},
+		Feeds:      fs,
+		Images:     imgs,
+		Enclosures: encs,
+		ImageURL:   t.ImageURL,
 	}
 }